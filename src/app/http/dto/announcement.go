@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// AnnouncementRequest creates an announcement. Severity defaults to INFO and target to
+// ALL; set the target_* field matching target (a role, team or user).
+type AnnouncementRequest struct {
+	Title        string     `json:"title" binding:"required"`
+	Body         string     `json:"body"`
+	Severity     string     `json:"severity"`
+	Target       string     `json:"target"`
+	TargetRole   *string    `json:"target_role"`
+	TargetTeamID *int64     `json:"target_team_id"`
+	TargetUserID *int64     `json:"target_user_id"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+// ChatMessageRequest posts a message to a chat channel.
+type ChatMessageRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// ChatReadRequest marks a channel as read up to and including MessageID.
+type ChatReadRequest struct {
+	MessageID int64 `json:"message_id" binding:"required"`
+}
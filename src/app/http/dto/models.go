@@ -2,7 +2,10 @@ package dto
 
 // SessionJoinRequest is the payload for /v1/session/join.
 type SessionJoinRequest struct {
-	DisplayName string `json:"display_name" binding:"required"`
+	// DisplayName is required unless joining with a rejoin secret or join code.
+	DisplayName  string `json:"display_name"`
+	RejoinSecret string `json:"rejoin_secret"`
+	JoinCode     string `json:"join_code"`
 }
 
 // BatchSubmitRequest is the payload for submitting a batch.
@@ -25,6 +28,23 @@ type RatingEntry struct {
 	JokeTitle  *string `json:"joke_title"`
 }
 
+// JokePriceRequest sets a team's asking price for a joke; null reverts to the market price.
+type JokePriceRequest struct {
+	Price *float64 `json:"price"`
+}
+
+// CustomerRatingRequest is a customer's 1–5 rating of a joke they bought, with an
+// optional review.
+type CustomerRatingRequest struct {
+	Rating int     `json:"rating" binding:"required"`
+	Review *string `json:"review"`
+}
+
+// BidRequest is a customer's sealed bid on a joke in an auction round.
+type BidRequest struct {
+	Amount float64 `json:"amount" binding:"required"`
+}
+
 // AssignRequest is used for instructor assign endpoint.
 type AssignRequest struct {
 	CustomerCount int `json:"customer_count" binding:"required"`
@@ -38,6 +58,41 @@ type PatchUserRequest struct {
 	TeamID *int64  `json:"team_id"`
 }
 
+// ReplaceUserRequest picks who takes over a dropped JM/QC's role; omit the id to pick
+// the longest-waiting online participant.
+type ReplaceUserRequest struct {
+	ReplacementUserID *int64 `json:"replacement_user_id"`
+}
+
+// MergeUsersRequest folds an accidental duplicate (source) into the real user (target).
+type MergeUsersRequest struct {
+	SourceUserID int64 `json:"source_user_id" binding:"required"`
+	TargetUserID int64 `json:"target_user_id" binding:"required"`
+}
+
+// RosterEntryRequest is one student in a JSON roster import.
+type RosterEntryRequest struct {
+	DisplayName   string  `json:"display_name"`
+	StudentID     *string `json:"student_id"`
+	PreferredTeam *int    `json:"preferred_team"`
+	PreferredRole *string `json:"preferred_role"`
+}
+
+// RosterImportRequest is the JSON form of a roster import.
+type RosterImportRequest struct {
+	Entries []RosterEntryRequest `json:"entries" binding:"required"`
+}
+
+// RosterSettingsRequest toggles whether joining requires a roster join code.
+type RosterSettingsRequest struct {
+	RequireJoinCode *bool `json:"require_join_code" binding:"required"`
+}
+
+// ParticipantCapRequest sets the maximum number of students; null removes the cap.
+type ParticipantCapRequest struct {
+	MaxParticipants *int `json:"max_participants"`
+}
+
 // ConfigRequest updates round configuration.
 type ConfigRequest struct {
 	CustomerBudget    int     `json:"customer_budget" binding:"required"`
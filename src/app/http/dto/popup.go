@@ -5,3 +5,46 @@ type PopupStateRequest struct {
 	IsPoppedActive *bool `json:"is_popped_active" binding:"required"`
 }
 
+// QCRoutingRequest selects the QC routing mode for a round.
+type QCRoutingRequest struct {
+	QCRoutingMode string `json:"qc_routing_mode" binding:"required"`
+}
+
+// LateJoinerPolicyRequest selects what happens to participants joining after Assign.
+type LateJoinerPolicyRequest struct {
+	LateJoinerPolicy string `json:"late_joiner_policy" binding:"required"`
+}
+
+// WIPLimitsRequest sets per-team limits on unrated work. Omit or null a field to clear it.
+type WIPLimitsRequest struct {
+	MaxUnratedBatches *int `json:"max_unrated_batches"`
+	MaxUnratedJokes   *int `json:"max_unrated_jokes"`
+}
+
+// ChatStateRequest turns team chat on or off for a round.
+type ChatStateRequest struct {
+	ChatEnabled *bool `json:"chat_enabled" binding:"required"`
+}
+
+// PricingRequest picks how a round's jokes are priced. TEAM_SET needs both bounds;
+// DYNAMIC adds PriceStep per sale and takes off PriceDecay per minute without one;
+// AUCTION takes sealed bids for AuctionWindowSeconds under AuctionRule (FIRST_PRICE or
+// SECOND_PRICE), with MinPrice as the reserve.
+type PricingRequest struct {
+	PricingMode          string   `json:"pricing_mode" binding:"required"`
+	MinPrice             *float64 `json:"min_price"`
+	MaxPrice             *float64 `json:"max_price"`
+	PriceStep            float64  `json:"price_step"`
+	PriceDecay           float64  `json:"price_decay"`
+	AuctionRule          string   `json:"auction_rule"`
+	AuctionWindowSeconds int      `json:"auction_window_seconds"`
+}
+
+// ReturnPolicyRequest sets a round's return policy. ReturnWindowSeconds and MaxReturns
+// are left out for no limit; RefundPercent defaults to a full refund.
+type ReturnPolicyRequest struct {
+	ReturnsAllowed      *bool `json:"returns_allowed" binding:"required"`
+	ReturnWindowSeconds *int  `json:"return_window_seconds"`
+	MaxReturns          *int  `json:"max_returns"`
+	RefundPercent       *int  `json:"refund_percent"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "jokefactory/src/core/domain"
+
+// ScriptRequest creates or replaces a game script. Steps use the stored step format:
+// at_seconds, action and the fields that action takes (see domain.ScriptStep).
+type ScriptRequest struct {
+	Name  string              `json:"name" binding:"required"`
+	Steps []domain.ScriptStep `json:"steps" binding:"required"`
+}
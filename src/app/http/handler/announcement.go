@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"jokefactory/src/app/http/dto"
+	"jokefactory/src/app/http/response"
+	"jokefactory/src/app/middleware"
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+	"jokefactory/src/core/usecase"
+)
+
+// AnnouncementHandler serves instructor announcements: creating them and reading receipts
+// for the instructor, and listing, acknowledging and streaming them for participants.
+type AnnouncementHandler struct {
+	announcementService *usecase.AnnouncementService
+}
+
+func NewAnnouncementHandler(announcementService *usecase.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{announcementService: announcementService}
+}
+
+// Create posts a new announcement and pushes it to connected recipients.
+func (h *AnnouncementHandler) Create(c *gin.Context) {
+	var req dto.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+	a := &domain.Announcement{
+		Title:        req.Title,
+		Body:         req.Body,
+		Severity:     domain.AnnouncementSeverity(strings.ToUpper(req.Severity)),
+		Target:       domain.AnnouncementTarget(strings.ToUpper(req.Target)),
+		TargetTeamID: req.TargetTeamID,
+		TargetUserID: req.TargetUserID,
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if req.TargetRole != nil && *req.TargetRole != "" {
+		role := domain.Role(strings.ToUpper(*req.TargetRole))
+		a.TargetRole = &role
+	}
+
+	created, err := h.announcementService.Create(c.Request.Context(), c.GetInt64("user_id"), a)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.Created(c, gin.H{"announcement": announcementJSON(created)})
+}
+
+// List returns every announcement with how many recipients have acknowledged it.
+func (h *AnnouncementHandler) List(c *gin.Context) {
+	summaries, err := h.announcementService.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	out := make([]gin.H, 0, len(summaries))
+	for _, s := range summaries {
+		item := announcementJSON(&s.Announcement)
+		item["recipients"] = s.Recipients
+		item["acknowledged"] = s.Acknowledged
+		out = append(out, item)
+	}
+	response.OK(c, gin.H{"announcements": out})
+}
+
+// Receipts lists an announcement's current recipients and when each acknowledged it.
+func (h *AnnouncementHandler) Receipts(c *gin.Context) {
+	announcementID, ok := parseAnnouncementID(c)
+	if !ok {
+		return
+	}
+	a, recipients, err := h.announcementService.Receipts(c.Request.Context(), announcementID)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	out := make([]gin.H, 0, len(recipients))
+	acknowledged := 0
+	for _, r := range recipients {
+		if r.AckedAt != nil {
+			acknowledged++
+		}
+		out = append(out, gin.H{
+			"user_id":         r.UserID,
+			"display_name":    r.DisplayName,
+			"role":            r.Role,
+			"team_id":         r.TeamID,
+			"acknowledged_at": r.AckedAt,
+		})
+	}
+	response.OK(c, gin.H{
+		"announcement": announcementJSON(a),
+		"recipients":   out,
+		"acknowledged": acknowledged,
+	})
+}
+
+// Mine returns the caller's unexpired announcements, newest first.
+func (h *AnnouncementHandler) Mine(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	announcements, err := h.announcementService.ForUser(c.Request.Context(), userID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"announcements": userAnnouncementsJSON(announcements)})
+}
+
+// Ack marks an announcement as read by the caller.
+func (h *AnnouncementHandler) Ack(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	announcementID, ok := parseAnnouncementID(c)
+	if !ok {
+		return
+	}
+	ackedAt, err := h.announcementService.Ack(c.Request.Context(), announcementID, userID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"announcement_id": announcementID,
+		"acknowledged_at": ackedAt,
+	})
+}
+
+// Stream is a server-sent event stream for the caller. It opens with an "announcements"
+// event holding the current list, then sends an "announcement" event for each new one.
+// Browsers' EventSource can't set headers, so the user id may be given as ?user_id=.
+func (h *AnnouncementHandler) Stream(c *gin.Context) {
+	allowQueryUserID(c)
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	// Subscribe before loading the list so nothing created in between is missed.
+	pushed, err := h.announcementService.Subscribe(ctx, userID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	current, err := h.announcementService.ForUser(ctx, userID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+
+	startEventStream(c)
+	c.SSEvent("announcements", gin.H{"announcements": userAnnouncementsJSON(current)})
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case a, open := <-pushed:
+			if !open {
+				return false
+			}
+			c.SSEvent("announcement", announcementJSON(&a))
+		case <-keepAlive.C:
+			_, _ = io.WriteString(w, ": keep-alive\n\n")
+		case <-ctx.Done():
+			return false
+		}
+		return true
+	})
+}
+
+func parseAnnouncementID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("announcement_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid announcement id", middleware.GetRequestID(c))
+		return 0, false
+	}
+	return id, true
+}
+
+func announcementJSON(a *domain.Announcement) gin.H {
+	return gin.H{
+		"announcement_id": a.ID,
+		"title":           a.Title,
+		"body":            a.Body,
+		"severity":        a.Severity,
+		"target":          a.Target,
+		"target_role":     a.TargetRole,
+		"target_team_id":  a.TargetTeamID,
+		"target_user_id":  a.TargetUserID,
+		"expires_at":      a.ExpiresAt,
+		"created_at":      a.CreatedAt,
+	}
+}
+
+func userAnnouncementsJSON(announcements []ports.UserAnnouncement) []gin.H {
+	out := make([]gin.H, 0, len(announcements))
+	for _, ua := range announcements {
+		item := announcementJSON(&ua.Announcement)
+		item["acknowledged_at"] = ua.AckedAt
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"jokefactory/src/app/http/response"
+	"jokefactory/src/app/middleware"
+	"jokefactory/src/core/ports"
+	"jokefactory/src/core/usecase"
+)
+
+// AuditHandler serves the instructor audit log.
+type AuditHandler struct {
+	auditService *usecase.AuditService
+}
+
+func NewAuditHandler(auditService *usecase.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// List returns audit events newest first.
+// Optional query: actor_user_id, action, target_type, target_id, round_id,
+// since/until (RFC 3339), page (default 1), per_page (default 50).
+func (h *AuditHandler) List(c *gin.Context) {
+	filter := ports.AuditFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+	}
+	ids := map[string]**int64{
+		"actor_user_id": &filter.ActorUserID,
+		"target_id":     &filter.TargetID,
+		"round_id":      &filter.RoundID,
+	}
+	for name, dst := range ids {
+		if raw := c.Query(name); raw != "" {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				response.BadRequest(c, "invalid "+name, middleware.GetRequestID(c))
+				return
+			}
+			*dst = &id
+		}
+	}
+	times := map[string]**time.Time{"since": &filter.Since, "until": &filter.Until}
+	for name, dst := range times {
+		if raw := c.Query(name); raw != "" {
+			t, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				response.BadRequest(c, "invalid "+name, middleware.GetRequestID(c))
+				return
+			}
+			*dst = &t
+		}
+	}
+	page, perPage := 1, usecase.DefaultAuditPageSize
+	if raw := c.Query("page"); raw != "" {
+		var err error
+		if page, err = strconv.Atoi(raw); err != nil {
+			response.BadRequest(c, "invalid page", middleware.GetRequestID(c))
+			return
+		}
+	}
+	if raw := c.Query("per_page"); raw != "" {
+		var err error
+		if perPage, err = strconv.Atoi(raw); err != nil || perPage <= 0 {
+			response.BadRequest(c, "invalid per_page", middleware.GetRequestID(c))
+			return
+		}
+	}
+
+	events, total, err := h.auditService.List(c.Request.Context(), filter, page, perPage)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	out := make([]gin.H, 0, len(events))
+	for _, e := range events {
+		out = append(out, gin.H{
+			"event_id":           e.ID,
+			"actor_user_id":      e.ActorUserID,
+			"actor_display_name": e.ActorDisplayName,
+			"action":             e.Action,
+			"target_type":        e.TargetType,
+			"target_id":          e.TargetID,
+			"round_id":           e.RoundID,
+			"before":             rawJSON(e.Before),
+			"after":              rawJSON(e.After),
+			"request_id":         e.RequestID,
+			"created_at":         e.CreatedAt,
+		})
+	}
+	response.Page(c, out, total, page, perPage)
+}
+
+// rawJSON embeds stored JSON as-is, or null when absent.
+func rawJSON(b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(b)
+}
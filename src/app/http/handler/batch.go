@@ -55,6 +55,46 @@ func (h *BatchHandler) Submit(c *gin.Context) {
 	})
 }
 
+// SetJokePrice lets the team's JM or QC set the asking price of one of its jokes in a
+// team-priced round.
+func (h *BatchHandler) SetJokePrice(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	jokeID, err := strconv.ParseInt(c.Param("joke_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid joke id", middleware.GetRequestID(c))
+		return
+	}
+
+	var req dto.JokePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+
+	joke, err := h.batchService.SetJokePrice(c.Request.Context(), userID, roundID, jokeID, req.Price)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+
+	response.OK(c, gin.H{
+		"joke": gin.H{
+			"joke_id":    joke.JokeID,
+			"team_id":    joke.TeamID,
+			"team_price": joke.TeamPrice,
+			"published":  joke.PublishedAt != nil,
+		},
+	})
+}
+
 func (h *BatchHandler) List(c *gin.Context) {
 	userID, ok := parseUserID(c)
 	if !ok {
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"jokefactory/src/app/http/dto"
+	"jokefactory/src/app/http/response"
+	"jokefactory/src/app/middleware"
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/usecase"
+)
+
+// ChatHandler serves the per-team JM/QC chat channels and the instructor's round channel.
+// Routes with a :team_id address that team's channel; without one, the round channel.
+type ChatHandler struct {
+	chatService *usecase.ChatService
+}
+
+func NewChatHandler(chatService *usecase.ChatService) *ChatHandler {
+	return &ChatHandler{chatService: chatService}
+}
+
+// List returns a channel's messages oldest first, each with who has read it.
+// Optional query: after_id (only newer messages; otherwise the latest page), limit.
+func (h *ChatHandler) List(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	roundID, teamID, ok := parseChatChannel(c)
+	if !ok {
+		return
+	}
+	var afterID int64
+	if raw := c.Query("after_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "invalid after_id", middleware.GetRequestID(c))
+			return
+		}
+		afterID = id
+	}
+	limit := usecase.DefaultChatPageSize
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			response.BadRequest(c, "invalid limit", middleware.GetRequestID(c))
+			return
+		}
+		limit = n
+	}
+
+	views, err := h.chatService.List(c.Request.Context(), userID, roundID, teamID, afterID, limit)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	out := make([]gin.H, 0, len(views))
+	for _, v := range views {
+		item := chatMessageJSON(&v.Message)
+		readBy := make([]gin.H, 0, len(v.ReadBy))
+		for _, r := range v.ReadBy {
+			readBy = append(readBy, gin.H{
+				"user_id":      r.UserID,
+				"display_name": r.DisplayName,
+				"read_at":      r.ReadAt,
+			})
+		}
+		item["read_by"] = readBy
+		out = append(out, item)
+	}
+	c.JSON(http.StatusOK, gin.H{"messages": out})
+}
+
+// Post sends a message to the caller's team channel.
+func (h *ChatHandler) Post(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	roundID, teamID, ok := parseChatChannel(c)
+	if !ok {
+		return
+	}
+	h.post(c, userID, roundID, teamID)
+}
+
+// Broadcast sends an instructor message to everyone on the round channel.
+func (h *ChatHandler) Broadcast(c *gin.Context) {
+	roundID, _, ok := parseChatChannel(c)
+	if !ok {
+		return
+	}
+	h.post(c, c.GetInt64("user_id"), roundID, nil)
+}
+
+func (h *ChatHandler) post(c *gin.Context, userID, roundID int64, teamID *int64) {
+	var req dto.ChatMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+	msg, err := h.chatService.Post(c.Request.Context(), userID, roundID, teamID, req.Body)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": chatMessageJSON(msg)})
+}
+
+// MarkRead records that the caller has read a channel up to message_id.
+func (h *ChatHandler) MarkRead(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	roundID, teamID, ok := parseChatChannel(c)
+	if !ok {
+		return
+	}
+	var req dto.ChatReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+	cursor, err := h.chatService.MarkRead(c.Request.Context(), userID, roundID, teamID, req.MessageID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	c.JSON(http.StatusOK, chatReadJSON(cursor))
+}
+
+// Stream is a server-sent event stream of the round's chat for the caller: a "message"
+// event for each new message and a "read" event when someone reads further, on the round
+// channel and the channels of the caller's team. Instructors see every team's channel.
+// Like the announcement stream, the user id may be given as ?user_id=.
+func (h *ChatHandler) Stream(c *gin.Context) {
+	allowQueryUserID(c)
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	roundID, _, ok := parseChatChannel(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	events, err := h.chatService.Subscribe(ctx, userID, roundID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+
+	startEventStream(c)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, open := <-events:
+			if !open {
+				return false
+			}
+			switch e.Type {
+			case usecase.ChatEventMessage:
+				c.SSEvent("message", chatMessageJSON(e.Message))
+			case usecase.ChatEventRead:
+				c.SSEvent("read", chatReadJSON(e.Read))
+			}
+		case <-keepAlive.C:
+			_, _ = io.WriteString(w, ": keep-alive\n\n")
+		case <-ctx.Done():
+			return false
+		}
+		return true
+	})
+}
+
+// parseChatChannel reads the round and, when the route has one, the team of a channel.
+func parseChatChannel(c *gin.Context) (int64, *int64, bool) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return 0, nil, false
+	}
+	raw := c.Param("team_id")
+	if raw == "" {
+		return roundID, nil, true
+	}
+	teamID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid team id", middleware.GetRequestID(c))
+		return 0, nil, false
+	}
+	return roundID, &teamID, true
+}
+
+func chatMessageJSON(m *domain.ChatMessage) gin.H {
+	return gin.H{
+		"message_id":  m.ID,
+		"round_id":    m.RoundID,
+		"team_id":     m.TeamID,
+		"sender_id":   m.SenderID,
+		"sender_name": m.SenderName,
+		"sender_role": m.SenderRole,
+		"body":        m.Body,
+		"created_at":  m.CreatedAt,
+	}
+}
+
+func chatReadJSON(r *domain.ChatReadCursor) gin.H {
+	return gin.H{
+		"round_id":             r.RoundID,
+		"team_id":              r.TeamID,
+		"user_id":              r.UserID,
+		"display_name":         r.DisplayName,
+		"last_read_message_id": r.LastReadMessageID,
+		"read_at":              r.ReadAt,
+	}
+}
@@ -2,11 +2,15 @@ package handler
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"jokefactory/src/app/http/dto"
 	"jokefactory/src/app/http/response"
 	"jokefactory/src/app/middleware"
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
 	"jokefactory/src/core/usecase"
 )
 
@@ -19,6 +23,11 @@ func NewCustomerHandler(customerService *usecase.CustomerService) *CustomerHandl
 	return &CustomerHandler{customerService: customerService}
 }
 
+// Market lists the round's published jokes one page at a time.
+// Optional query: q (full-text search over text and title), team_id, label (HIGH, AVERAGE
+// or LOW), bought (true/false), sort (oldest, newest, most_bought, team_profit; default
+// oldest), page (default 1), per_page (default 50).
+// The items stay under data.items, with the page metadata alongside.
 func (h *CustomerHandler) Market(c *gin.Context) {
 	userID, ok := parseUserID(c)
 	if !ok {
@@ -29,12 +38,47 @@ func (h *CustomerHandler) Market(c *gin.Context) {
 		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
 		return
 	}
-	items, err := h.customerService.Market(c.Request.Context(), userID, roundID)
+	filter := ports.MarketFilter{
+		Search: c.Query("q"),
+		Label:  c.Query("label"),
+		Sort:   ports.MarketSort(strings.ToLower(c.Query("sort"))),
+	}
+	if raw := c.Query("team_id"); raw != "" {
+		teamID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "invalid team_id", middleware.GetRequestID(c))
+			return
+		}
+		filter.TeamID = &teamID
+	}
+	if raw := c.Query("bought"); raw != "" {
+		bought, err := strconv.ParseBool(raw)
+		if err != nil {
+			response.BadRequest(c, "invalid bought", middleware.GetRequestID(c))
+			return
+		}
+		filter.Bought = &bought
+	}
+	page, perPage := 1, usecase.DefaultMarketPageSize
+	if raw := c.Query("page"); raw != "" {
+		if page, err = strconv.Atoi(raw); err != nil {
+			response.BadRequest(c, "invalid page", middleware.GetRequestID(c))
+			return
+		}
+	}
+	if raw := c.Query("per_page"); raw != "" {
+		if perPage, err = strconv.Atoi(raw); err != nil || perPage <= 0 {
+			response.BadRequest(c, "invalid per_page", middleware.GetRequestID(c))
+			return
+		}
+	}
+
+	items, total, err := h.customerService.Market(c.Request.Context(), userID, roundID, filter, page, perPage)
 	if err != nil {
 		response.FromDomainError(c, err, middleware.GetRequestID(c))
 		return
 	}
-	var out []gin.H
+	out := make([]gin.H, 0, len(items))
 	for _, item := range items {
 		out = append(out, gin.H{
 			"joke_id":         item.JokeID,
@@ -48,11 +92,22 @@ func (h *CustomerHandler) Market(c *gin.Context) {
 				"sold_jokes_count":  item.TeamSold,
 				"profit":            item.TeamProfit,
 			},
+			"price":           item.Price,
+			"team_price":      item.TeamPrice,
+			"auction": gin.H{
+				"closes_at": item.AuctionClosesAt,
+				"my_bid":    item.MyBid,
+			},
 			"bought_count":    item.BoughtCount,
 			"is_bought_by_me": item.IsBoughtByMe,
+			"customer_rating": gin.H{
+				"average": item.CustomerRatingAvg,
+				"count":   item.CustomerRatingCount,
+				"mine":    item.MyRating,
+			},
 		})
 	}
-	response.OK(c, gin.H{"items": out})
+	response.Page(c, gin.H{"items": out}, total, page, perPage)
 }
 
 func (h *CustomerHandler) Budget(c *gin.Context) {
@@ -70,13 +125,182 @@ func (h *CustomerHandler) Budget(c *gin.Context) {
 		response.FromDomainError(c, err, middleware.GetRequestID(c))
 		return
 	}
+	returns, err := h.customerService.Returns(c.Request.Context(), userID, roundID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
 	response.OK(c, gin.H{
 		"round_id":         budget.RoundID,
 		"starting_budget":  budget.StartingBudget,
 		"remaining_budget": budget.RemainingBudget,
+		"returns": gin.H{
+			"allowed":        returns.Policy.ReturnsAllowed,
+			"window_seconds": returns.Policy.ReturnWindowSeconds,
+			"max_returns":    returns.Policy.MaxReturns,
+			"refund_percent": returns.Policy.RefundPercent,
+			"used":           returns.Used,
+			"left":           returns.Left,
+		},
+	})
+}
+
+// Rate records the caller's rating and optional review of a joke they bought.
+func (h *CustomerHandler) Rate(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	jokeID, err := strconv.ParseInt(c.Param("joke_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid joke id", middleware.GetRequestID(c))
+		return
+	}
+	var req dto.CustomerRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+	rating, err := h.customerService.Rate(c.Request.Context(), userID, roundID, jokeID, req.Rating, req.Review)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{"rating": gin.H{
+		"joke_id":    rating.JokeID,
+		"rating":     rating.Rating,
+		"review":     rating.Review,
+		"created_at": rating.CreatedAt,
+		"updated_at": rating.UpdatedAt,
+	}})
+}
+
+// Purchases lists the caller's buys and returns in the round with the budget left after
+// each, so a customer can see where their money went.
+func (h *CustomerHandler) Purchases(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	history, err := h.customerService.Purchases(c.Request.Context(), userID, roundID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	out := make([]gin.H, 0, len(history.Entries))
+	for _, e := range history.Entries {
+		action := "BUY"
+		if e.Delta < 0 {
+			action = "RETURN"
+		}
+		out = append(out, gin.H{
+			"event_id":   e.EventID,
+			"action":     action,
+			"joke_id":    e.JokeID,
+			"joke_text":  e.JokeText,
+			"joke_title": e.JokeTitle,
+			"team_id":    e.TeamID,
+			"team_name":  e.TeamName,
+			"price":      e.Price,
+			"balance":    e.Balance,
+			"created_at": e.CreatedAt,
+		})
+	}
+	response.OK(c, gin.H{
+		"round_id":  roundID,
+		"purchases": out,
+		"budget": gin.H{
+			"starting_budget":  history.Budget.StartingBudget,
+			"remaining_budget": history.Budget.RemainingBudget,
+			"spent":            history.Spent,
+			"reserved":         history.Reserved,
+			"reconciled":       history.Reconciled,
+		},
+	})
+}
+
+// Bid places or replaces the caller's sealed bid on a joke in an auction round.
+func (h *CustomerHandler) Bid(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	jokeID, err := strconv.ParseInt(c.Param("joke_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid joke id", middleware.GetRequestID(c))
+		return
+	}
+	var req dto.BidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+	bid, budget, err := h.customerService.Bid(c.Request.Context(), userID, roundID, jokeID, req.Amount)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{
+		"bid": bidView(*bid),
+		"budget": gin.H{
+			"starting_budget":  budget.StartingBudget,
+			"remaining_budget": budget.RemainingBudget,
+		},
 	})
 }
 
+// Bids lists the caller's bids in the round, newest first, with how each one ended.
+func (h *CustomerHandler) Bids(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	bids, err := h.customerService.Bids(c.Request.Context(), userID, roundID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	out := make([]gin.H, 0, len(bids))
+	for _, b := range bids {
+		out = append(out, bidView(b))
+	}
+	response.OK(c, gin.H{"round_id": roundID, "bids": out})
+}
+
+func bidView(b domain.Bid) gin.H {
+	return gin.H{
+		"bid_id":     b.ID,
+		"joke_id":    b.JokeID,
+		"amount":     b.Amount,
+		"status":     b.Status,
+		"price":      b.Price,
+		"closes_at":  b.ClosesAt,
+		"created_at": b.CreatedAt,
+		"updated_at": b.UpdatedAt,
+		"settled_at": b.SettledAt,
+	}
+}
+
 func (h *CustomerHandler) Buy(c *gin.Context) {
 	userID, ok := parseUserID(c)
 	if !ok {
@@ -101,6 +325,7 @@ func (h *CustomerHandler) Buy(c *gin.Context) {
 		"purchase": gin.H{
 			"purchase_id": purchase.ID,
 			"joke_id":     purchase.JokeID,
+			"price":       purchase.Price,
 		},
 		"budget": gin.H{
 			"starting_budget":  budget.StartingBudget,
@@ -137,6 +362,8 @@ func (h *CustomerHandler) Return(c *gin.Context) {
 		"purchase": gin.H{
 			"purchase_id": purchase.ID,
 			"joke_id":     purchase.JokeID,
+			"price":       purchase.Price,
+			"refund":      purchase.Refund,
 		},
 		"budget": gin.H{
 			"starting_budget":  budget.StartingBudget,
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+
+	"jokefactory/src/app/http/response"
+	"jokefactory/src/app/middleware"
+	"jokefactory/src/core/ports"
+	"jokefactory/src/core/usecase"
+)
+
+// ExportHandler serves raw data exports for offline analysis.
+type ExportHandler struct {
+	exportService *usecase.ExportService
+}
+
+func NewExportHandler(exportService *usecase.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// CSV sends one entity as CSV. Optional query: round_id, team_id. The file is built in
+// memory first, so a failed export is reported as an error instead of a truncated file.
+func (h *ExportHandler) CSV(c *gin.Context) {
+	entity, err := h.exportService.ParseEntity(c.Param("entity"))
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	filter, ok := parseExportFilter(c)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	err = h.exportService.Export(c.Request.Context(), entity, filter, w.Write)
+	if err == nil {
+		w.Flush()
+		err = w.Error()
+	}
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, exportFileName(string(entity), filter)))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// XLSX builds a workbook with one sheet per entity. Optional query: round_id, team_id,
+// entities (comma-separated; defaults to all). The leaderboard sheet is only included
+// when round_id is given.
+func (h *ExportHandler) XLSX(c *gin.Context) {
+	filter, ok := parseExportFilter(c)
+	if !ok {
+		return
+	}
+	entities := ports.ExportEntities
+	if raw := c.Query("entities"); raw != "" {
+		entities = nil
+		for _, name := range strings.Split(raw, ",") {
+			entity, err := h.exportService.ParseEntity(strings.TrimSpace(name))
+			if err != nil {
+				response.FromDomainError(c, err, middleware.GetRequestID(c))
+				return
+			}
+			entities = append(entities, entity)
+		}
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	first := true
+	for _, entity := range entities {
+		if entity == ports.ExportLeaderboard && filter.RoundID == nil {
+			continue
+		}
+		sheet := string(entity)
+		if first {
+			if err := f.SetSheetName("Sheet1", sheet); err != nil {
+				c.Error(err)
+				response.InternalError(c, middleware.GetRequestID(c))
+				return
+			}
+			first = false
+		} else if _, err := f.NewSheet(sheet); err != nil {
+			c.Error(err)
+			response.InternalError(c, middleware.GetRequestID(c))
+			return
+		}
+		// The stream writer spills to a temp file, so large tables aren't held in memory.
+		sw, err := f.NewStreamWriter(sheet)
+		if err != nil {
+			c.Error(err)
+			response.InternalError(c, middleware.GetRequestID(c))
+			return
+		}
+		row := 1
+		err = h.exportService.Export(c.Request.Context(), entity, filter, func(record []string) error {
+			cell, err := excelize.CoordinatesToCellName(1, row)
+			if err != nil {
+				return err
+			}
+			row++
+			values := make([]any, len(record))
+			for i, v := range record {
+				values[i] = v
+			}
+			return sw.SetRow(cell, values)
+		})
+		if err == nil {
+			err = sw.Flush()
+		}
+		if err != nil {
+			c.Error(err)
+			response.FromDomainError(c, err, middleware.GetRequestID(c))
+			return
+		}
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, exportFileName("export", filter)))
+	if _, err := f.WriteTo(c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+func parseExportFilter(c *gin.Context) (ports.ExportFilter, bool) {
+	var filter ports.ExportFilter
+	if raw := c.Query("round_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "invalid round_id", middleware.GetRequestID(c))
+			return filter, false
+		}
+		filter.RoundID = &id
+	}
+	if raw := c.Query("team_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "invalid team_id", middleware.GetRequestID(c))
+			return filter, false
+		}
+		filter.TeamID = &id
+	}
+	return filter, true
+}
+
+func exportFileName(name string, filter ports.ExportFilter) string {
+	out := "jokefactory_" + name
+	if filter.RoundID != nil {
+		out += fmt.Sprintf("_round-%d", *filter.RoundID)
+	}
+	if filter.TeamID != nil {
+		out += fmt.Sprintf("_team-%d", *filter.TeamID)
+	}
+	return out
+}
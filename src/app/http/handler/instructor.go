@@ -2,6 +2,8 @@ package handler
 
 import (
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -187,6 +189,214 @@ func (h *InstructorHandler) SetPopupState(c *gin.Context) {
 		"started_at":           round.StartedAt,
 		"ended_at":             round.EndedAt,
 		"is_popped_active":     round.IsPoppedActive,
+		"qc_routing_mode":      round.QCRoutingMode,
+	}})
+}
+
+func (h *InstructorHandler) SetQCRouting(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+
+	var req dto.QCRoutingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+
+	round, err := h.instructorService.SetQCRoutingMode(c.Request.Context(), roundID, domain.QCRoutingMode(req.QCRoutingMode))
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+
+	response.OK(c, gin.H{"round": gin.H{
+		"id":              round.ID,
+		"round_number":    round.RoundNumber,
+		"status":          round.Status,
+		"qc_routing_mode": round.QCRoutingMode,
+	}})
+}
+
+// SetLateJoinerPolicy decides what happens to participants joining after Assign.
+func (h *InstructorHandler) SetLateJoinerPolicy(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+
+	var req dto.LateJoinerPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+
+	policy := domain.LateJoinerPolicy(strings.ToUpper(req.LateJoinerPolicy))
+	round, err := h.instructorService.SetLateJoinerPolicy(c.Request.Context(), roundID, policy)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+
+	response.OK(c, gin.H{"round": gin.H{
+		"id":                 round.ID,
+		"round_number":       round.RoundNumber,
+		"status":             round.Status,
+		"late_joiner_policy": round.LateJoinerPolicy,
+	}})
+}
+
+// SetPricing picks flat, team-set or dynamic pricing for a round, with the price bounds
+// and the dynamic curve.
+func (h *InstructorHandler) SetPricing(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+
+	var req dto.PricingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+
+	round, err := h.instructorService.SetPricing(c.Request.Context(), roundID, domain.RoundPricing{
+		PricingMode:          domain.PricingMode(strings.ToUpper(req.PricingMode)),
+		MinJokePrice:         req.MinPrice,
+		MaxJokePrice:         req.MaxPrice,
+		PriceStep:            req.PriceStep,
+		PriceDecay:           req.PriceDecay,
+		AuctionRule:          domain.AuctionRule(strings.ToUpper(req.AuctionRule)),
+		AuctionWindowSeconds: req.AuctionWindowSeconds,
+	})
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+
+	response.OK(c, gin.H{"round": gin.H{
+		"id":                     round.ID,
+		"round_number":           round.RoundNumber,
+		"status":                 round.Status,
+		"market_price":           round.MarketPrice,
+		"pricing_mode":           round.PricingMode,
+		"min_joke_price":         round.MinJokePrice,
+		"max_joke_price":         round.MaxJokePrice,
+		"price_step":             round.PriceStep,
+		"price_decay":            round.PriceDecay,
+		"auction_rule":           round.AuctionRule,
+		"auction_window_seconds": round.AuctionWindowSeconds,
+	}})
+}
+
+// SetReturnPolicy sets whether and how customers may return purchases in a round.
+func (h *InstructorHandler) SetReturnPolicy(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+
+	var req dto.ReturnPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+
+	round, err := h.instructorService.SetReturnPolicy(c.Request.Context(), roundID, domain.ReturnPolicy{
+		ReturnsAllowed:      *req.ReturnsAllowed,
+		ReturnWindowSeconds: req.ReturnWindowSeconds,
+		MaxReturns:          req.MaxReturns,
+	}, req.RefundPercent)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+
+	response.OK(c, gin.H{"round": gin.H{
+		"id":                    round.ID,
+		"round_number":          round.RoundNumber,
+		"status":                round.Status,
+		"returns_allowed":       round.ReturnsAllowed,
+		"return_window_seconds": round.ReturnWindowSeconds,
+		"max_returns":           round.MaxReturns,
+		"refund_percent":        round.RefundPercent,
+	}})
+}
+
+// SetChatEnabled turns team chat on or off for a round.
+func (h *InstructorHandler) SetChatEnabled(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+
+	var req dto.ChatStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+
+	round, err := h.instructorService.SetChatEnabled(c.Request.Context(), roundID, *req.ChatEnabled)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+
+	response.OK(c, gin.H{"round": gin.H{
+		"id":           round.ID,
+		"round_number": round.RoundNumber,
+		"status":       round.Status,
+		"chat_enabled": round.ChatEnabled,
+	}})
+}
+
+// SetParticipantCap limits how many students can join; later joiners are waitlisted.
+func (h *InstructorHandler) SetParticipantCap(c *gin.Context) {
+	var req dto.ParticipantCapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+	settings, err := h.instructorService.SetParticipantCap(c.Request.Context(), req.MaxParticipants)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, settings)
+}
+
+func (h *InstructorHandler) SetWIPLimits(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+
+	var req dto.WIPLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+
+	round, err := h.instructorService.SetWIPLimits(c.Request.Context(), roundID, req.MaxUnratedBatches, req.MaxUnratedJokes)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+
+	response.OK(c, gin.H{"round": gin.H{
+		"id":                  round.ID,
+		"round_number":        round.RoundNumber,
+		"status":              round.Status,
+		"max_unrated_batches": round.MaxUnratedBatches,
+		"max_unrated_jokes":   round.MaxUnratedJokes,
 	}})
 }
 
@@ -205,6 +415,7 @@ func (h *InstructorHandler) Stats(c *gin.Context) {
 	}
 	response.OK(c, gin.H{
 		"round_id":               stats.RoundID,
+		"qc_routing_mode":        stats.QCRoutingMode,
 		"leaderboard":            stats.Leaderboard,
 		"rejection_by_team":      stats.RejectionByTeam,
 		"sales_over_time":        stats.SalesOverTime,
@@ -214,7 +425,196 @@ func (h *InstructorHandler) Stats(c *gin.Context) {
 	})
 }
 
-// DeleteUser removes a non-instructor user from the round and database.
+// FlowMetrics returns lead/cycle time distributions, throughput and WIP per team.
+// Optional query: bucket_seconds (time-series resolution, default 60).
+func (h *InstructorHandler) FlowMetrics(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	var bucket time.Duration
+	if raw := c.Query("bucket_seconds"); raw != "" {
+		secs, err := strconv.Atoi(raw)
+		if err != nil || secs <= 0 {
+			response.BadRequest(c, "invalid bucket_seconds", middleware.GetRequestID(c))
+			return
+		}
+		bucket = time.Duration(secs) * time.Second
+	}
+	metrics, err := h.instructorService.FlowMetrics(c.Request.Context(), roundID, bucket)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, metrics)
+}
+
+// CumulativeFlow returns per-team joke state counts sampled across the round.
+// Optional query: interval_seconds (sampling interval, default 60).
+func (h *InstructorHandler) CumulativeFlow(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	var interval time.Duration
+	if raw := c.Query("interval_seconds"); raw != "" {
+		secs, err := strconv.Atoi(raw)
+		if err != nil || secs <= 0 {
+			response.BadRequest(c, "invalid interval_seconds", middleware.GetRequestID(c))
+			return
+		}
+		interval = time.Duration(secs) * time.Second
+	}
+	cfd, err := h.instructorService.CumulativeFlow(c.Request.Context(), roundID, interval)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, cfd)
+}
+
+// PriceHistory returns one joke's price over the round as a chart series.
+func (h *InstructorHandler) PriceHistory(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	jokeID, err := strconv.ParseInt(c.Param("joke_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid joke id", middleware.GetRequestID(c))
+		return
+	}
+	history, err := h.instructorService.PriceHistory(c.Request.Context(), roundID, jokeID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, history)
+}
+
+// ReplayState returns the round's leaderboard, market and per-team queues as of a past
+// instant. Optional query: at (RFC 3339, default now).
+func (h *InstructorHandler) ReplayState(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		at, err = time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			response.BadRequest(c, "invalid at", middleware.GetRequestID(c))
+			return
+		}
+	}
+	state, err := h.instructorService.ReplayState(c.Request.Context(), roundID, at)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, state)
+}
+
+// ReplayEvents pages through the round's history in time order.
+// Optional query: after_seq (default 0), limit (default 500).
+func (h *InstructorHandler) ReplayEvents(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	var afterSeq, limit int
+	if raw := c.Query("after_seq"); raw != "" {
+		if afterSeq, err = strconv.Atoi(raw); err != nil {
+			response.BadRequest(c, "invalid after_seq", middleware.GetRequestID(c))
+			return
+		}
+	}
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 {
+			response.BadRequest(c, "invalid limit", middleware.GetRequestID(c))
+			return
+		}
+	}
+	feed, err := h.instructorService.ReplayEvents(c.Request.Context(), roundID, afterSeq, limit)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, feed)
+}
+
+// CompareRounds returns per-team and class-wide metrics across rounds, with deltas.
+// Query: round_ids=1,2 (comma-separated or repeated), compared in the order given.
+func (h *InstructorHandler) CompareRounds(c *gin.Context) {
+	var roundIDs []int64
+	for _, raw := range c.QueryArray("round_ids") {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+				return
+			}
+			roundIDs = append(roundIDs, id)
+		}
+	}
+	comparison, err := h.instructorService.CompareRounds(c.Request.Context(), roundIDs)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, comparison)
+}
+
+// UndoLobby reverts the round's most recent lobby operation while it is still CONFIGURED.
+func (h *InstructorHandler) UndoLobby(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	entry, lobby, err := h.instructorService.UndoLobby(c.Request.Context(), roundID)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{
+		"undone": entry,
+		"lobby":  lobby,
+	})
+}
+
+// ListLobbyUndo returns the round's undo stack, next to undo first.
+func (h *InstructorHandler) ListLobbyUndo(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	entries, err := h.instructorService.ListLobbyUndo(c.Request.Context(), roundID)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{"round_id": roundID, "entries": entries})
+}
+
+// DeleteUser soft-deletes a non-instructor user; RestoreUser undoes it.
 func (h *InstructorHandler) DeleteUser(c *gin.Context) {
 	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
 	if err != nil {
@@ -227,10 +627,77 @@ func (h *InstructorHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.instructorService.DeleteUser(c.Request.Context(), roundID, userID); err != nil {
+	if err := h.instructorService.DeleteUser(c.Request.Context(), roundID, userID, c.GetInt64("user_id")); err != nil {
 		response.FromDomainError(c, err, middleware.GetRequestID(c))
 		return
 	}
 
 	response.OK(c, gin.H{"deleted_user_id": userID})
 }
+
+// RestoreUser brings back a soft-deleted user and returns the updated lobby.
+func (h *InstructorHandler) RestoreUser(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user id", middleware.GetRequestID(c))
+		return
+	}
+
+	lobby, err := h.instructorService.RestoreUser(c.Request.Context(), roundID, userID)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, lobby)
+}
+
+// ReplaceUser hands a dropped JM/QC's role to a waiting participant.
+func (h *InstructorHandler) ReplaceUser(c *gin.Context) {
+	roundID, err := strconv.ParseInt(c.Param("round_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user id", middleware.GetRequestID(c))
+		return
+	}
+	var req dto.ReplaceUserRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+			return
+		}
+	}
+
+	lobby, err := h.instructorService.ReplaceDropped(c.Request.Context(), roundID, userID, req.ReplacementUserID)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, lobby)
+}
+
+// MergeUsers folds a duplicate user into another and returns the merged user.
+func (h *InstructorHandler) MergeUsers(c *gin.Context) {
+	var req dto.MergeUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+	user, err := h.instructorService.MergeUsers(c.Request.Context(), req.SourceUserID, req.TargetUserID, c.GetInt64("user_id"))
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{"merged_user_id": req.SourceUserID, "user": user})
+}
@@ -120,7 +120,17 @@ func (h *QCHandler) QueueCount(c *gin.Context) {
 		response.BadRequest(c, "invalid round id", middleware.GetRequestID(c))
 		return
 	}
-	count, err := h.qcService.QueueCount(c.Request.Context(), roundID)
+	// X-User-Id is optional here: a QC gets their routed queue, anyone else the round total.
+	var userID *int64
+	if raw := c.GetHeader("X-User-Id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "invalid X-User-Id header", middleware.GetRequestID(c))
+			return
+		}
+		userID = &id
+	}
+	count, err := h.qcService.QueueCount(c.Request.Context(), roundID, userID)
 	if err != nil {
 		response.FromDomainError(c, err, middleware.GetRequestID(c))
 		return
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"jokefactory/src/app/http/dto"
+	"jokefactory/src/app/http/response"
+	"jokefactory/src/app/middleware"
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/usecase"
+)
+
+// RosterHandler handles the class roster and join-code settings.
+type RosterHandler struct {
+	rosterService *usecase.RosterService
+}
+
+func NewRosterHandler(rosterService *usecase.RosterService) *RosterHandler {
+	return &RosterHandler{rosterService: rosterService}
+}
+
+// Import accepts a roster as JSON ({"entries": [...]}) or, with Content-Type text/csv, as
+// CSV with a header row naming the columns display_name (or name), and optionally
+// student_id, preferred_team (or team) and preferred_role (or role).
+func (h *RosterHandler) Import(c *gin.Context) {
+	var entries []domain.RosterEntry
+	var err error
+	if strings.HasPrefix(c.ContentType(), "text/csv") {
+		entries, err = parseRosterCSV(c.Request.Body)
+	} else {
+		var req dto.RosterImportRequest
+		if err = c.ShouldBindJSON(&req); err != nil {
+			err = errors.New("invalid payload")
+		} else {
+			entries = make([]domain.RosterEntry, 0, len(req.Entries))
+			for _, e := range req.Entries {
+				entry := domain.RosterEntry{DisplayName: e.DisplayName, StudentID: e.StudentID, PreferredTeam: e.PreferredTeam}
+				if e.PreferredRole != nil && *e.PreferredRole != "" {
+					role := domain.Role(strings.ToUpper(*e.PreferredRole))
+					entry.PreferredRole = &role
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if err != nil {
+		response.BadRequest(c, err.Error(), middleware.GetRequestID(c))
+		return
+	}
+
+	stored, err := h.rosterService.Import(c.Request.Context(), entries)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{"imported": len(stored), "entries": stored})
+}
+
+// List returns the roster with every student's join code.
+func (h *RosterHandler) List(c *gin.Context) {
+	roster, err := h.rosterService.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, roster)
+}
+
+// Settings turns the join-code requirement on or off.
+func (h *RosterHandler) Settings(c *gin.Context) {
+	var req dto.RosterSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+	settings, err := h.rosterService.SetRequireJoinCode(c.Request.Context(), *req.RequireJoinCode)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, settings)
+}
+
+func parseRosterCSV(body io.Reader) ([]domain.RosterEntry, error) {
+	r := csv.NewReader(body)
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, errors.New("roster CSV has no header row")
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch name {
+		case "name":
+			name = "display_name"
+		case "team":
+			name = "preferred_team"
+		case "role":
+			name = "preferred_role"
+		}
+		col[name] = i
+	}
+	if _, ok := col["display_name"]; !ok {
+		return nil, errors.New("roster CSV needs a display_name (or name) column")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := col[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var entries []domain.RosterEntry
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("roster CSV line %d: %v", line, err)
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		entry := domain.RosterEntry{DisplayName: field(record, "display_name")}
+		if v := field(record, "student_id"); v != "" {
+			entry.StudentID = &v
+		}
+		if v := field(record, "preferred_team"); v != "" {
+			team, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("roster CSV line %d: preferred_team must be a team number", line)
+			}
+			entry.PreferredTeam = &team
+		}
+		if v := field(record, "preferred_role"); v != "" {
+			role := domain.Role(strings.ToUpper(v))
+			entry.PreferredRole = &role
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
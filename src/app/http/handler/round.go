@@ -45,6 +45,28 @@ func (h *RoundHandler) Active(c *gin.Context) {
 			"started_at":         rd.StartedAt,
 			"ended_at":           rd.EndedAt,
 			"is_popped_active":   rd.IsPoppedActive,
+			"qc_routing_mode":    rd.QCRoutingMode,
+			"late_joiner_policy": rd.LateJoinerPolicy,
+			"chat_enabled":       rd.ChatEnabled,
+			"pricing": gin.H{
+				"mode":                   rd.PricingMode,
+				"min_joke_price":         rd.MinJokePrice,
+				"max_joke_price":         rd.MaxJokePrice,
+				"price_step":             rd.PriceStep,
+				"price_decay":            rd.PriceDecay,
+				"auction_rule":           rd.AuctionRule,
+				"auction_window_seconds": rd.AuctionWindowSeconds,
+			},
+			"return_policy": gin.H{
+				"returns_allowed":       rd.ReturnsAllowed,
+				"return_window_seconds": rd.ReturnWindowSeconds,
+				"max_returns":           rd.MaxReturns,
+				"refund_percent":        rd.RefundPercent,
+			},
+			"wip_limit": gin.H{
+				"max_unrated_batches": rd.MaxUnratedBatches,
+				"max_unrated_jokes":   rd.MaxUnratedJokes,
+			},
 		})
 	}
 
@@ -86,5 +108,11 @@ func (h *RoundHandler) TeamSummary(c *gin.Context) {
 		"accepted_jokes":    summary.AcceptedJokes,
 		"avg_score_overall": summary.AvgScoreOverall,
 		"unrated_batches":   summary.UnratedBatches,
+		"unrated_jokes":     summary.UnratedJokes,
+		"wip_limit": gin.H{
+			"max_unrated_batches": summary.MaxUnratedBatches,
+			"max_unrated_jokes":   summary.MaxUnratedJokes,
+			"can_submit":          summary.CanSubmit,
+		},
 	})
 }
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"jokefactory/src/app/http/dto"
+	"jokefactory/src/app/http/response"
+	"jokefactory/src/app/middleware"
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/usecase"
+)
+
+// ScriptHandler manages game scripts and controls the script run in progress.
+type ScriptHandler struct {
+	scriptService *usecase.ScriptService
+}
+
+func NewScriptHandler(scriptService *usecase.ScriptService) *ScriptHandler {
+	return &ScriptHandler{scriptService: scriptService}
+}
+
+func (h *ScriptHandler) Create(c *gin.Context) {
+	var req dto.ScriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+	script, err := h.scriptService.Create(c.Request.Context(), c.GetInt64("user_id"), req.Name, req.Steps)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.Created(c, gin.H{"script": scriptJSON(script)})
+}
+
+func (h *ScriptHandler) Update(c *gin.Context) {
+	scriptID, ok := parseScriptID(c)
+	if !ok {
+		return
+	}
+	var req dto.ScriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid payload", middleware.GetRequestID(c))
+		return
+	}
+	script, err := h.scriptService.Update(c.Request.Context(), scriptID, req.Name, req.Steps)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{"script": scriptJSON(script)})
+}
+
+func (h *ScriptHandler) Get(c *gin.Context) {
+	scriptID, ok := parseScriptID(c)
+	if !ok {
+		return
+	}
+	script, err := h.scriptService.Get(c.Request.Context(), scriptID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{"script": scriptJSON(script)})
+}
+
+func (h *ScriptHandler) List(c *gin.Context) {
+	scripts, err := h.scriptService.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	out := make([]gin.H, 0, len(scripts))
+	for i := range scripts {
+		out = append(out, scriptJSON(&scripts[i]))
+	}
+	response.OK(c, gin.H{"scripts": out})
+}
+
+func (h *ScriptHandler) Delete(c *gin.Context) {
+	scriptID, ok := parseScriptID(c)
+	if !ok {
+		return
+	}
+	if err := h.scriptService.Delete(c.Request.Context(), scriptID); err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{"deleted_script_id": scriptID})
+}
+
+// Play starts the script, or resumes it if its run is paused.
+func (h *ScriptHandler) Play(c *gin.Context) {
+	scriptID, ok := parseScriptID(c)
+	if !ok {
+		return
+	}
+	run, err := h.scriptService.Play(c.Request.Context(), scriptID, c.GetInt64("user_id"))
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{"run": scriptRunJSON(run)})
+}
+
+// Pause, Skip and Stop act on the run in progress.
+func (h *ScriptHandler) Pause(c *gin.Context) {
+	h.control(c, h.scriptService.Pause)
+}
+
+func (h *ScriptHandler) Skip(c *gin.Context) {
+	h.control(c, h.scriptService.Skip)
+}
+
+func (h *ScriptHandler) Stop(c *gin.Context) {
+	h.control(c, h.scriptService.Stop)
+}
+
+func (h *ScriptHandler) control(c *gin.Context, op func(ctx context.Context) (*domain.ScriptRun, error)) {
+	run, err := op(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, gin.H{"run": scriptRunJSON(run)})
+}
+
+// Current returns the run in progress and the log of its steps so far.
+func (h *ScriptHandler) Current(c *gin.Context) {
+	view, err := h.scriptService.Current(c.Request.Context())
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, scriptRunViewJSON(view))
+}
+
+// Run returns any past or current run with its step log.
+func (h *ScriptHandler) Run(c *gin.Context) {
+	runID, err := strconv.ParseInt(c.Param("run_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid run id", middleware.GetRequestID(c))
+		return
+	}
+	view, err := h.scriptService.Run(c.Request.Context(), runID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, scriptRunViewJSON(view))
+}
+
+func parseScriptID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("script_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid script id", middleware.GetRequestID(c))
+		return 0, false
+	}
+	return id, true
+}
+
+func scriptJSON(s *domain.GameScript) gin.H {
+	return gin.H{
+		"script_id":  s.ID,
+		"name":       s.Name,
+		"steps":      s.Steps,
+		"created_by": s.CreatedBy,
+		"created_at": s.CreatedAt,
+		"updated_at": s.UpdatedAt,
+	}
+}
+
+func scriptRunJSON(run *domain.ScriptRun) gin.H {
+	out := gin.H{
+		"run_id":          run.ID,
+		"script_id":       run.ScriptID,
+		"script_name":     run.ScriptName,
+		"status":          run.Status,
+		"steps":           run.Steps,
+		"next_step":       run.NextStep,
+		"elapsed_seconds": run.ElapsedAt(time.Now()).Seconds(),
+		"started_by":      run.StartedBy,
+		"started_at":      run.StartedAt,
+		"ended_at":        run.EndedAt,
+		"next_step_at":    nil,
+	}
+	if run.NextStep < len(run.Steps) {
+		out["next_step_at"] = run.Steps[run.NextStep].AtSeconds
+	}
+	return out
+}
+
+func scriptRunViewJSON(view *usecase.ScriptRunView) gin.H {
+	log := make([]gin.H, 0, len(view.Log))
+	for _, e := range view.Log {
+		log = append(log, gin.H{
+			"step_index":  e.StepIndex,
+			"action":      e.Action,
+			"outcome":     e.Outcome,
+			"error":       e.Error,
+			"executed_at": e.ExecutedAt,
+		})
+	}
+	return gin.H{"run": scriptRunJSON(view.Run), "log": log}
+}
@@ -28,7 +28,7 @@ func (h *SessionHandler) Join(c *gin.Context) {
 		return
 	}
 
-	res, err := h.sessionService.Join(c.Request.Context(), req.DisplayName)
+	res, err := h.sessionService.Join(c.Request.Context(), req.DisplayName, req.RejoinSecret, req.JoinCode)
 	if err != nil {
 		// Attach error for middleware logging
 		c.Error(err)
@@ -46,6 +46,8 @@ func (h *SessionHandler) Join(c *gin.Context) {
 			"joined_at":   res.User.JoinedAt,
 			"assigned_at": res.User.AssignedAt,
 		},
+		"rejoin_secret": res.RejoinSecret,
+		"rejoined":      res.Rejoined,
 	})
 }
 
@@ -94,7 +96,28 @@ func (h *SessionHandler) Me(c *gin.Context) {
 			"role":    role,
 			"team_id": team,
 		},
-		"teammates": teammates,
+		"teammates":     teammates,
+		"announcements": userAnnouncementsJSON(res.Announcements),
+	})
+}
+
+// Heartbeat keeps the caller's presence ONLINE; clients should call it more often than
+// idle_after_seconds.
+func (h *SessionHandler) Heartbeat(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	res, err := h.sessionService.Heartbeat(c.Request.Context(), userID)
+	if err != nil {
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":            userID,
+		"presence":           res.Presence,
+		"last_seen_at":       res.LastSeenAt,
+		"idle_after_seconds": int(res.IdleAfter.Seconds()),
 	})
 }
 
@@ -111,4 +134,3 @@ func parseUserID(c *gin.Context) (int64, bool) {
 	}
 	return id, true
 }
-
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"jokefactory/src/app/http/response"
+	"jokefactory/src/app/middleware"
+	"jokefactory/src/core/ports"
+	"jokefactory/src/core/usecase"
+)
+
+// maxSnapshotBytes caps the size of an uploaded snapshot.
+const maxSnapshotBytes = 64 << 20
+
+// SnapshotHandler handles whole-game archive download and restore.
+type SnapshotHandler struct {
+	snapshotService *usecase.SnapshotService
+}
+
+func NewSnapshotHandler(snapshotService *usecase.SnapshotService) *SnapshotHandler {
+	return &SnapshotHandler{snapshotService: snapshotService}
+}
+
+// Export downloads the whole game as a JSON snapshot.
+func (h *SnapshotHandler) Export(c *gin.Context) {
+	snap, err := h.snapshotService.Export(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="jokefactory_snapshot_%s.json"`, snap.ExportedAt.Format("20060102T150405Z")))
+	c.JSON(http.StatusOK, snap)
+}
+
+// Import restores a JSON snapshot (the body of a previous Export) into an empty game.
+func (h *SnapshotHandler) Import(c *gin.Context) {
+	var snap ports.GameSnapshot
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSnapshotBytes)
+	if err := json.NewDecoder(body).Decode(&snap); err != nil {
+		response.BadRequest(c, "invalid snapshot", middleware.GetRequestID(c))
+		return
+	}
+	res, err := h.snapshotService.Import(c.Request.Context(), &snap)
+	if err != nil {
+		c.Error(err)
+		response.FromDomainError(c, err, middleware.GetRequestID(c))
+		return
+	}
+	response.OK(c, res)
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamKeepAlive is how often an idle event stream sends a comment so proxies keep it open.
+const streamKeepAlive = 20 * time.Second
+
+// allowQueryUserID lets a stream take the caller from ?user_id=, since browsers'
+// EventSource can't set headers. The X-User-Id header wins when both are given.
+func allowQueryUserID(c *gin.Context) {
+	if c.GetHeader("X-User-Id") == "" && c.Query("user_id") != "" {
+		c.Request.Header.Set("X-User-Id", c.Query("user_id"))
+	}
+}
+
+// startEventStream writes the headers of a server-sent event response. Call it only once
+// nothing can fail with a plain JSON error any more.
+func startEventStream(c *gin.Context) {
+	// The stream outlives the server's write timeout.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+}
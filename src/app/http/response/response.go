@@ -49,6 +49,15 @@ func OK(c *gin.Context, data any) {
 	c.JSON(http.StatusOK, Success{Data: data})
 }
 
+// Page sends a 200 paginated list response.
+func Page(c *gin.Context, data any, total int64, page, perPage int) {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = int((total + int64(perPage) - 1) / int64(perPage))
+	}
+	c.JSON(http.StatusOK, Paginated{Data: data, Total: total, Page: page, PerPage: perPage, TotalPages: totalPages})
+}
+
 // Created sends a 201 response with the created resource.
 func Created(c *gin.Context, data any) {
 	c.JSON(http.StatusCreated, Success{Data: data})
@@ -8,13 +8,15 @@ import (
 	"jokefactory/src/app/http/response"
 	"jokefactory/src/core/domain"
 	"jokefactory/src/core/ports"
+	"jokefactory/src/core/usecase"
 )
 
 const instructorHeader = "X-User-Id"
 
 // InstructorAuth enforces that the incoming request is made by an instructor.
 // It reads the X-User-Id header, validates the user exists, and checks the role.
-// On success it stores the user ID in the context under the key "user_id".
+// On success it stores the user ID in the context under the key "user_id" and attaches
+// the instructor to the request context as the audit actor.
 func InstructorAuth(repo ports.GameRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := GetRequestID(c)
@@ -47,6 +49,12 @@ func InstructorAuth(repo ports.GameRepository) gin.HandlerFunc {
 		}
 
 		c.Set("user_id", userID)
+		// Attribute any mutation made by this request in the audit log.
+		c.Request = c.Request.WithContext(usecase.WithAuditActor(c.Request.Context(), usecase.AuditActor{
+			UserID:      userID,
+			DisplayName: user.DisplayName,
+			RequestID:   requestID,
+		}))
 		c.Next()
 	}
 }
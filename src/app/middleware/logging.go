@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -91,3 +92,9 @@ func levelString(status int) string {
 		return "INFO"
 	}
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController, which streaming
+// handlers use to lift the server's write deadline.
+func (r *responseCapture) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -16,6 +16,7 @@ import (
 
 	"jokefactory/src/app/http/handler"
 	"jokefactory/src/app/middleware"
+	"jokefactory/src/core/domain"
 	"jokefactory/src/core/ports"
 	"jokefactory/src/core/usecase"
 	"jokefactory/src/infra/config"
@@ -30,14 +31,25 @@ type Server struct {
 	repo   ports.GameRepository
 
 	// Handlers
-	healthHandler     *handler.HealthHandler
-	sessionHandler    *handler.SessionHandler
-	roundHandler      *handler.RoundHandler
-	batchHandler      *handler.BatchHandler
-	qcHandler         *handler.QCHandler
-	customerHandler   *handler.CustomerHandler
-	instructorHandler *handler.InstructorHandler
-	adminHandler      *handler.AdminHandler
+	healthHandler       *handler.HealthHandler
+	sessionHandler      *handler.SessionHandler
+	roundHandler        *handler.RoundHandler
+	batchHandler        *handler.BatchHandler
+	qcHandler           *handler.QCHandler
+	customerHandler     *handler.CustomerHandler
+	instructorHandler   *handler.InstructorHandler
+	exportHandler       *handler.ExportHandler
+	snapshotHandler     *handler.SnapshotHandler
+	auditHandler        *handler.AuditHandler
+	rosterHandler       *handler.RosterHandler
+	announcementHandler *handler.AnnouncementHandler
+	adminHandler        *handler.AdminHandler
+	scriptHandler       *handler.ScriptHandler
+	chatHandler         *handler.ChatHandler
+
+	// Background jobs
+	scriptService   *usecase.ScriptService
+	customerService *usecase.CustomerService
 }
 
 // New creates a new Server with all dependencies wired up.
@@ -53,13 +65,21 @@ func New(cfg *config.Config, log *slog.Logger, repo ports.GameRepository) *Serve
 	router := gin.New()
 
 	// Create services
+	presence := domain.PresencePolicy{IdleAfter: cfg.Presence.IdleAfter, DropAfter: cfg.Presence.DropAfter}
 	healthService := usecase.NewHealthService(log)
-	sessionService := usecase.NewSessionService(repo, log)
+	sessionService := usecase.NewSessionService(repo, log, presence)
 	roundService := usecase.NewRoundService(repo, log)
 	batchService := usecase.NewBatchService(repo, log)
 	qcService := usecase.NewQCService(repo, log)
 	customerService := usecase.NewCustomerService(repo, log)
-	instructorService := usecase.NewInstructorService(repo, log)
+	instructorService := usecase.NewInstructorService(repo, log, presence)
+	exportService := usecase.NewExportService(repo, log)
+	snapshotService := usecase.NewSnapshotService(repo, log)
+	auditService := usecase.NewAuditService(repo, log)
+	rosterService := usecase.NewRosterService(repo, log)
+	announcementService := usecase.NewAnnouncementService(repo, log)
+	scriptService := usecase.NewScriptService(repo, log, instructorService, announcementService)
+	chatService := usecase.NewChatService(repo, log)
 	adminService := usecase.NewAdminAuthService(repo, cfg.Admin.AdminPassword)
 
 	// Create handlers
@@ -70,21 +90,37 @@ func New(cfg *config.Config, log *slog.Logger, repo ports.GameRepository) *Serve
 	qcHandler := handler.NewQCHandler(qcService)
 	customerHandler := handler.NewCustomerHandler(customerService)
 	instructorHandler := handler.NewInstructorHandler(instructorService)
+	exportHandler := handler.NewExportHandler(exportService)
+	snapshotHandler := handler.NewSnapshotHandler(snapshotService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	rosterHandler := handler.NewRosterHandler(rosterService)
+	announcementHandler := handler.NewAnnouncementHandler(announcementService)
+	scriptHandler := handler.NewScriptHandler(scriptService)
+	chatHandler := handler.NewChatHandler(chatService)
 	adminHandler := handler.NewAdminHandler(adminService)
 
 	s := &Server{
-		cfg:               cfg,
-		log:               log,
-		router:            router,
-		repo:              repo,
-		healthHandler:     healthHandler,
-		sessionHandler:    sessionHandler,
-		roundHandler:      roundHandler,
-		batchHandler:      batchHandler,
-		qcHandler:         qcHandler,
-		customerHandler:   customerHandler,
-		instructorHandler: instructorHandler,
-		adminHandler:      adminHandler,
+		cfg:                 cfg,
+		log:                 log,
+		router:              router,
+		repo:                repo,
+		healthHandler:       healthHandler,
+		sessionHandler:      sessionHandler,
+		roundHandler:        roundHandler,
+		batchHandler:        batchHandler,
+		qcHandler:           qcHandler,
+		customerHandler:     customerHandler,
+		instructorHandler:   instructorHandler,
+		exportHandler:       exportHandler,
+		snapshotHandler:     snapshotHandler,
+		auditHandler:        auditHandler,
+		rosterHandler:       rosterHandler,
+		announcementHandler: announcementHandler,
+		adminHandler:        adminHandler,
+		scriptHandler:       scriptHandler,
+		chatHandler:         chatHandler,
+		scriptService:       scriptService,
+		customerService:     customerService,
 	}
 
 	s.setupMiddleware()
@@ -119,6 +155,10 @@ func (s *Server) setupRoutes() {
 		// Session
 		v1.POST("/session/join", s.sessionHandler.Join)
 		v1.GET("/session/me", s.sessionHandler.Me)
+		v1.POST("/session/heartbeat", s.sessionHandler.Heartbeat)
+		v1.GET("/session/announcements", s.announcementHandler.Mine)
+		v1.GET("/session/announcements/stream", s.announcementHandler.Stream)
+		v1.POST("/session/announcements/:announcement_id/ack", s.announcementHandler.Ack)
 
 		// Admin/Instructor login
 		v1.POST("/instructor/login", s.adminHandler.Login)
@@ -130,6 +170,7 @@ func (s *Server) setupRoutes() {
 		// JM batches
 		v1.POST("/rounds/:round_id/batches", s.batchHandler.Submit)
 		v1.GET("/rounds/:round_id/teams/:team_id/batches", s.batchHandler.List)
+		v1.PUT("/rounds/:round_id/jokes/:joke_id/price", s.batchHandler.SetJokePrice)
 
 		// QC
 		v1.GET("/qc/queue/next", s.qcHandler.QueueNext)
@@ -139,24 +180,77 @@ func (s *Server) setupRoutes() {
 		// Customers
 		v1.GET("/rounds/:round_id/market", s.customerHandler.Market)
 		v1.GET("/rounds/:round_id/customers/budget", s.customerHandler.Budget)
+		v1.GET("/rounds/:round_id/customers/purchases", s.customerHandler.Purchases)
 		v1.POST("/rounds/:round_id/market/:joke_id/buy", s.customerHandler.Buy)
 		v1.POST("/rounds/:round_id/market/:joke_id/return", s.customerHandler.Return)
+		v1.PUT("/rounds/:round_id/market/:joke_id/rating", s.customerHandler.Rate)
+		v1.PUT("/rounds/:round_id/market/:joke_id/bid", s.customerHandler.Bid)
+		v1.GET("/rounds/:round_id/customers/bids", s.customerHandler.Bids)
+
+		// Chat: team channels for JM/QC, the round channel for instructor messages
+		v1.GET("/rounds/:round_id/teams/:team_id/messages", s.chatHandler.List)
+		v1.POST("/rounds/:round_id/teams/:team_id/messages", s.chatHandler.Post)
+		v1.POST("/rounds/:round_id/teams/:team_id/messages/read", s.chatHandler.MarkRead)
+		v1.GET("/rounds/:round_id/messages", s.chatHandler.List)
+		v1.POST("/rounds/:round_id/messages/read", s.chatHandler.MarkRead)
+		v1.GET("/rounds/:round_id/messages/stream", s.chatHandler.Stream)
 	}
 
 	// Instructor-protected routes
 	instructor := v1.Group("", middleware.InstructorAuth(s.repo))
 	{
 		instructor.POST("/admin/reset", s.adminHandler.ResetGame)
+		instructor.GET("/admin/snapshot", s.snapshotHandler.Export)
+		instructor.POST("/admin/snapshot", s.snapshotHandler.Import)
 
 		instructor.GET("/instructor/rounds/:round_id/lobby", s.instructorHandler.Lobby)
 		instructor.POST("/instructor/rounds/:round_id/config", s.instructorHandler.Config)
 		instructor.POST("/instructor/rounds/:round_id/assign", s.instructorHandler.Assign)
 		instructor.PATCH("/instructor/rounds/:round_id/users/:user_id", s.instructorHandler.PatchUser)
 		instructor.DELETE("/instructor/rounds/:round_id/users/:user_id", s.instructorHandler.DeleteUser)
+		instructor.POST("/instructor/rounds/:round_id/users/:user_id/restore", s.instructorHandler.RestoreUser)
+		instructor.POST("/instructor/rounds/:round_id/users/:user_id/replace", s.instructorHandler.ReplaceUser)
+		instructor.GET("/instructor/rounds/:round_id/undo", s.instructorHandler.ListLobbyUndo)
+		instructor.POST("/instructor/rounds/:round_id/undo", s.instructorHandler.UndoLobby)
 		instructor.POST("/instructor/rounds/:round_id/start", s.instructorHandler.StartRound)
 		instructor.POST("/instructor/rounds/:round_id/end", s.instructorHandler.EndRound)
 		instructor.POST("/instructor/rounds/:round_id/popups", s.instructorHandler.SetPopupState)
+		instructor.POST("/instructor/rounds/:round_id/qc-routing", s.instructorHandler.SetQCRouting)
+		instructor.POST("/instructor/rounds/:round_id/wip-limits", s.instructorHandler.SetWIPLimits)
+		instructor.POST("/instructor/rounds/:round_id/late-joiners", s.instructorHandler.SetLateJoinerPolicy)
+		instructor.POST("/instructor/rounds/:round_id/chat", s.instructorHandler.SetChatEnabled)
+		instructor.POST("/instructor/rounds/:round_id/pricing", s.instructorHandler.SetPricing)
+		instructor.POST("/instructor/rounds/:round_id/returns", s.instructorHandler.SetReturnPolicy)
+		instructor.POST("/instructor/rounds/:round_id/messages", s.chatHandler.Broadcast)
 		instructor.GET("/instructor/rounds/:round_id/stats", s.instructorHandler.Stats)
+		instructor.GET("/instructor/rounds/:round_id/stats/flow", s.instructorHandler.FlowMetrics)
+		instructor.GET("/instructor/rounds/:round_id/stats/cfd", s.instructorHandler.CumulativeFlow)
+		instructor.GET("/instructor/rounds/:round_id/stats/prices/:joke_id", s.instructorHandler.PriceHistory)
+		instructor.GET("/instructor/rounds/:round_id/replay/state", s.instructorHandler.ReplayState)
+		instructor.GET("/instructor/rounds/:round_id/replay/events", s.instructorHandler.ReplayEvents)
+		instructor.GET("/instructor/stats/compare", s.instructorHandler.CompareRounds)
+		instructor.POST("/instructor/users/merge", s.instructorHandler.MergeUsers)
+		instructor.GET("/instructor/roster", s.rosterHandler.List)
+		instructor.POST("/instructor/roster", s.rosterHandler.Import)
+		instructor.PUT("/instructor/roster/settings", s.rosterHandler.Settings)
+		instructor.PUT("/instructor/settings/participant-cap", s.instructorHandler.SetParticipantCap)
+		instructor.GET("/instructor/announcements", s.announcementHandler.List)
+		instructor.POST("/instructor/announcements", s.announcementHandler.Create)
+		instructor.GET("/instructor/announcements/:announcement_id/receipts", s.announcementHandler.Receipts)
+		instructor.GET("/instructor/scripts", s.scriptHandler.List)
+		instructor.POST("/instructor/scripts", s.scriptHandler.Create)
+		instructor.GET("/instructor/scripts/:script_id", s.scriptHandler.Get)
+		instructor.PUT("/instructor/scripts/:script_id", s.scriptHandler.Update)
+		instructor.DELETE("/instructor/scripts/:script_id", s.scriptHandler.Delete)
+		instructor.POST("/instructor/scripts/:script_id/play", s.scriptHandler.Play)
+		instructor.GET("/instructor/script-run", s.scriptHandler.Current)
+		instructor.POST("/instructor/script-run/pause", s.scriptHandler.Pause)
+		instructor.POST("/instructor/script-run/skip", s.scriptHandler.Skip)
+		instructor.POST("/instructor/script-run/stop", s.scriptHandler.Stop)
+		instructor.GET("/instructor/script-runs/:run_id", s.scriptHandler.Run)
+		instructor.GET("/instructor/audit", s.auditHandler.List)
+		instructor.GET("/instructor/export", s.exportHandler.XLSX)
+		instructor.GET("/instructor/export/:entity", s.exportHandler.CSV)
 	}
 
 	// Handle 404
@@ -191,6 +285,12 @@ func (s *Server) Run() error {
 	// Channel to receive server errors
 	errCh := make(chan error, 1)
 
+	// Background jobs run until the server stops.
+	jobs, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go s.scriptService.RunScheduler(jobs)
+	go s.customerService.RunAuctions(jobs)
+
 	// Start server in goroutine
 	go func() {
 		s.log.Info("starting HTTP server",
@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"time"
+)
 
 // Role represents a user's role in the game.
 type Role string
@@ -32,8 +36,40 @@ type ParticipantStatus string
 const (
 	ParticipantWaiting  ParticipantStatus = "WAITING"
 	ParticipantAssigned ParticipantStatus = "ASSIGNED"
+	// ParticipantWaitlisted is someone who joined once the game was full.
+	ParticipantWaitlisted ParticipantStatus = "WAITLISTED"
+)
+
+// Presence is whether a participant's client is still connected. It is derived from the
+// last heartbeat and never stored.
+type Presence string
+
+const (
+	PresenceOnline  Presence = "ONLINE"
+	PresenceIdle    Presence = "IDLE"
+	PresenceDropped Presence = "DROPPED"
 )
 
+// PresencePolicy holds how long after the last heartbeat a participant counts as idle,
+// and then as dropped.
+type PresencePolicy struct {
+	IdleAfter time.Duration
+	DropAfter time.Duration
+}
+
+// At returns the presence of a participant last seen at lastSeen, as of now.
+func (p PresencePolicy) At(lastSeen, now time.Time) Presence {
+	since := now.Sub(lastSeen)
+	switch {
+	case since >= p.DropAfter:
+		return PresenceDropped
+	case since >= p.IdleAfter:
+		return PresenceIdle
+	default:
+		return PresenceOnline
+	}
+}
+
 // RoundStatus represents lifecycle of a round.
 type RoundStatus string
 
@@ -52,6 +88,63 @@ const (
 	BatchRated     BatchStatus = "RATED"
 )
 
+// QCRoutingMode decides which teams' batches a QC is served in a round.
+type QCRoutingMode string
+
+const (
+	// QCRoutingOwnTeam serves a QC only batches from their own team.
+	QCRoutingOwnTeam QCRoutingMode = "OWN_TEAM"
+	// QCRoutingRotation has team N rated by team N+1 (teams ordered by id, wrapping around).
+	QCRoutingRotation QCRoutingMode = "ROTATION"
+	// QCRoutingGlobalPool lets any QC take the oldest submitted batch in the round.
+	QCRoutingGlobalPool QCRoutingMode = "GLOBAL_POOL"
+)
+
+// IsValid reports whether m is a known routing mode.
+func (m QCRoutingMode) IsValid() bool {
+	switch m {
+	case QCRoutingOwnTeam, QCRoutingRotation, QCRoutingGlobalPool:
+		return true
+	}
+	return false
+}
+
+// LateJoinerPolicy decides what happens to participants who join after a round's teams
+// have been assigned.
+type LateJoinerPolicy string
+
+const (
+	// LateJoinersWait leaves them waiting for the instructor.
+	LateJoinersWait LateJoinerPolicy = "WAIT"
+	// LateJoinersCustomer makes them customers.
+	LateJoinersCustomer LateJoinerPolicy = "CUSTOMER"
+	// LateJoinersSmallestTeam adds them to the team with the fewest members, in whichever
+	// of JM/QC that team has fewer of.
+	LateJoinersSmallestTeam LateJoinerPolicy = "SMALLEST_TEAM"
+)
+
+// IsValid reports whether p is a known late-joiner policy.
+func (p LateJoinerPolicy) IsValid() bool {
+	switch p {
+	case LateJoinersWait, LateJoinersCustomer, LateJoinersSmallestTeam:
+		return true
+	}
+	return false
+}
+
+// QCRotationSourceTeam returns the team whose batches are rated by qcTeamID under
+// QCRoutingRotation. teamIDs must be the round's teams ordered by id; the team at
+// index i is rated by the team at index i+1, so a QC rates the team just before it.
+// Returns false if qcTeamID is not part of the round.
+func QCRotationSourceTeam(teamIDs []int64, qcTeamID int64) (int64, bool) {
+	for i, id := range teamIDs {
+		if id == qcTeamID {
+			return teamIDs[(i-1+len(teamIDs))%len(teamIDs)], true
+		}
+	}
+	return 0, false
+}
+
 // Team represents a team.
 type Team struct {
 	ID        int64     `json:"id"`
@@ -69,6 +162,51 @@ type User struct {
 	AssignedAt  *time.Time
 	JoinedAt    time.Time
 	CreatedAt   time.Time
+	LastSeenAt  time.Time
+	// RemovedAt/RemovedBy are set when an instructor soft-deletes the user.
+	RemovedAt *time.Time
+	RemovedBy *int64
+}
+
+// PricingMode decides who sets the price of a published joke.
+type PricingMode string
+
+const (
+	// PricingFlat sells every joke at the round's market price.
+	PricingFlat PricingMode = "FLAT"
+	// PricingTeamSet lets each team price its own jokes within the round's bounds.
+	PricingTeamSet PricingMode = "TEAM_SET"
+	// PricingDynamic moves each joke's price with demand: every sale raises it by the
+	// round's PriceStep and it falls by PriceDecay per minute without one.
+	PricingDynamic PricingMode = "DYNAMIC"
+	// PricingAuction sells each joke by sealed bid to the highest bidder once its
+	// bidding window closes.
+	PricingAuction PricingMode = "AUCTION"
+)
+
+// IsValid reports whether m is a known pricing mode.
+func (m PricingMode) IsValid() bool {
+	switch m {
+	case PricingFlat, PricingTeamSet, PricingDynamic, PricingAuction:
+		return true
+	}
+	return false
+}
+
+// AuctionRule decides what the winner of a sealed-bid auction pays.
+type AuctionRule string
+
+const (
+	// AuctionFirstPrice charges the winner their own bid.
+	AuctionFirstPrice AuctionRule = "FIRST_PRICE"
+	// AuctionSecondPrice charges the winner the runner-up's bid, or the reserve price
+	// when nobody else bid.
+	AuctionSecondPrice AuctionRule = "SECOND_PRICE"
+)
+
+// IsValid reports whether r is a known auction rule.
+func (r AuctionRule) IsValid() bool {
+	return r == AuctionFirstPrice || r == AuctionSecondPrice
 }
 
 // Round represents a game session.
@@ -84,6 +222,168 @@ type Round struct {
 	EndedAt          *time.Time
 	CreatedAt        time.Time
 	IsPoppedActive   bool
+	QCRoutingMode    QCRoutingMode
+	// MaxUnratedBatches / MaxUnratedJokes cap how much work a team may have waiting
+	// for QC at once. Nil means no limit.
+	MaxUnratedBatches *int
+	MaxUnratedJokes   *int
+	LateJoinerPolicy  LateJoinerPolicy
+	// ChatEnabled allows team chat; the instructor's round channel is always open.
+	ChatEnabled bool
+	RoundPricing
+	ReturnPolicy
+}
+
+// ReturnPolicy limits how customers may return purchases in a round.
+// ReturnWindowSeconds (time since the purchase) and MaxReturns (per customer) are nil
+// for no limit; a return refunds RefundPercent of what was paid.
+type ReturnPolicy struct {
+	ReturnsAllowed      bool
+	ReturnWindowSeconds *int
+	MaxReturns          *int
+	RefundPercent       int
+}
+
+// CheckReturn reports whether a customer who has made returnsUsed returns may return a
+// purchase made age ago. It returns a conflict error naming the rule that stops them.
+func (p ReturnPolicy) CheckReturn(age time.Duration, returnsUsed int) error {
+	if !p.ReturnsAllowed {
+		return NewConflictError("returns are not allowed this round")
+	}
+	if p.ReturnWindowSeconds != nil && age > time.Duration(*p.ReturnWindowSeconds)*time.Second {
+		return NewConflictError(fmt.Sprintf("return window closed: returns must be made within %d seconds of buying", *p.ReturnWindowSeconds))
+	}
+	if p.MaxReturns != nil && returnsUsed >= *p.MaxReturns {
+		return NewConflictError(fmt.Sprintf("return limit reached: %d of %d returns used", returnsUsed, *p.MaxReturns))
+	}
+	return nil
+}
+
+// ReturnsLeft is how many more returns a customer who has made returnsUsed may make; nil
+// means no limit.
+func (p ReturnPolicy) ReturnsLeft(returnsUsed int) *int {
+	if !p.ReturnsAllowed {
+		left := 0
+		return &left
+	}
+	if p.MaxReturns == nil {
+		return nil
+	}
+	left := *p.MaxReturns - returnsUsed
+	if left < 0 {
+		left = 0
+	}
+	return &left
+}
+
+// Refund is what returning a purchase made at price pays back, in whole cents.
+func (p ReturnPolicy) Refund(price float64) float64 {
+	return math.Round(price*float64(p.RefundPercent)) / 100
+}
+
+// RoundPricing is how a round prices its published jokes. MinJokePrice/MaxJokePrice
+// bound team-set prices under PricingTeamSet and the curve under PricingDynamic, where
+// PriceStep is added per sale and PriceDecay taken off per idle minute. Under
+// PricingAuction each joke takes bids for AuctionWindowSeconds and AuctionRule sets
+// what the winner pays.
+type RoundPricing struct {
+	PricingMode          PricingMode
+	MinJokePrice         *float64
+	MaxJokePrice         *float64
+	PriceStep            float64
+	PriceDecay           float64
+	AuctionRule          AuctionRule
+	AuctionWindowSeconds int
+}
+
+// PriceState is where a joke's dynamic price was last set (when dynamic pricing started
+// or at its latest sale) and how long ago that was.
+type PriceState struct {
+	Price float64
+	Idle  time.Duration
+}
+
+// JokePrice is what a joke sells for in this round right now, given the team's asking
+// price (nil when the team hasn't set one) and the joke's dynamic price state. Under flat
+// pricing, or team pricing without an asking price, it is the market price; a team price
+// is kept within the round's bounds even if they have moved since it was set.
+func (r *Round) JokePrice(teamPrice *float64, state PriceState) float64 {
+	switch r.PricingMode {
+	case PricingTeamSet:
+		if teamPrice != nil {
+			return r.clampPrice(*teamPrice)
+		}
+	case PricingDynamic:
+		return r.DecayedPrice(state)
+	case PricingAuction:
+		return r.ReservePrice()
+	}
+	return r.MarketPrice
+}
+
+// ReservePrice is the lowest bid an auction accepts: the round's minimum price, or the
+// market price when there is none.
+func (r *Round) ReservePrice() float64 {
+	if r.MinJokePrice != nil {
+		return *r.MinJokePrice
+	}
+	return r.MarketPrice
+}
+
+// ClearingPrice is what the winner of an auction pays, given its bids highest first.
+func (r *Round) ClearingPrice(bids []float64) float64 {
+	if len(bids) == 0 {
+		return 0
+	}
+	if r.AuctionRule != AuctionSecondPrice {
+		return bids[0]
+	}
+	if len(bids) > 1 && bids[1] > r.ReservePrice() {
+		return bids[1]
+	}
+	return math.Min(r.ReservePrice(), bids[0])
+}
+
+// DecayedPrice is a joke's dynamic price after sitting unsold since state was recorded.
+func (r *Round) DecayedPrice(state PriceState) float64 {
+	return r.clampPrice(state.Price - r.PriceDecay*state.Idle.Minutes())
+}
+
+// RaisedPrice is where a joke's dynamic price moves after it sells for paid.
+func (r *Round) RaisedPrice(paid float64) float64 {
+	return r.clampPrice(paid + r.PriceStep)
+}
+
+// PriceFloor is the lowest price the round allows.
+func (r *Round) PriceFloor() float64 {
+	if r.MinJokePrice != nil {
+		return *r.MinJokePrice
+	}
+	return 0
+}
+
+// clampPrice keeps a price within the round's bounds, in whole cents.
+func (r *Round) clampPrice(price float64) float64 {
+	if price < r.PriceFloor() {
+		price = r.PriceFloor()
+	}
+	if r.MaxJokePrice != nil && price > *r.MaxJokePrice {
+		price = *r.MaxJokePrice
+	}
+	return math.Round(price*100) / 100
+}
+
+// CheckWIPLimit reports whether a team that already has queuedBatches batches holding
+// queuedJokes jokes waiting for QC may submit another batch of newJokes jokes.
+// It returns a conflict error naming the current queue size when a limit would be exceeded.
+func (r *Round) CheckWIPLimit(queuedBatches, queuedJokes, newJokes int) error {
+	if r.MaxUnratedBatches != nil && queuedBatches+1 > *r.MaxUnratedBatches {
+		return NewConflictError(fmt.Sprintf("WIP limit reached: team has %d unrated batches waiting for QC (limit %d)", queuedBatches, *r.MaxUnratedBatches))
+	}
+	if r.MaxUnratedJokes != nil && queuedJokes+newJokes > *r.MaxUnratedJokes {
+		return NewConflictError(fmt.Sprintf("WIP limit reached: team has %d unrated jokes waiting for QC, submitting %d more would exceed the limit of %d", queuedJokes, newJokes, *r.MaxUnratedJokes))
+	}
+	return nil
 }
 
 // TeamRoundState tracks per-team stats for a round.
@@ -169,11 +469,330 @@ type CustomerRoundBudget struct {
 	UpdatedAt       time.Time
 }
 
-// Purchase represents a purchase of a joke.
+// Purchase represents a purchase of a joke. Price is what the customer paid; Refund is
+// set once it has been returned, to what the round's return policy paid back.
 type Purchase struct {
 	ID             int64
 	RoundID        int64
 	CustomerUserID int64
 	JokeID         int64
+	Price          float64
+	Refund         *float64
+	CreatedAt      time.Time
+}
+
+// CustomerRating is a customer's 1–5 rating, and optional review, of a joke they hold an
+// active purchase for.
+type CustomerRating struct {
+	RoundID        int64
+	CustomerUserID int64
+	JokeID         int64
+	Rating         int
+	Review         *string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// JokePriceChange is one move of a joke's dynamic price: where it started when dynamic
+// pricing was switched on (SalePrice nil), or where a sale at SalePrice pushed it.
+type JokePriceChange struct {
+	ID        int64
+	RoundID   int64
+	JokeID    int64
+	SalePrice *float64
+	Price     float64
+	CreatedAt time.Time
+}
+
+// BidStatus tracks a sealed bid through its auction.
+type BidStatus string
+
+const (
+	BidOpen BidStatus = "OPEN"
+	BidWon  BidStatus = "WON"
+	BidLost BidStatus = "LOST"
+)
+
+// Bid is a customer's sealed bid on a joke. While open its Amount is held back from the
+// customer's budget; Price is what it paid once won. ClosesAt is when bidding on the joke
+// ends.
+type Bid struct {
+	ID             int64
+	RoundID        int64
+	JokeID         int64
+	CustomerUserID int64
+	Amount         float64
+	Status         BidStatus
+	Price          *float64
+	ClosesAt       time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	SettledAt      *time.Time
+}
+
+// RosterEntry is a pre-registered student. JoinCode is their personal code for joining;
+// UserID is set once they have joined with it.
+type RosterEntry struct {
+	ID            int64
+	DisplayName   string
+	StudentID     *string
+	PreferredTeam *int
+	PreferredRole *Role
+	JoinCode      string
+	UserID        *int64
+	ClaimedAt     *time.Time
+	CreatedAt     time.Time
+}
+
+// GameSettings holds game-wide switches set by the instructor.
+type GameSettings struct {
+	// RequireJoinCode rejects joins that don't present a roster join code.
+	RequireJoinCode bool
+	// MaxParticipants caps how many students can be in the game; later joiners are
+	// waitlisted. Nil means no cap.
+	MaxParticipants *int
+	UpdatedAt       time.Time
+}
+
+// AnnouncementSeverity is how prominently clients should show an announcement.
+type AnnouncementSeverity string
+
+const (
+	SeverityInfo     AnnouncementSeverity = "INFO"
+	SeverityWarning  AnnouncementSeverity = "WARNING"
+	SeverityCritical AnnouncementSeverity = "CRITICAL"
+)
+
+// IsValid reports whether s is a known severity.
+func (s AnnouncementSeverity) IsValid() bool {
+	switch s {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return true
+	}
+	return false
+}
+
+// AnnouncementTarget is who an announcement is addressed to.
+type AnnouncementTarget string
+
+const (
+	TargetAll  AnnouncementTarget = "ALL"
+	TargetRole AnnouncementTarget = "ROLE"
+	TargetTeam AnnouncementTarget = "TEAM"
+	TargetUser AnnouncementTarget = "USER"
+)
+
+// IsValid reports whether t is a known target.
+func (t AnnouncementTarget) IsValid() bool {
+	switch t {
+	case TargetAll, TargetRole, TargetTeam, TargetUser:
+		return true
+	}
+	return false
+}
+
+// Announcement is an instructor message to participants. Only the target field matching
+// Target is set.
+type Announcement struct {
+	ID           int64
+	Title        string
+	Body         string
+	Severity     AnnouncementSeverity
+	Target       AnnouncementTarget
+	TargetRole   *Role
+	TargetTeamID *int64
+	TargetUserID *int64
+	ExpiresAt    *time.Time
+	CreatedBy    *int64
+	CreatedAt    time.Time
+}
+
+// Expired reports whether the announcement has expired as of now.
+func (a *Announcement) Expired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
+}
+
+// AppliesTo reports whether u, as currently assigned, is a recipient. Instructors and
+// removed users never are.
+func (a *Announcement) AppliesTo(u *User) bool {
+	if u.RemovedAt != nil || (u.Role != nil && *u.Role == RoleInstructor) {
+		return false
+	}
+	switch a.Target {
+	case TargetAll:
+		return true
+	case TargetRole:
+		return a.TargetRole != nil && u.Role != nil && *u.Role == *a.TargetRole
+	case TargetTeam:
+		return a.TargetTeamID != nil && u.TeamID != nil && *u.TeamID == *a.TargetTeamID
+	case TargetUser:
+		return a.TargetUserID != nil && u.ID == *a.TargetUserID
+	}
+	return false
+}
+
+// ScriptAction is the instructor operation a script step performs.
+type ScriptAction string
+
+const (
+	ScriptStartRound     ScriptAction = "START_ROUND"
+	ScriptEndRound       ScriptAction = "END_ROUND"
+	ScriptSetPopup       ScriptAction = "SET_POPUP"
+	ScriptAnnounce       ScriptAction = "ANNOUNCE"
+	ScriptSetMarketPrice ScriptAction = "SET_MARKET_PRICE"
+)
+
+// ScriptStep is one timed step of a game script. Which fields are used depends on Action:
+// START_ROUND takes RoundID and optionally the round config (anything omitted keeps the
+// round's stored value), END_ROUND takes RoundID, SET_POPUP RoundID and PopupActive,
+// SET_MARKET_PRICE RoundID and MarketPrice, and ANNOUNCE takes Announcement.
+type ScriptStep struct {
+	// AtSeconds is when the step runs, counted from when the script was played (pauses
+	// excluded).
+	AtSeconds        int                 `json:"at_seconds"`
+	Action           ScriptAction        `json:"action"`
+	Label            string              `json:"label,omitempty"`
+	RoundID          *int64              `json:"round_id,omitempty"`
+	PopupActive      *bool               `json:"popup_active,omitempty"`
+	CustomerBudget   *int                `json:"customer_budget,omitempty"`
+	BatchSize        *int                `json:"batch_size,omitempty"`
+	MarketPrice      *float64            `json:"market_price,omitempty"`
+	CostOfPublishing *float64            `json:"cost_of_publishing,omitempty"`
+	Announcement     *ScriptAnnouncement `json:"announcement,omitempty"`
+}
+
+// At returns the step's offset from the start of the script.
+func (s ScriptStep) At() time.Duration {
+	return time.Duration(s.AtSeconds) * time.Second
+}
+
+// ScriptAnnouncement is the announcement an ANNOUNCE step posts. ExpiresInSeconds, if
+// set, is counted from when the step runs.
+type ScriptAnnouncement struct {
+	Title            string               `json:"title"`
+	Body             string               `json:"body,omitempty"`
+	Severity         AnnouncementSeverity `json:"severity,omitempty"`
+	Target           AnnouncementTarget   `json:"target,omitempty"`
+	TargetRole       *Role                `json:"target_role,omitempty"`
+	TargetTeamID     *int64               `json:"target_team_id,omitempty"`
+	TargetUserID     *int64               `json:"target_user_id,omitempty"`
+	ExpiresInSeconds *int                 `json:"expires_in_seconds,omitempty"`
+}
+
+// GameScript is a stored list of timed steps, ordered by AtSeconds.
+type GameScript struct {
+	ID        int64
+	Name      string
+	Steps     []ScriptStep
+	CreatedBy *int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ScriptRunStatus is the lifecycle of a script run.
+type ScriptRunStatus string
+
+const (
+	ScriptRunning  ScriptRunStatus = "RUNNING"
+	ScriptPaused   ScriptRunStatus = "PAUSED"
+	ScriptFinished ScriptRunStatus = "FINISHED"
+	ScriptStopped  ScriptRunStatus = "STOPPED"
+)
+
+// ScriptRun is one play-through of a script, with its own copy of the steps.
+type ScriptRun struct {
+	ID         int64
+	ScriptID   *int64
+	ScriptName string
+	Steps      []ScriptStep
+	Status     ScriptRunStatus
+	// NextStep is the index of the first step not yet run or skipped.
+	NextStep int
+	// Elapsed is the script time accumulated up to ResumedAt (or up to the pause).
+	Elapsed   time.Duration
+	ResumedAt *time.Time
+	StartedBy *int64
+	StartedAt time.Time
+	EndedAt   *time.Time
+}
+
+// ElapsedAt returns how far into the script the run is as of now.
+func (r *ScriptRun) ElapsedAt(now time.Time) time.Duration {
+	if r.Status == ScriptRunning && r.ResumedAt != nil {
+		return r.Elapsed + now.Sub(*r.ResumedAt)
+	}
+	return r.Elapsed
+}
+
+// ScriptStepOutcome is what happened to a script step.
+type ScriptStepOutcome string
+
+const (
+	ScriptStepDone    ScriptStepOutcome = "DONE"
+	ScriptStepFailed  ScriptStepOutcome = "FAILED"
+	ScriptStepSkipped ScriptStepOutcome = "SKIPPED"
+)
+
+// ScriptStepLog records one step of a run being executed or skipped.
+type ScriptStepLog struct {
+	ID         int64
+	RunID      int64
+	StepIndex  int
+	Action     ScriptAction
+	Outcome    ScriptStepOutcome
+	Error      string
+	ExecutedAt time.Time
+}
+
+// ChatMessage is a message in a round's chat. TeamID nil is the round channel, where the
+// instructor posts to everyone; otherwise it is that team's channel.
+type ChatMessage struct {
+	ID         int64
+	RoundID    int64
+	TeamID     *int64
+	SenderID   *int64
+	SenderName string
+	SenderRole *Role
+	Body       string
+	CreatedAt  time.Time
+}
+
+// ChatReadCursor is how far a user has read in a channel: every message up to
+// LastReadMessageID.
+type ChatReadCursor struct {
+	RoundID           int64
+	TeamID            *int64
+	UserID            int64
+	DisplayName       string
+	LastReadMessageID int64
+	ReadAt            time.Time
+}
+
+// AuditEvent records one instructor mutation: who did what to which target, with the
+// target's state before and after as JSON (nil when it didn't exist on that side).
+type AuditEvent struct {
+	ID               int64
+	ActorUserID      *int64
+	ActorDisplayName string
+	Action           string
+	TargetType       string
+	TargetID         *int64
+	RoundID          *int64
+	Before           []byte
+	After            []byte
+	RequestID        string
+	CreatedAt        time.Time
+}
+
+// PurchaseEvent is an audit record of a buy (+1) or return (-1) of a published joke.
+// Price is what the buy charged or the return refunded.
+type PurchaseEvent struct {
+	ID             int64
+	RoundID        int64
+	CustomerUserID int64
+	JokeID         int64
+	TeamID         int64
+	Delta          int
+	Price          float64
 	CreatedAt      time.Time
 }
@@ -40,6 +40,88 @@ type MarketItem struct {
 	TeamSold     int
 	BoughtCount  int
 	IsBoughtByMe bool
+	// CustomerRatingAvg is nil until some customer has rated the joke; MyRating is the
+	// caller's own rating, if any.
+	CustomerRatingAvg   *float64
+	CustomerRatingCount int
+	MyRating            *int
+	// TeamPrice is the team's asking price (nil if unset) and PriceState where its dynamic
+	// price stands; Price is what the joke sells for now, worked out from the round's
+	// pricing by the caller.
+	TeamPrice  *float64
+	PriceState domain.PriceState
+	Price      float64
+	// AuctionClosesAt is set when the round sells by auction; MyBid is the caller's open
+	// bid, if any.
+	AuctionClosesAt *time.Time
+	MyBid           *float64
+}
+
+// AuctionResult is how a joke's auction closed. WinnerUserID is nil when every bidder
+// already held the joke.
+type AuctionResult struct {
+	RoundID      int64
+	JokeID       int64
+	TeamID       int64
+	WinnerUserID *int64
+	Price        float64
+	Bids         int
+}
+
+// JokePricing is what pricing a joke needs to know about it.
+type JokePricing struct {
+	JokeID      int64
+	RoundID     int64
+	TeamID      int64
+	TeamPrice   *float64
+	PublishedAt *time.Time
+}
+
+// MarketSort orders the market listing.
+type MarketSort string
+
+const (
+	MarketSortOldest     MarketSort = "oldest"
+	MarketSortNewest     MarketSort = "newest"
+	MarketSortMostBought MarketSort = "most_bought"
+	MarketSortTeamProfit MarketSort = "team_profit"
+)
+
+// IsValid reports whether s is a known sort.
+func (s MarketSort) IsValid() bool {
+	switch s {
+	case MarketSortOldest, MarketSortNewest, MarketSortMostBought, MarketSortTeamProfit:
+		return true
+	}
+	return false
+}
+
+// MarketFilter narrows and pages the market. Search is full-text over joke text and title;
+// Label is a full performance label such as "HIGH PERFORMING"; Bought filters on whether
+// the caller holds the joke. Empty or nil fields don't filter.
+type MarketFilter struct {
+	Search string
+	TeamID *int64
+	Label  string
+	Bought *bool
+	Sort   MarketSort
+	Limit  int
+	Offset int
+}
+
+// PurchaseHistoryEntry is one buy (Delta 1) or return (Delta -1) in a customer's history.
+// Price is what was charged or refunded; Balance is the remaining budget right after it.
+type PurchaseHistoryEntry struct {
+	EventID   int64
+	JokeID    int64
+	JokeText  string
+	JokeTitle *string
+	TeamID    int64
+	TeamName  string
+	Delta     int
+	Price     float64
+	Balance   float64
+	CreatedAt time.Time
 }
 
 // TeamMember is a user assigned to a team with a role.
@@ -47,6 +129,8 @@ type TeamMember struct {
 	UserID      int64
 	DisplayName string
 	Role        domain.Role
+	LastSeenAt  time.Time
+	Presence    domain.Presence
 }
 
 // LobbySnapshot captures lobby state for instructor.
@@ -56,13 +140,19 @@ type LobbySnapshot struct {
 	Teams      []LobbyTeam
 	Customers  []LobbyCustomer
 	Unassigned []LobbyUnassigned
+	// Waitlist holds participants who joined once the game was full, first in line first.
+	Waitlist []LobbyUnassigned
+	Removed  []LobbyRemoved
 }
 
 // LobbySummary aggregates counts for lobby view.
 type LobbySummary struct {
 	Waiting       int
 	Assigned      int
+	Waitlisted    int
+	Idle          int
 	Dropped       int
+	Removed       int
 	TeamCount     int
 	CustomerCount int
 }
@@ -78,6 +168,8 @@ type LobbyCustomer struct {
 	UserID      int64
 	DisplayName string
 	Role        domain.Role
+	LastSeenAt  time.Time
+	Presence    domain.Presence
 }
 
 // LobbyUnassigned represents a waiting participant.
@@ -85,6 +177,20 @@ type LobbyUnassigned struct {
 	UserID      int64
 	DisplayName string
 	Status      domain.ParticipantStatus
+	LastSeenAt  time.Time
+	Presence    domain.Presence
+}
+
+// LobbyRemoved represents a soft-deleted participant that can be restored.
+type LobbyRemoved struct {
+	UserID      int64
+	DisplayName string
+	Role        *domain.Role
+	TeamID      *int64
+	RemovedAt   time.Time
+	RemovedBy   *int64
+	// MergedInto is set for duplicates merged into another user; those can't be restored.
+	MergedInto *int64
 }
 
 // TeamSummary aggregates stats for a team in a round.
@@ -103,6 +209,12 @@ type TeamSummary struct {
 	SoldJokesCount  int
 	AvgScoreOverall float64
 	UnratedBatches  int
+	UnratedJokes    int
+	// WIP limits configured on the round (nil = unlimited), filled in by RoundService.
+	MaxUnratedBatches *int
+	MaxUnratedJokes   *int
+	// CanSubmit is true while the round is active and the team is under its WIP limits.
+	CanSubmit bool
 }
 
 // TeamStats is used for instructor round stats.
@@ -129,7 +241,8 @@ type SalesPoint struct {
 	CumulativePoints int       `json:"cumulative_points"`
 }
 
-// UnratedJokesPoint represents queue size over time per team.
+// UnratedJokesPoint represents QC queue size over time. TeamID is the team whose QC
+// owns the queue under the round's routing mode (0 for the global pool).
 type UnratedJokesPoint struct {
 	EventIndex   int       `json:"event_index"`
 	TeamEventIndex int     `json:"team_event_index"`
@@ -167,15 +280,393 @@ type TeamRejectionPoint struct {
 	RejectionRate   float64 `json:"rejection_rate"`
 }
 
+// RatingComparisonPoint sets a joke's QC rating against what the customers who bought it
+// thought, for judging whether QC predicts customer taste.
+type RatingComparisonPoint struct {
+	JokeID          int64   `json:"joke_id"`
+	TeamID          int64   `json:"team_id"`
+	TeamName        string  `json:"team_name"`
+	QCRating        int     `json:"qc_rating"`
+	CustomerAvg     float64 `json:"customer_avg"`
+	CustomerRatings int     `json:"customer_ratings"`
+}
+
+// TeamMessageCount is how much a team used its chat in a round.
+type TeamMessageCount struct {
+	TeamID     int64  `json:"team_id"`
+	TeamName   string `json:"team_name"`
+	Messages   int    `json:"messages"`
+	JMMessages int    `json:"jm_messages"`
+	QCMessages int    `json:"qc_messages"`
+}
+
+// BatchFlow holds the lifecycle of one submitted batch for flow metrics.
+// Durations are in seconds and nil until the batch reaches the relevant state
+// (or when it was rated without being locked first, for wait/rating time).
+type BatchFlow struct {
+	BatchID         int64      `json:"batch_id"`
+	TeamID          int64      `json:"team_id"`
+	TeamName        string     `json:"team_name"`
+	JokesCount      int        `json:"jokes_count"`
+	SubmittedAt     time.Time  `json:"submitted_at"`
+	LockedAt        *time.Time `json:"locked_at"`
+	RatedAt         *time.Time `json:"rated_at"`
+	WaitSeconds     *float64   `json:"wait_seconds"`
+	RatingSeconds   *float64   `json:"rating_seconds"`
+	LeadTimeSeconds *float64   `json:"lead_time_seconds"`
+}
+
+// DurationStats summarises a distribution of durations in seconds.
+type DurationStats struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	Min   float64 `json:"min"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	Max   float64 `json:"max"`
+}
+
+// TeamFlowMetrics aggregates lean flow metrics for one team over the round window.
+type TeamFlowMetrics struct {
+	TeamID                     int64         `json:"team_id"`
+	TeamName                   string        `json:"team_name"`
+	BatchesSubmitted           int           `json:"batches_submitted"`
+	BatchesRated               int           `json:"batches_rated"`
+	JokesRated                 int           `json:"jokes_rated"`
+	WaitTime                   DurationStats `json:"wait_time"`
+	RatingTime                 DurationStats `json:"rating_time"`
+	LeadTime                   DurationStats `json:"lead_time"`
+	ThroughputJokesPerMinute   float64       `json:"throughput_jokes_per_minute"`
+	ThroughputBatchesPerMinute float64       `json:"throughput_batches_per_minute"`
+	AvgWIPJokes                float64       `json:"avg_wip_jokes"`
+	AvgWIPBatches              float64       `json:"avg_wip_batches"`
+}
+
+// FlowPoint is one time bucket of a team's flow: WIP at the end of the bucket and
+// the work completed (rated) during it.
+type FlowPoint struct {
+	BucketStart         time.Time `json:"bucket_start"`
+	TeamID              int64     `json:"team_id"`
+	TeamName            string    `json:"team_name"`
+	WIPJokes            int       `json:"wip_jokes"`
+	WIPBatches          int       `json:"wip_batches"`
+	RatedJokes          int       `json:"rated_jokes"`
+	ThroughputPerMinute float64   `json:"throughput_per_minute"`
+}
+
+// FlowMetrics bundles per-batch distributions, per-team aggregates and time series.
+type FlowMetrics struct {
+	RoundID       int64             `json:"round_id"`
+	WindowStart   time.Time         `json:"window_start"`
+	WindowEnd     time.Time         `json:"window_end"`
+	BucketSeconds int               `json:"bucket_seconds"`
+	Teams         []TeamFlowMetrics `json:"teams"`
+	Batches       []BatchFlow       `json:"batches"`
+	TimeSeries    []FlowPoint       `json:"time_series"`
+}
+
+// JokeLifecycle holds the timestamps needed to tell which flow state a joke was in at
+// any moment of a round (used to rebuild the cumulative flow diagram and replays).
+type JokeLifecycle struct {
+	JokeID         int64
+	BatchID        int64
+	TeamID         int64
+	TeamName       string
+	JokeText       string
+	JokeTitle      *string
+	BatchCreatedAt time.Time
+	SubmittedAt    *time.Time
+	LockedAt       *time.Time
+	RatedAt        *time.Time
+	BatchAvgScore  *float64
+	Rating         *int
+	IsPublished    bool
+	PublishedAt    *time.Time
+}
+
+// CFDPoint is the number of a team's jokes in each flow state at one instant.
+type CFDPoint struct {
+	Timestamp       time.Time `json:"timestamp"`
+	TeamID          int64     `json:"team_id"`
+	TeamName        string    `json:"team_name"`
+	Submitted       int       `json:"submitted"`
+	InReview        int       `json:"in_review"`
+	RatedRejected   int       `json:"rated_rejected"`
+	PublishedUnsold int       `json:"published_unsold"`
+	Sold            int       `json:"sold"`
+}
+
+// CumulativeFlow is cumulative flow diagram data sampled at a fixed interval.
+type CumulativeFlow struct {
+	RoundID         int64      `json:"round_id"`
+	WindowStart     time.Time  `json:"window_start"`
+	WindowEnd       time.Time  `json:"window_end"`
+	IntervalSeconds int        `json:"interval_seconds"`
+	Points          []CFDPoint `json:"points"`
+}
+
+// PriceEvent says what happened at a point on a joke's price chart: LISTED (published),
+// STARTED (dynamic pricing switched on), SOLD (the price a sale paid), RAISED (where that
+// sale pushed the price), FLOORED (decay reached the round's minimum) or CURRENT (the
+// price now, or when the round ended).
+type PriceEvent string
+
+const (
+	PriceListed  PriceEvent = "LISTED"
+	PriceStarted PriceEvent = "STARTED"
+	PriceSold    PriceEvent = "SOLD"
+	PriceRaised  PriceEvent = "RAISED"
+	PriceFloored PriceEvent = "FLOORED"
+	PriceCurrent PriceEvent = "CURRENT"
+)
+
+// PricePoint is one corner of a joke's price chart; the price moves in a straight line
+// between consecutive points.
+type PricePoint struct {
+	Timestamp time.Time  `json:"timestamp"`
+	Price     float64    `json:"price"`
+	Event     PriceEvent `json:"event"`
+}
+
+// PriceHistory is a joke's price over the round as a chart series.
+type PriceHistory struct {
+	RoundID      int64              `json:"round_id"`
+	JokeID       int64              `json:"joke_id"`
+	TeamID       int64              `json:"team_id"`
+	PricingMode  domain.PricingMode `json:"pricing_mode"`
+	CurrentPrice float64            `json:"current_price"`
+	Points       []PricePoint       `json:"points"`
+}
+
+// RoundMetrics are the headline numbers compared across rounds in the debrief.
+type RoundMetrics struct {
+	Profit             float64 `json:"profit"`
+	Sales              int     `json:"sales"`
+	AcceptanceRate     float64 `json:"acceptance_rate"`
+	AvgScore           float64 `json:"avg_score"`
+	AvgLeadTimeSeconds float64 `json:"avg_lead_time_seconds"`
+	JokesProduced      int     `json:"jokes_produced"`
+}
+
+// RoundMetricsDelta is To minus From for every metric.
+type RoundMetricsDelta struct {
+	FromRoundID int64 `json:"from_round_id"`
+	ToRoundID   int64 `json:"to_round_id"`
+	RoundMetrics
+}
+
+// TeamRoundResult is one team's metrics in one round.
+type TeamRoundResult struct {
+	RoundID     int64 `json:"round_id"`
+	RoundNumber int   `json:"round_number"`
+	RoundMetrics
+}
+
+// TeamRoundComparison lists a team's results per round and the change between rounds.
+type TeamRoundComparison struct {
+	TeamID   int64               `json:"team_id"`
+	TeamName string              `json:"team_name"`
+	Rounds   []TeamRoundResult   `json:"rounds"`
+	Deltas   []RoundMetricsDelta `json:"deltas"`
+}
+
+// ClassRoundAggregate is the class-wide total/average for one round.
+type ClassRoundAggregate struct {
+	RoundID     int64 `json:"round_id"`
+	RoundNumber int   `json:"round_number"`
+	TeamCount   int   `json:"team_count"`
+	RoundMetrics
+}
+
+// RoundComparison compares teams and the whole class across several rounds.
+type RoundComparison struct {
+	RoundIDs    []int64               `json:"round_ids"`
+	Teams       []TeamRoundComparison `json:"teams"`
+	Class       []ClassRoundAggregate `json:"class"`
+	ClassDeltas []RoundMetricsDelta   `json:"class_deltas"`
+}
+
+// ReplayMarketItem is a published joke as the market showed it at a past instant.
+type ReplayMarketItem struct {
+	JokeID      int64     `json:"joke_id"`
+	JokeTitle   *string   `json:"joke_title"`
+	JokeText    string    `json:"joke_text"`
+	TeamID      int64     `json:"team_id"`
+	TeamName    string    `json:"team_name"`
+	PublishedAt time.Time `json:"published_at"`
+	BoughtCount int       `json:"bought_count"`
+}
+
+// ReplayQueue is a team's submitted-but-unrated work at a past instant.
+type ReplayQueue struct {
+	TeamID          int64   `json:"team_id"`
+	TeamName        string  `json:"team_name"`
+	WaitingBatches  int     `json:"waiting_batches"`
+	InReviewBatches int     `json:"in_review_batches"`
+	QueuedJokes     int     `json:"queued_jokes"`
+	BatchIDs        []int64 `json:"batch_ids"`
+}
+
+// ReplayState reconstructs the market, leaderboard and per-team QC queues of a round
+// as they were at one instant.
+type ReplayState struct {
+	RoundID     int64              `json:"round_id"`
+	At          time.Time          `json:"at"`
+	Leaderboard []TeamStats        `json:"leaderboard"`
+	Market      []ReplayMarketItem `json:"market"`
+	Queues      []ReplayQueue      `json:"queues"`
+}
+
+// ReplayEventType identifies one step of a round replay.
+type ReplayEventType string
+
+const (
+	ReplayBatchSubmitted ReplayEventType = "BATCH_SUBMITTED"
+	ReplayBatchLocked    ReplayEventType = "BATCH_LOCKED"
+	ReplayBatchRated     ReplayEventType = "BATCH_RATED"
+	ReplayJokePublished  ReplayEventType = "JOKE_PUBLISHED"
+	ReplayPurchase       ReplayEventType = "PURCHASE"
+	ReplayReturn         ReplayEventType = "RETURN"
+)
+
+// ReplayEvent is one step of a round replay. Seq is the event's position in the
+// round's full, time-ordered history.
+type ReplayEvent struct {
+	Seq            int             `json:"seq"`
+	Type           ReplayEventType `json:"type"`
+	Timestamp      time.Time       `json:"timestamp"`
+	TeamID         int64           `json:"team_id"`
+	TeamName       string          `json:"team_name"`
+	BatchID        *int64          `json:"batch_id,omitempty"`
+	JokeID         *int64          `json:"joke_id,omitempty"`
+	CustomerUserID *int64          `json:"customer_user_id,omitempty"`
+	JokesCount     *int            `json:"jokes_count,omitempty"`
+	AvgScore       *float64        `json:"avg_score,omitempty"`
+}
+
+// ReplayFeed is one page of a round's replay events.
+type ReplayFeed struct {
+	RoundID int64         `json:"round_id"`
+	Events  []ReplayEvent `json:"events"`
+	NextSeq int           `json:"next_seq"`
+	HasMore bool          `json:"has_more"`
+}
+
 // RoundStats aggregates leaderboard plus chart data for instructor dashboard.
 type RoundStats struct {
 	RoundID              int64                   `json:"round_id"`
+	QCRoutingMode        domain.QCRoutingMode    `json:"qc_routing_mode"`
 	Leaderboard          []TeamStats             `json:"leaderboard"`
 	RejectionByTeam      []TeamRejectionPoint    `json:"rejection_by_team"`
 	SalesOverTime        []SalesPoint            `json:"sales_over_time"`
 	UnratedJokesOverTime []UnratedJokesPoint     `json:"unrated_jokes_over_time"`
 	BatchSequenceQuality []BatchSequencePoint    `json:"batch_sequence_quality"`
 	BatchSizeQuality     []BatchSizeQualityPoint `json:"batch_size_quality"`
+	MessagesByTeam       []TeamMessageCount      `json:"messages_by_team"`
+	CustomerVsQCRatings  []RatingComparisonPoint `json:"customer_vs_qc_ratings"`
+}
+
+// ExportEntity names a table (or derived report) available for raw data export.
+type ExportEntity string
+
+const (
+	ExportUsers                 ExportEntity = "users"
+	ExportTeams                 ExportEntity = "teams"
+	ExportBatches               ExportEntity = "batches"
+	ExportJokes                 ExportEntity = "jokes"
+	ExportPublishedJokes        ExportEntity = "published_jokes"
+	ExportPurchases             ExportEntity = "purchases"
+	ExportPurchaseEvents        ExportEntity = "purchase_events"
+	ExportBatchSubmissionEvents ExportEntity = "batch_submission_events"
+	ExportLeaderboard           ExportEntity = "leaderboard"
+)
+
+// ExportEntities lists every exportable entity in workbook sheet order.
+var ExportEntities = []ExportEntity{
+	ExportUsers, ExportTeams, ExportBatches, ExportJokes, ExportPublishedJokes,
+	ExportPurchases, ExportPurchaseEvents, ExportBatchSubmissionEvents, ExportLeaderboard,
+}
+
+// ExportFilter narrows an export. Nil fields mean "all"; filters that don't apply to
+// an entity (e.g. round for users) are ignored.
+type ExportFilter struct {
+	RoundID *int64
+	TeamID  *int64
+}
+
+// ExportRowFunc receives an export's header row first, then each data row in order.
+// Returning an error stops the export.
+type ExportRowFunc func(record []string) error
+
+// LobbyUserState is everything needed to put a user back the way they were in a round's
+// lobby. Exists is false when the user had been deleted (or not created yet).
+type LobbyUserState struct {
+	UserID      int64                    `json:"user_id"`
+	Exists      bool                     `json:"exists"`
+	DisplayName string                   `json:"display_name"`
+	Role        *domain.Role             `json:"role"`
+	TeamID      *int64                   `json:"team_id"`
+	Status      domain.ParticipantStatus `json:"status"`
+	AssignedAt  *time.Time               `json:"assigned_at"`
+	JoinedAt    time.Time                `json:"joined_at"`
+	CreatedAt   time.Time                `json:"created_at"`
+	// Budget is the user's customer_round_budget row for the round, if any.
+	Budget *LobbyBudgetState `json:"budget"`
+}
+
+// LobbyBudgetState is a captured customer budget row.
+type LobbyBudgetState struct {
+	StartingBudget  float64 `json:"starting_budget"`
+	RemainingBudget float64 `json:"remaining_budget"`
+}
+
+// LobbyUndoEntry is one undoable lobby operation: the state of every user it touched
+// before and after it ran.
+type LobbyUndoEntry struct {
+	ID          int64            `json:"undo_id"`
+	RoundID     int64            `json:"round_id"`
+	Action      string           `json:"action"`
+	UsersBefore []LobbyUserState `json:"users_before"`
+	UsersAfter  []LobbyUserState `json:"users_after"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UndoneAt    *time.Time       `json:"undone_at"`
+}
+
+// AuditFilter narrows an audit log query. Nil/empty fields match everything.
+type AuditFilter struct {
+	ActorUserID *int64
+	Action      string
+	TargetType  string
+	TargetID    *int64
+	RoundID     *int64
+	Since       *time.Time
+	Until       *time.Time
+	Limit       int
+	Offset      int
+}
+
+// AnnouncementSummary is an announcement with how many of its current recipients have
+// acknowledged it.
+type AnnouncementSummary struct {
+	domain.Announcement
+	Recipients   int
+	Acknowledged int
+}
+
+// UserAnnouncement is an announcement as seen by one recipient.
+type UserAnnouncement struct {
+	domain.Announcement
+	AckedAt *time.Time
+}
+
+// AnnouncementRecipient is one recipient's read receipt; AckedAt is nil until they
+// acknowledge.
+type AnnouncementRecipient struct {
+	UserID      int64
+	DisplayName string
+	Role        *domain.Role
+	TeamID      *int64
+	AckedAt     *time.Time
 }
 
 // GameRepository is a composite repository covering all domain operations.
@@ -185,7 +676,12 @@ type GameRepository interface {
 
 	// Users & participants
 	CreateUser(ctx context.Context, displayName string) (*domain.User, error)
-	GetUserByDisplayName(ctx context.Context, displayName string) (*domain.User, error)
+	// CreateParticipant creates a student user. If the display name is already taken
+	// (case-insensitively) it is suffixed with the lowest free " (n)".
+	CreateParticipant(ctx context.Context, displayName, rejoinSecretHash string) (*domain.User, error)
+	// GetUserByRejoinSecret resolves a rejoin secret hash to its user, following merges.
+	GetUserByRejoinSecret(ctx context.Context, rejoinSecretHash string) (*domain.User, error)
+	GetInstructorByDisplayName(ctx context.Context, displayName string) (*domain.User, error)
 	GetUserByID(ctx context.Context, userID int64) (*domain.User, error)
 	UpdateUserAssignment(ctx context.Context, userID int64, role *domain.Role, teamID *int64) error
 	UpdateUserStatus(ctx context.Context, userID int64, status domain.ParticipantStatus) error
@@ -198,7 +694,90 @@ type GameRepository interface {
 	ListUsersByStatus(ctx context.Context, status domain.ParticipantStatus) ([]domain.User, error)
 	ListTeamMembers(ctx context.Context, teamID int64) ([]TeamMember, error)
 	ListCustomers(ctx context.Context) ([]LobbyCustomer, error)
-	DeleteUser(ctx context.Context, userID int64) error
+	// DeleteUser soft-deletes a non-instructor user, keeping their history for stats.
+	DeleteUser(ctx context.Context, userID, removedBy int64) error
+	// RestoreUser clears a soft delete and returns the restored user.
+	RestoreUser(ctx context.Context, userID int64) (*domain.User, error)
+	ListRemovedUsers(ctx context.Context) ([]LobbyRemoved, error)
+	// TouchUser records a heartbeat and returns the new last-seen time.
+	TouchUser(ctx context.Context, userID int64) (time.Time, error)
+	// ReplaceTeamMember hands a JM/QC's role and team to a waiting participant and sends
+	// the original holder back to waiting, releasing any batch they had locked for QC.
+	ReplaceTeamMember(ctx context.Context, userID, replacementID int64) error
+	// MergeUsers moves the source user's ratings, purchases, budgets and locks to the
+	// target, then soft-deletes the source and marks it merged into the target.
+	MergeUsers(ctx context.Context, sourceID, targetID, mergedBy int64) (*domain.User, error)
+
+	// Roster & settings
+	// ImportRoster inserts roster entries, updating existing ones matched by student id
+	// (which keep their join code), and returns them as stored.
+	ImportRoster(ctx context.Context, entries []domain.RosterEntry) ([]domain.RosterEntry, error)
+	ListRoster(ctx context.Context) ([]domain.RosterEntry, error)
+	// ClaimJoinCode joins with a roster code: the first use creates the student's user,
+	// later uses reclaim it. The user's rejoin secret is replaced with the given hash.
+	ClaimJoinCode(ctx context.Context, joinCode, rejoinSecretHash string) (user *domain.User, reclaimed bool, err error)
+	GetGameSettings(ctx context.Context) (*domain.GameSettings, error)
+	UpdateGameSettings(ctx context.Context, settings *domain.GameSettings) error
+
+	// Admission
+	// PromoteWaitlisted moves waitlisted participants, oldest first, back to WAITING while
+	// the participant cap allows, and returns their ids.
+	PromoteWaitlisted(ctx context.Context) ([]int64, error)
+	// ListLateJoiners returns waiting, unassigned participants who joined after the round's
+	// teams were first assigned (none if they haven't been) and have not been seated as
+	// late joiners before, oldest first.
+	ListLateJoiners(ctx context.Context, roundID int64) ([]domain.User, error)
+	// ClaimLateJoiner marks a late joiner as seated and reports whether this call did so;
+	// false means someone else already seated them, or they are no longer waiting.
+	ClaimLateJoiner(ctx context.Context, userID int64) (bool, error)
+
+	// Announcements
+	CreateAnnouncement(ctx context.Context, a *domain.Announcement) error
+	GetAnnouncement(ctx context.Context, announcementID int64) (*domain.Announcement, error)
+	// ListAnnouncements returns every announcement newest first, with read counts.
+	ListAnnouncements(ctx context.Context) ([]AnnouncementSummary, error)
+	// ListAnnouncementsForUser returns the unexpired announcements the user currently
+	// receives, newest first, with when they acknowledged each.
+	ListAnnouncementsForUser(ctx context.Context, userID int64) ([]UserAnnouncement, error)
+	// AckAnnouncement records a read receipt and returns its time; acknowledging again
+	// keeps the first one.
+	AckAnnouncement(ctx context.Context, announcementID, userID int64) (time.Time, error)
+	// ListAnnouncementRecipients returns everyone the announcement currently reaches and
+	// whether they have acknowledged it.
+	ListAnnouncementRecipients(ctx context.Context, announcementID int64) ([]AnnouncementRecipient, error)
+
+	// Game scripts
+	CreateScript(ctx context.Context, script *domain.GameScript) error
+	// UpdateScript replaces a script's name and steps.
+	UpdateScript(ctx context.Context, script *domain.GameScript) error
+	GetScript(ctx context.Context, scriptID int64) (*domain.GameScript, error)
+	ListScripts(ctx context.Context) ([]domain.GameScript, error)
+	DeleteScript(ctx context.Context, scriptID int64) error
+	// StartScriptRun inserts a RUNNING run; it fails with a conflict while another run is
+	// in progress.
+	StartScriptRun(ctx context.Context, run *domain.ScriptRun) error
+	GetScriptRun(ctx context.Context, runID int64) (*domain.ScriptRun, error)
+	// GetCurrentScriptRun returns the RUNNING or PAUSED run, or a not-found error.
+	GetCurrentScriptRun(ctx context.Context) (*domain.ScriptRun, error)
+	// SetScriptRunStatus moves a run between statuses, banking elapsed time when it stops
+	// running. It fails with a conflict if the run isn't in one of the from statuses.
+	SetScriptRunStatus(ctx context.Context, runID int64, to domain.ScriptRunStatus, from ...domain.ScriptRunStatus) (*domain.ScriptRun, error)
+	// AdvanceScriptRun moves a run in progress past step index step. It reports false if
+	// the run is no longer at that step (another caller got there first) or has ended.
+	AdvanceScriptRun(ctx context.Context, runID int64, step int, requireRunning bool) (bool, error)
+	InsertScriptStepLog(ctx context.Context, entry *domain.ScriptStepLog) error
+	ListScriptStepLog(ctx context.Context, runID int64) ([]domain.ScriptStepLog, error)
+
+	// Chat
+	// CreateChatMessage stores a message and fills in its id, time and sender name.
+	CreateChatMessage(ctx context.Context, msg *domain.ChatMessage) error
+	// ListChatMessages returns a channel's messages oldest first: those after afterID, or
+	// the latest limit when afterID is 0. A nil teamID is the round channel.
+	ListChatMessages(ctx context.Context, roundID int64, teamID *int64, afterID int64, limit int) ([]domain.ChatMessage, error)
+	// MarkChatRead moves the user's read position in a channel forward to messageID (never
+	// back) and returns it. A message outside the channel is not found.
+	MarkChatRead(ctx context.Context, roundID int64, teamID *int64, userID, messageID int64) (*domain.ChatReadCursor, error)
+	ListChatReadCursors(ctx context.Context, roundID int64, teamID *int64) ([]domain.ChatReadCursor, error)
 
 	// Teams
 	EnsureTeamCount(ctx context.Context, teamCount int) ([]domain.Team, error)
@@ -214,6 +793,16 @@ type GameRepository interface {
 	StartRound(ctx context.Context, roundID int64, customerBudget, batchSize int, marketPrice, costOfPublishing float64) (*domain.Round, error)
 	EndRound(ctx context.Context, roundID int64) (*domain.Round, error)
 	SetRoundPopupState(ctx context.Context, roundID int64, isActive bool) (*domain.Round, error)
+	SetRoundQCRoutingMode(ctx context.Context, roundID int64, mode domain.QCRoutingMode) (*domain.Round, error)
+	SetRoundLateJoinerPolicy(ctx context.Context, roundID int64, policy domain.LateJoinerPolicy) (*domain.Round, error)
+	SetRoundWIPLimits(ctx context.Context, roundID int64, maxUnratedBatches, maxUnratedJokes *int) (*domain.Round, error)
+	SetRoundChatEnabled(ctx context.Context, roundID int64, enabled bool) (*domain.Round, error)
+	// SetRoundPricing switches dynamic pricing on from the market price for jokes
+	// already on sale.
+	SetRoundPricing(ctx context.Context, roundID int64, pricing domain.RoundPricing) (*domain.Round, error)
+	SetRoundReturnPolicy(ctx context.Context, roundID int64, policy domain.ReturnPolicy) (*domain.Round, error)
+	// ListRoundTeamIDs returns the round's teams ordered by id (the QC rotation order).
+	ListRoundTeamIDs(ctx context.Context, roundID int64) ([]int64, error)
 
 	// Team round state
 	EnsureTeamRoundState(ctx context.Context, roundID, teamID int64) error
@@ -221,18 +810,62 @@ type GameRepository interface {
 	IncrementRatedStats(ctx context.Context, roundID, teamID int64, passesCount, pointsDelta int) error
 
 	// Batches and jokes
+	// CreateBatch submits a batch, returning a conflict error if it would break the round's
+	// WIP limits. The check and the insert share one transaction.
 	CreateBatch(ctx context.Context, roundID, teamID int64, jokes []string) (*domain.Batch, error)
 	ListBatchesByTeam(ctx context.Context, roundID, teamID int64) ([]domain.Batch, error)
 	GetBatchWithJokes(ctx context.Context, batchID int64) (*BatchWithJokes, error)
-	GetNextBatchForQC(ctx context.Context, roundID, qcUserID, teamID int64) (*BatchWithJokes, int, error)
+	// GetNextBatchForQC serves from sourceTeamID's queue, or from every team when nil.
+	GetNextBatchForQC(ctx context.Context, roundID, qcUserID int64, sourceTeamID *int64) (*BatchWithJokes, int, error)
 	RateBatch(ctx context.Context, batchID int64, qcUserID int64, ratings []domain.JokeRating, feedback *string) (*domain.Batch, []int64, error)
-	CountSubmittedBatches(ctx context.Context, roundID int64) (int, error)
+	CountSubmittedBatches(ctx context.Context, roundID int64, teamID *int64) (int, error)
+	// ListBatchFlow returns submitted/locked/rated timestamps for the round's batches.
+	ListBatchFlow(ctx context.Context, roundID int64) ([]BatchFlow, error)
+	// ListJokeLifecycles returns per-joke lifecycle timestamps for the round.
+	ListJokeLifecycles(ctx context.Context, roundID int64) ([]JokeLifecycle, error)
 
 	// Market and budget
 	EnsureCustomerBudget(ctx context.Context, roundID, customerID int64, starting int) (*domain.CustomerRoundBudget, error)
-	ListMarket(ctx context.Context, roundID, customerID int64) ([]MarketItem, error)
-	BuyJoke(ctx context.Context, roundID, customerID, jokeID int64, marketPrice float64) (*domain.Purchase, *domain.CustomerRoundBudget, int64, error)
-	ReturnJoke(ctx context.Context, roundID, customerID, jokeID int64, marketPrice float64) (*domain.Purchase, *domain.CustomerRoundBudget, int64, error)
+	// ListMarket returns one page of the round's market as seen by customerID, with the
+	// number of items matching the filter.
+	ListMarket(ctx context.Context, roundID, customerID int64, filter MarketFilter) ([]MarketItem, int64, error)
+	// BuyJoke charges the customer the joke's current price, worked out inside the
+	// purchase transaction, and records it on the purchase. Under dynamic pricing the
+	// sale also raises the joke's price.
+	BuyJoke(ctx context.Context, roundID, customerID, jokeID int64) (*domain.Purchase, *domain.CustomerRoundBudget, int64, error)
+	// ReturnJoke undoes a purchase, refunding the round's share of the price that was paid
+	// for it. The round's return policy is checked inside the same transaction.
+	ReturnJoke(ctx context.Context, roundID, customerID, jokeID int64) (*domain.Purchase, *domain.CustomerRoundBudget, int64, error)
+	// CountCustomerReturns returns how many jokes the customer has returned this round.
+	CountCustomerReturns(ctx context.Context, roundID, customerID int64) (int, error)
+	// GetJokePricing returns who owns a joke and its team-set price.
+	GetJokePricing(ctx context.Context, jokeID int64) (*JokePricing, error)
+	// SetJokeTeamPrice sets a joke's team-set price; nil reverts it to the market price.
+	SetJokeTeamPrice(ctx context.Context, jokeID int64, price *float64) error
+	// ListJokePriceChanges returns a joke's dynamic price changes, oldest first.
+	ListJokePriceChanges(ctx context.Context, jokeID int64) ([]domain.JokePriceChange, error)
+	// ListPurchaseEvents returns the round's buy/return events in the order they happened.
+	ListPurchaseEvents(ctx context.Context, roundID int64) ([]domain.PurchaseEvent, error)
+	// RateJoke stores or replaces a customer's rating of a joke. It fails with a conflict
+	// unless the customer currently holds a purchase of the joke.
+	RateJoke(ctx context.Context, rating *domain.CustomerRating) error
+	// ListCustomerPurchaseHistory returns one customer's buys and returns in the round,
+	// oldest first. Balance is left for the caller to fill in.
+	ListCustomerPurchaseHistory(ctx context.Context, roundID, customerID int64) ([]PurchaseHistoryEntry, error)
+
+	// Auctions
+	// PlaceBid places or replaces a customer's sealed bid on a joke while its auction is
+	// open, holding the amount back from their budget.
+	PlaceBid(ctx context.Context, roundID, customerID, jokeID int64, amount float64) (*domain.Bid, *domain.CustomerRoundBudget, error)
+	// ListCustomerBids returns a customer's bids in the round, newest first.
+	ListCustomerBids(ctx context.Context, roundID, customerID int64) ([]domain.Bid, error)
+	// ListDueAuctions returns jokes with open bids whose auction has closed (or whose
+	// round has ended).
+	ListDueAuctions(ctx context.Context) ([]int64, error)
+	// SettleAuction closes a joke's auction: the winner buys it as BuyJoke would, at the
+	// round's clearing price, and every other bid is released back to its budget. It
+	// returns nil if the auction isn't due or has nothing left to settle.
+	SettleAuction(ctx context.Context, jokeID int64) (*AuctionResult, error)
 
 	// Stats
 	GetTeamSummary(ctx context.Context, roundID, teamID int64) (*TeamSummary, error)
@@ -240,6 +873,32 @@ type GameRepository interface {
 	GetRoundStats(ctx context.Context, roundID int64) ([]TeamStats, error)
 	GetRoundStatsV2(ctx context.Context, roundID int64) (*RoundStats, error)
 
+	// Export
+	// StreamExport streams a table export row by row without buffering it.
+	// ExportLeaderboard is not a table and is not supported here.
+	StreamExport(ctx context.Context, entity ExportEntity, filter ExportFilter, emit ExportRowFunc) error
+
+	// Lobby undo
+	// CaptureLobbyUsers reads the current lobby state of the given users in a round.
+	CaptureLobbyUsers(ctx context.Context, roundID int64, userIDs []int64) ([]LobbyUserState, error)
+	PushLobbyUndo(ctx context.Context, entry *LobbyUndoEntry) error
+	// ListLobbyUndo returns the round's pending (not undone) entries, most recent first.
+	ListLobbyUndo(ctx context.Context, roundID int64, limit int) ([]LobbyUndoEntry, error)
+	// UndoLobbyChange reverts the round's most recent pending entry. It fails with a
+	// conflict if the round has left CONFIGURED or a touched user changed since.
+	UndoLobbyChange(ctx context.Context, roundID int64) (*LobbyUndoEntry, error)
+
+	// Audit
+	InsertAuditEvent(ctx context.Context, event *domain.AuditEvent) error
+	// ListAuditEvents returns matching events newest first, plus the total match count.
+	ListAuditEvents(ctx context.Context, filter AuditFilter) ([]domain.AuditEvent, int64, error)
+
+	// Snapshots
+	// ExportSnapshot reads every game table in one consistent transaction.
+	ExportSnapshot(ctx context.Context) (*GameSnapshot, error)
+	// ImportSnapshot restores a snapshot into an empty (fresh or reset) game, remapping ids.
+	ImportSnapshot(ctx context.Context, snap *GameSnapshot) (*SnapshotImportResult, error)
+
 	// Admin utilities
 	ResetGame(ctx context.Context) error
 }
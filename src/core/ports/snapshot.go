@@ -0,0 +1,331 @@
+package ports
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SnapshotVersion is the GameSnapshot format written by this build. Bump it whenever a
+// table or column is added to the snapshot so older archives are recognised on import.
+// Version 2 added users.removed_at/removed_by; version 3 rounds.late_joiner_policy;
+// version 4 rounds.chat_enabled; version 5 purchase_events.price; version 6
+// customer_ratings; version 7 round pricing, jokes.team_price and purchases.price;
+// version 8 rounds.price_step/price_decay and joke_price_changes; version 9 round
+// auction settings and joke_bids; version 10 round return policy; version 11
+// batches.qc_routing_mode; version 12 roster_entries, game_settings and
+// users.last_seen_at/rejoin_secret_hash/merged_into; version 13 users.late_admitted_at;
+// version 14 announcements and announcement_acks; version 15 game_scripts, script_runs
+// and script_step_log; version 16 chat_messages and chat_read_cursors. Audit events and
+// lobby undo entries are not part of the game and stay out.
+const SnapshotVersion = 16
+
+// MinSnapshotVersion is the oldest format Import still reads; columns added since then
+// are simply absent (zero) in older archives.
+const MinSnapshotVersion = 1
+
+// GameSnapshot is a full, self-contained copy of one game: every table behind
+// GameRepository, with the original ids. Import remaps all ids.
+type GameSnapshot struct {
+	Version               int                            `json:"version"`
+	ExportedAt            time.Time                      `json:"exported_at"`
+	Teams                 []SnapshotTeam                 `json:"teams"`
+	Users                 []SnapshotUser                 `json:"users"`
+	Rounds                []SnapshotRound                `json:"rounds"`
+	TeamRoundStates       []SnapshotTeamRoundState       `json:"team_rounds_state"`
+	Batches               []SnapshotBatch                `json:"batches"`
+	Jokes                 []SnapshotJoke                 `json:"jokes"`
+	JokeRatings           []SnapshotJokeRating           `json:"joke_ratings"`
+	PublishedJokes        []SnapshotPublishedJoke        `json:"published_jokes"`
+	CustomerBudgets       []SnapshotCustomerBudget       `json:"customer_round_budget"`
+	Purchases             []SnapshotPurchase             `json:"purchases"`
+	PurchaseEvents        []SnapshotPurchaseEvent        `json:"purchase_events"`
+	BatchSubmissionEvents []SnapshotBatchSubmissionEvent `json:"batch_submission_events"`
+	CustomerRatings       []SnapshotCustomerRating       `json:"customer_ratings"`
+	JokePriceChanges      []SnapshotJokePriceChange      `json:"joke_price_changes"`
+	JokeBids              []SnapshotJokeBid              `json:"joke_bids"`
+	RosterEntries         []SnapshotRosterEntry          `json:"roster_entries"`
+	GameSettings          *SnapshotGameSettings          `json:"game_settings"`
+	Announcements         []SnapshotAnnouncement         `json:"announcements"`
+	AnnouncementAcks      []SnapshotAnnouncementAck      `json:"announcement_acks"`
+	GameScripts           []SnapshotGameScript           `json:"game_scripts"`
+	ScriptRuns            []SnapshotScriptRun            `json:"script_runs"`
+	ScriptStepLog         []SnapshotScriptStepLog        `json:"script_step_log"`
+	ChatMessages          []SnapshotChatMessage          `json:"chat_messages"`
+	ChatReadCursors       []SnapshotChatReadCursor       `json:"chat_read_cursors"`
+}
+
+type SnapshotTeam struct {
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type SnapshotUser struct {
+	ID          int64      `json:"user_id" db:"user_id"`
+	DisplayName string     `json:"display_name" db:"display_name"`
+	Role        *string    `json:"role" db:"role"`
+	TeamID      *int64     `json:"team_id" db:"team_id"`
+	Status      string     `json:"status" db:"status"`
+	AssignedAt  *time.Time `json:"assigned_at" db:"assigned_at"`
+	JoinedAt    time.Time  `json:"joined_at" db:"joined_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	RemovedAt   *time.Time `json:"removed_at" db:"removed_at"`
+	RemovedBy   *int64     `json:"removed_by" db:"removed_by"`
+	LastSeenAt  *time.Time `json:"last_seen_at" db:"last_seen_at"`
+	RejoinHash  *string    `json:"rejoin_secret_hash" db:"rejoin_secret_hash"`
+	MergedInto  *int64     `json:"merged_into" db:"merged_into"`
+	LateAdmit   *time.Time `json:"late_admitted_at" db:"late_admitted_at"`
+}
+
+type SnapshotRosterEntry struct {
+	ID            int64      `json:"roster_id" db:"roster_id"`
+	DisplayName   string     `json:"display_name" db:"display_name"`
+	StudentID     *string    `json:"student_id" db:"student_id"`
+	PreferredTeam *int       `json:"preferred_team" db:"preferred_team"`
+	PreferredRole *string    `json:"preferred_role" db:"preferred_role"`
+	JoinCode      string     `json:"join_code" db:"join_code"`
+	UserID        *int64     `json:"user_id" db:"user_id"`
+	ClaimedAt     *time.Time `json:"claimed_at" db:"claimed_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type SnapshotGameSettings struct {
+	RequireJoinCode bool      `json:"require_join_code" db:"require_join_code"`
+	MaxParticipants *int      `json:"max_participants" db:"max_participants"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SnapshotRound struct {
+	ID                int64      `json:"round_id" db:"round_id"`
+	RoundNumber       int        `json:"round_number" db:"round_number"`
+	Status            string     `json:"status" db:"status"`
+	CustomerBudget    int        `json:"customer_budget" db:"customer_budget"`
+	BatchSize         int        `json:"batch_size" db:"batch_size"`
+	MarketPrice       float64    `json:"market_price" db:"market_price"`
+	CostOfPublishing  float64    `json:"cost_of_publishing" db:"cost_of_publishing"`
+	IsPoppedActive    bool       `json:"is_popped_active" db:"is_popped_active"`
+	QCRoutingMode     string     `json:"qc_routing_mode" db:"qc_routing_mode"`
+	MaxUnratedBatches *int       `json:"max_unrated_batches" db:"max_unrated_batches"`
+	MaxUnratedJokes   *int       `json:"max_unrated_jokes" db:"max_unrated_jokes"`
+	LateJoinerPolicy  string     `json:"late_joiner_policy" db:"late_joiner_policy"`
+	ChatEnabled       *bool      `json:"chat_enabled" db:"chat_enabled"`
+	PricingMode       string     `json:"pricing_mode" db:"pricing_mode"`
+	MinJokePrice      *float64   `json:"min_joke_price" db:"min_joke_price"`
+	MaxJokePrice      *float64   `json:"max_joke_price" db:"max_joke_price"`
+	PriceStep         float64    `json:"price_step" db:"price_step"`
+	PriceDecay        float64    `json:"price_decay" db:"price_decay"`
+	AuctionRule       string     `json:"auction_rule" db:"auction_rule"`
+	AuctionWindow     int        `json:"auction_window_seconds" db:"auction_window_seconds"`
+	AuctionStartedAt  *time.Time `json:"auction_started_at" db:"auction_started_at"`
+	ReturnsAllowed    *bool      `json:"returns_allowed" db:"returns_allowed"`
+	ReturnWindow      *int       `json:"return_window_seconds" db:"return_window_seconds"`
+	MaxReturns        *int       `json:"max_returns" db:"max_returns"`
+	RefundPercent     *int       `json:"return_refund_percent" db:"return_refund_percent"`
+	StartedAt         *time.Time `json:"started_at" db:"started_at"`
+	EndedAt           *time.Time `json:"ended_at" db:"ended_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+}
+
+type SnapshotTeamRoundState struct {
+	RoundID        int64     `json:"round_id" db:"round_id"`
+	TeamID         int64     `json:"team_id" db:"team_id"`
+	PointsEarned   int       `json:"points_earned" db:"points_earned"`
+	BatchesCreated int       `json:"batches_created" db:"batches_created"`
+	BatchesRated   int       `json:"batches_rated" db:"batches_rated"`
+	AcceptedJokes  int       `json:"accepted_jokes" db:"accepted_jokes"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SnapshotBatch struct {
+	ID          int64      `json:"batch_id" db:"batch_id"`
+	RoundID     int64      `json:"round_id" db:"round_id"`
+	TeamID      int64      `json:"team_id" db:"team_id"`
+	Status      string     `json:"status" db:"status"`
+	SubmittedAt *time.Time `json:"submitted_at" db:"submitted_at"`
+	RatedAt     *time.Time `json:"rated_at" db:"rated_at"`
+	AvgScore    *float64   `json:"avg_score" db:"avg_score"`
+	PassesCount *int       `json:"passes_count" db:"passes_count"`
+	Feedback    *string    `json:"feedback" db:"feedback"`
+	LockedAt    *time.Time `json:"locked_at" db:"locked_at"`
+	LockedByQC  *int64     `json:"locked_by_qc" db:"locked_by_qc"`
+	QCRouting   string     `json:"qc_routing_mode" db:"qc_routing_mode"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type SnapshotJoke struct {
+	ID        int64     `json:"joke_id" db:"joke_id"`
+	BatchID   int64     `json:"batch_id" db:"batch_id"`
+	JokeText  string    `json:"joke_text" db:"joke_text"`
+	JokeTitle *string   `json:"joke_title" db:"joke_title"`
+	TeamPrice *float64  `json:"team_price" db:"team_price"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type SnapshotJokeRating struct {
+	JokeID   int64     `json:"joke_id" db:"joke_id"`
+	QCUserID int64     `json:"qc_user_id" db:"qc_user_id"`
+	Rating   int       `json:"rating" db:"rating"`
+	Tag      *string   `json:"tag" db:"tag"`
+	RatedAt  time.Time `json:"rated_at" db:"rated_at"`
+}
+
+type SnapshotPublishedJoke struct {
+	JokeID    int64     `json:"joke_id" db:"joke_id"`
+	RoundID   int64     `json:"round_id" db:"round_id"`
+	TeamID    int64     `json:"team_id" db:"team_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type SnapshotCustomerBudget struct {
+	RoundID         int64     `json:"round_id" db:"round_id"`
+	CustomerUserID  int64     `json:"customer_user_id" db:"customer_user_id"`
+	StartingBudget  float64   `json:"starting_budget" db:"starting_budget"`
+	RemainingBudget float64   `json:"remaining_budget" db:"remaining_budget"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SnapshotPurchase is one held joke. Price is nil in snapshots older than version 7;
+// import falls back to the round's market price.
+type SnapshotPurchase struct {
+	ID             int64     `json:"purchase_id" db:"purchase_id"`
+	RoundID        int64     `json:"round_id" db:"round_id"`
+	CustomerUserID int64     `json:"customer_user_id" db:"customer_user_id"`
+	JokeID         int64     `json:"joke_id" db:"joke_id"`
+	Price          *float64  `json:"price" db:"price"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// SnapshotPurchaseEvent is one buy or return. Price is nil in snapshots older than
+// version 5; import falls back to the round's market price.
+type SnapshotPurchaseEvent struct {
+	ID             int64     `json:"event_id" db:"event_id"`
+	RoundID        int64     `json:"round_id" db:"round_id"`
+	CustomerUserID int64     `json:"customer_user_id" db:"customer_user_id"`
+	JokeID         int64     `json:"joke_id" db:"joke_id"`
+	TeamID         int64     `json:"team_id" db:"team_id"`
+	Delta          int       `json:"delta" db:"delta"`
+	Price          *float64  `json:"price" db:"price"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+type SnapshotCustomerRating struct {
+	RoundID        int64     `json:"round_id" db:"round_id"`
+	CustomerUserID int64     `json:"customer_user_id" db:"customer_user_id"`
+	JokeID         int64     `json:"joke_id" db:"joke_id"`
+	Rating         int       `json:"rating" db:"rating"`
+	Review         *string   `json:"review" db:"review"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SnapshotJokePriceChange struct {
+	ID        int64     `json:"change_id" db:"change_id"`
+	RoundID   int64     `json:"round_id" db:"round_id"`
+	JokeID    int64     `json:"joke_id" db:"joke_id"`
+	SalePrice *float64  `json:"sale_price" db:"sale_price"`
+	Price     float64   `json:"price" db:"price"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type SnapshotJokeBid struct {
+	ID             int64      `json:"bid_id" db:"bid_id"`
+	RoundID        int64      `json:"round_id" db:"round_id"`
+	JokeID         int64      `json:"joke_id" db:"joke_id"`
+	CustomerUserID int64      `json:"customer_user_id" db:"customer_user_id"`
+	Amount         float64    `json:"amount" db:"amount"`
+	Status         string     `json:"status" db:"status"`
+	Price          *float64   `json:"price" db:"price"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	SettledAt      *time.Time `json:"settled_at" db:"settled_at"`
+}
+
+type SnapshotAnnouncement struct {
+	ID           int64      `json:"announcement_id" db:"announcement_id"`
+	Title        string     `json:"title" db:"title"`
+	Body         string     `json:"body" db:"body"`
+	Severity     string     `json:"severity" db:"severity"`
+	Target       string     `json:"target" db:"target"`
+	TargetRole   *string    `json:"target_role" db:"target_role"`
+	TargetTeamID *int64     `json:"target_team_id" db:"target_team_id"`
+	TargetUserID *int64     `json:"target_user_id" db:"target_user_id"`
+	ExpiresAt    *time.Time `json:"expires_at" db:"expires_at"`
+	CreatedBy    *int64     `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+type SnapshotAnnouncementAck struct {
+	AnnouncementID int64     `json:"announcement_id" db:"announcement_id"`
+	UserID         int64     `json:"user_id" db:"user_id"`
+	AckedAt        time.Time `json:"acked_at" db:"acked_at"`
+}
+
+type SnapshotGameScript struct {
+	ID        int64           `json:"script_id" db:"script_id"`
+	Name      string          `json:"name" db:"name"`
+	Steps     json.RawMessage `json:"steps" db:"steps"`
+	CreatedBy *int64          `json:"created_by" db:"created_by"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+type SnapshotScriptRun struct {
+	ID         int64           `json:"run_id" db:"run_id"`
+	ScriptID   *int64          `json:"script_id" db:"script_id"`
+	ScriptName string          `json:"script_name" db:"script_name"`
+	Steps      json.RawMessage `json:"steps" db:"steps"`
+	Status     string          `json:"status" db:"status"`
+	NextStep   int             `json:"next_step" db:"next_step"`
+	ElapsedMs  int64           `json:"elapsed_ms" db:"elapsed_ms"`
+	ResumedAt  *time.Time      `json:"resumed_at" db:"resumed_at"`
+	StartedBy  *int64          `json:"started_by" db:"started_by"`
+	StartedAt  time.Time       `json:"started_at" db:"started_at"`
+	EndedAt    *time.Time      `json:"ended_at" db:"ended_at"`
+}
+
+type SnapshotScriptStepLog struct {
+	ID         int64     `json:"log_id" db:"log_id"`
+	RunID      int64     `json:"run_id" db:"run_id"`
+	StepIndex  int       `json:"step_index" db:"step_index"`
+	Action     string    `json:"action" db:"action"`
+	Outcome    string    `json:"outcome" db:"outcome"`
+	Error      string    `json:"error" db:"error"`
+	ExecutedAt time.Time `json:"executed_at" db:"executed_at"`
+}
+
+type SnapshotChatMessage struct {
+	ID           int64     `json:"message_id" db:"message_id"`
+	RoundID      int64     `json:"round_id" db:"round_id"`
+	TeamID       *int64    `json:"team_id" db:"team_id"`
+	SenderUserID *int64    `json:"sender_user_id" db:"sender_user_id"`
+	SenderRole   *string   `json:"sender_role" db:"sender_role"`
+	Body         string    `json:"body" db:"body"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+type SnapshotChatReadCursor struct {
+	RoundID           int64     `json:"round_id" db:"round_id"`
+	TeamID            *int64    `json:"team_id" db:"team_id"`
+	UserID            int64     `json:"user_id" db:"user_id"`
+	LastReadMessageID int64     `json:"last_read_message_id" db:"last_read_message_id"`
+	ReadAt            time.Time `json:"read_at" db:"read_at"`
+}
+
+type SnapshotBatchSubmissionEvent struct {
+	ID         int64     `json:"event_id" db:"event_id"`
+	RoundID    int64     `json:"round_id" db:"round_id"`
+	TeamID     int64     `json:"team_id" db:"team_id"`
+	BatchID    int64     `json:"batch_id" db:"batch_id"`
+	JokesCount int       `json:"jokes_count" db:"jokes_count"`
+	Delta      int       `json:"delta" db:"delta"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// SnapshotImportResult reports how many rows were restored per table and where the
+// snapshot's rounds ended up.
+type SnapshotImportResult struct {
+	Rows     map[string]int  `json:"rows"`
+	RoundIDs map[int64]int64 `json:"round_ids"`
+}
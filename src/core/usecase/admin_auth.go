@@ -11,10 +11,11 @@ import (
 type AdminAuthService struct {
 	repo          ports.GameRepository
 	adminPassword string
+	audit         auditLog
 }
 
 func NewAdminAuthService(repo ports.GameRepository, adminPassword string) *AdminAuthService {
-	return &AdminAuthService{repo: repo, adminPassword: adminPassword}
+	return &AdminAuthService{repo: repo, adminPassword: adminPassword, audit: auditLog{repo: repo}}
 }
 
 type AdminLoginResult struct {
@@ -30,8 +31,8 @@ func (s *AdminAuthService) Login(ctx context.Context, displayName, password stri
 		return nil, domain.NewUnauthorizedError("invalid admin password")
 	}
 
-	// Get or create user
-	user, err := s.repo.GetUserByDisplayName(ctx, displayName)
+	// Get or create the instructor; a student with the same name is never taken over.
+	user, err := s.repo.GetInstructorByDisplayName(ctx, displayName)
 	if err != nil {
 		if !domain.IsNotFound(err) {
 			return nil, err
@@ -80,5 +81,6 @@ func (s *AdminAuthService) ResetGame(ctx context.Context) error {
 	if err := s.repo.ResetGame(ctx); err != nil {
 		return err
 	}
+	s.audit.record(ctx, AuditGameReset, auditTargetGame, nil, nil, nil, nil)
 	return nil
 }
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// admissions applies the participant cap and the round's late-joiner policy. Join runs it
+// for the active round, and the instructor runs it by changing the cap or the policy, and
+// after deleting or merging users frees a place.
+// Each late joiner is claimed before being seated, so they are seated at most once even
+// when two runs overlap.
+type admissions struct {
+	repo     ports.GameRepository
+	log      *slog.Logger
+	presence domain.PresencePolicy
+}
+
+// applyActive runs apply for the active round. With no active round it still promotes
+// waitlisted participants.
+func (a admissions) applyActive(ctx context.Context) error {
+	round, err := a.repo.GetActiveRound(ctx)
+	if err != nil {
+		if !domain.IsNotFound(err) {
+			return err
+		}
+		round = nil
+	}
+	return a.apply(ctx, round)
+}
+
+// apply lets waitlisted participants in as places free up, then seats the round's late
+// joiners according to its policy. Participants who have dropped are left waiting.
+func (a admissions) apply(ctx context.Context, round *domain.Round) error {
+	if promoted, err := a.repo.PromoteWaitlisted(ctx); err != nil {
+		return err
+	} else if len(promoted) > 0 {
+		a.log.Info("promoted waitlisted participants", "user_ids", promoted)
+	}
+	if round == nil || round.Status == domain.RoundEnded || round.LateJoinerPolicy == domain.LateJoinersWait || round.LateJoinerPolicy == "" {
+		return nil
+	}
+
+	late, err := a.repo.ListLateJoiners(ctx, round.ID)
+	if err != nil || len(late) == 0 {
+		return err
+	}
+	now := time.Now()
+
+	switch round.LateJoinerPolicy {
+	case domain.LateJoinersCustomer:
+		role := domain.RoleCustomer
+		for _, u := range late {
+			if a.presence.At(u.LastSeenAt, now) == domain.PresenceDropped {
+				continue
+			}
+			claimed, err := a.repo.ClaimLateJoiner(ctx, u.ID)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+			// PatchUserInRound seeds the customer's budget row for the round.
+			if err := a.repo.PatchUserInRound(ctx, round.ID, u.ID, domain.ParticipantAssigned, &role, nil); err != nil {
+				return err
+			}
+		}
+
+	case domain.LateJoinersSmallestTeam:
+		teamIDs, err := a.repo.ListRoundTeamIDs(ctx, round.ID)
+		if err != nil || len(teamIDs) == 0 {
+			return err
+		}
+		type teamSize struct{ jm, qc int }
+		sizes := make(map[int64]*teamSize, len(teamIDs))
+		for _, id := range teamIDs {
+			members, err := a.repo.ListTeamMembers(ctx, id)
+			if err != nil {
+				return err
+			}
+			ts := &teamSize{}
+			for _, m := range members {
+				switch m.Role {
+				case domain.RoleJM:
+					ts.jm++
+				case domain.RoleQC:
+					ts.qc++
+				}
+			}
+			sizes[id] = ts
+		}
+		for _, u := range late {
+			if a.presence.At(u.LastSeenAt, now) == domain.PresenceDropped {
+				continue
+			}
+			claimed, err := a.repo.ClaimLateJoiner(ctx, u.ID)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+			// teamIDs is ordered by id, so ties go to the lowest team.
+			teamID := teamIDs[0]
+			for _, id := range teamIDs[1:] {
+				if s := sizes[id]; s.jm+s.qc < sizes[teamID].jm+sizes[teamID].qc {
+					teamID = id
+				}
+			}
+			ts := sizes[teamID]
+			role := domain.RoleJM
+			if ts.qc < ts.jm {
+				role = domain.RoleQC
+			}
+			tid := teamID
+			if err := a.repo.PatchUserInRound(ctx, round.ID, u.ID, domain.ParticipantAssigned, &role, &tid); err != nil {
+				return err
+			}
+			if role == domain.RoleJM {
+				ts.jm++
+			} else {
+				ts.qc++
+			}
+		}
+	}
+	return nil
+}
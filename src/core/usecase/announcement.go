@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+const (
+	maxAnnouncementTitle = 200
+	maxAnnouncementBody  = 5000
+
+	// announcementStreamBuffer is how many pushed announcements a slow stream may fall
+	// behind by before further ones are dropped for it. Clients reload the list on
+	// reconnect, so a dropped push is only delayed.
+	announcementStreamBuffer = 16
+)
+
+// AnnouncementService lets the instructor message participants and see who has read
+// each message. New announcements are also pushed to participants with an open stream.
+type AnnouncementService struct {
+	repo  ports.GameRepository
+	log   *slog.Logger
+	audit auditLog
+	hub   *hub[domain.Announcement]
+}
+
+func NewAnnouncementService(repo ports.GameRepository, log *slog.Logger) *AnnouncementService {
+	return &AnnouncementService{
+		repo:  repo,
+		log:   log,
+		audit: auditLog{repo: repo, log: log},
+		hub:   newHub[domain.Announcement](announcementStreamBuffer),
+	}
+}
+
+// Create validates and stores an announcement, then pushes it to connected recipients.
+// Severity defaults to INFO and target to ALL.
+func (s *AnnouncementService) Create(ctx context.Context, createdBy int64, a *domain.Announcement) (*domain.Announcement, error) {
+	if err := s.validate(ctx, a); err != nil {
+		return nil, err
+	}
+	a.CreatedBy = &createdBy
+	if err := s.repo.CreateAnnouncement(ctx, a); err != nil {
+		s.log.Error("create announcement failed", "error", err)
+		return nil, err
+	}
+	s.audit.record(ctx, AuditAnnouncement, auditTargetAnnouncement, &a.ID, nil, nil, auditAnnouncement(a))
+	s.hub.publish(*a)
+	return a, nil
+}
+
+func (s *AnnouncementService) validate(ctx context.Context, a *domain.Announcement) error {
+	a.Title = strings.TrimSpace(a.Title)
+	if a.Title == "" {
+		return domain.NewValidationError("title", "is required")
+	}
+	if utf8.RuneCountInString(a.Title) > maxAnnouncementTitle {
+		return domain.NewValidationError("title", fmt.Sprintf("must be at most %d characters", maxAnnouncementTitle))
+	}
+	a.Body = strings.TrimSpace(a.Body)
+	if utf8.RuneCountInString(a.Body) > maxAnnouncementBody {
+		return domain.NewValidationError("body", fmt.Sprintf("must be at most %d characters", maxAnnouncementBody))
+	}
+	if a.Severity == "" {
+		a.Severity = domain.SeverityInfo
+	}
+	if !a.Severity.IsValid() {
+		return domain.NewValidationError("severity", "must be INFO, WARNING or CRITICAL")
+	}
+	if a.Target == "" {
+		a.Target = domain.TargetAll
+	}
+	if !a.Target.IsValid() {
+		return domain.NewValidationError("target", "must be ALL, ROLE, TEAM or USER")
+	}
+	if a.ExpiresAt != nil && !a.ExpiresAt.After(time.Now()) {
+		return domain.NewValidationError("expires_at", "must be in the future")
+	}
+
+	// Exactly the target field matching Target must be set.
+	if a.TargetRole != nil && a.Target != domain.TargetRole {
+		return domain.NewValidationError("target_role", "is only allowed when target is ROLE")
+	}
+	if a.TargetTeamID != nil && a.Target != domain.TargetTeam {
+		return domain.NewValidationError("target_team_id", "is only allowed when target is TEAM")
+	}
+	if a.TargetUserID != nil && a.Target != domain.TargetUser {
+		return domain.NewValidationError("target_user_id", "is only allowed when target is USER")
+	}
+	switch a.Target {
+	case domain.TargetRole:
+		if a.TargetRole == nil {
+			return domain.NewValidationError("target_role", "is required when target is ROLE")
+		}
+		switch *a.TargetRole {
+		case domain.RoleJM, domain.RoleQC, domain.RoleCustomer:
+		default:
+			return domain.NewValidationError("target_role", "must be JM, QC or CUSTOMER")
+		}
+	case domain.TargetTeam:
+		if a.TargetTeamID == nil {
+			return domain.NewValidationError("target_team_id", "is required when target is TEAM")
+		}
+		if _, err := s.repo.GetTeam(ctx, *a.TargetTeamID); err != nil {
+			return err
+		}
+	case domain.TargetUser:
+		if a.TargetUserID == nil {
+			return domain.NewValidationError("target_user_id", "is required when target is USER")
+		}
+		user, err := s.repo.GetUserByID(ctx, *a.TargetUserID)
+		if err != nil {
+			return err
+		}
+		if user.Role != nil && *user.Role == domain.RoleInstructor {
+			return domain.NewValidationError("target_user_id", "cannot be an instructor")
+		}
+	}
+	return nil
+}
+
+// List returns every announcement, newest first, with read counts.
+func (s *AnnouncementService) List(ctx context.Context) ([]ports.AnnouncementSummary, error) {
+	return s.repo.ListAnnouncements(ctx)
+}
+
+// Receipts returns an announcement and, for each of its current recipients, whether they
+// have acknowledged it.
+func (s *AnnouncementService) Receipts(ctx context.Context, announcementID int64) (*domain.Announcement, []ports.AnnouncementRecipient, error) {
+	a, err := s.repo.GetAnnouncement(ctx, announcementID)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipients, err := s.repo.ListAnnouncementRecipients(ctx, announcementID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, recipients, nil
+}
+
+// ForUser returns the unexpired announcements a participant currently receives.
+func (s *AnnouncementService) ForUser(ctx context.Context, userID int64) ([]ports.UserAnnouncement, error) {
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAnnouncementsForUser(ctx, userID)
+}
+
+// Ack records that a participant has read an announcement addressed to them.
+func (s *AnnouncementService) Ack(ctx context.Context, announcementID, userID int64) (time.Time, error) {
+	a, err := s.repo.GetAnnouncement(ctx, announcementID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !a.AppliesTo(user) {
+		// Don't reveal announcements addressed to someone else.
+		return time.Time{}, domain.NewNotFoundError("announcement")
+	}
+	return s.repo.AckAnnouncement(ctx, announcementID, userID)
+}
+
+// Subscribe streams announcements created from now on that are addressed to the user,
+// as assigned when each one is created. The channel is closed once ctx is done.
+func (s *AnnouncementService) Subscribe(ctx context.Context, userID int64) (<-chan domain.Announcement, error) {
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	in := s.hub.subscribe()
+	out := make(chan domain.Announcement)
+	go func() {
+		defer close(out)
+		defer s.hub.unsubscribe(in)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case a := <-in:
+				// Reload the user each time: their role or team may have changed.
+				user, err := s.repo.GetUserByID(ctx, userID)
+				if err != nil {
+					if ctx.Err() == nil {
+						s.log.Warn("announcement stream: load user failed", "user_id", userID, "error", err)
+					}
+					if domain.IsNotFound(err) {
+						return
+					}
+					continue
+				}
+				if !a.AppliesTo(user) || a.Expired(time.Now()) {
+					continue
+				}
+				select {
+				case out <- a:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// auditAnnouncement is the audited view of an announcement.
+func auditAnnouncement(a *domain.Announcement) any {
+	return map[string]any{
+		"announcement_id": a.ID,
+		"title":           a.Title,
+		"body":            a.Body,
+		"severity":        a.Severity,
+		"target":          a.Target,
+		"target_role":     a.TargetRole,
+		"target_team_id":  a.TargetTeamID,
+		"target_user_id":  a.TargetUserID,
+		"expires_at":      a.ExpiresAt,
+	}
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+const (
+	// defaultAuctionWindowSeconds and its bounds size each joke's bidding window.
+	defaultAuctionWindowSeconds = 60
+	minAuctionWindowSeconds     = 10
+	maxAuctionWindowSeconds     = 3600
+
+	// auctionTick is how often closed auctions are looked for and settled.
+	auctionTick = time.Second
+)
+
+// Bid places or replaces the customer's sealed bid on a joke in an auction round. The
+// bid must reach the round's reserve price; the whole amount is held back from the
+// budget until the auction closes, when it is charged (in part, under second-price
+// rules) if it won and released if it lost.
+func (s *CustomerService) Bid(ctx context.Context, userID, roundID, jokeID int64, amount float64) (*domain.Bid, *domain.CustomerRoundBudget, error) {
+	if err := s.ensureCustomer(ctx, userID); err != nil {
+		return nil, nil, err
+	}
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if round.Status != domain.RoundActive {
+		return nil, nil, domain.NewConflictError("round not active")
+	}
+	if round.PricingMode != domain.PricingAuction {
+		return nil, nil, domain.NewConflictError("round does not sell by auction")
+	}
+	amount = roundCents(amount)
+	if amount <= 0 || amount < round.ReservePrice() {
+		return nil, nil, domain.NewValidationError("amount", fmt.Sprintf("must be at least %.2f", round.ReservePrice()))
+	}
+	if _, err := s.repo.EnsureCustomerBudget(ctx, roundID, userID, round.CustomerBudget); err != nil {
+		return nil, nil, err
+	}
+	return s.repo.PlaceBid(ctx, roundID, userID, jokeID, amount)
+}
+
+// Bids returns the customer's bids in the round, newest first, open and settled.
+func (s *CustomerService) Bids(ctx context.Context, userID, roundID int64) ([]domain.Bid, error) {
+	if err := s.ensureCustomer(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCustomerBids(ctx, roundID, userID)
+}
+
+// RunAuctions settles auctions as they close until ctx is cancelled.
+func (s *CustomerService) RunAuctions(ctx context.Context) {
+	ticker := time.NewTicker(auctionTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.settleAuctions(ctx); err != nil && ctx.Err() == nil {
+				s.log.Error("auction closer tick failed", "error", err)
+			}
+		}
+	}
+}
+
+// settleAuctions settles every auction that has closed. Settling is idempotent, so
+// several servers may run the closer side by side. An auction that fails to settle is
+// logged and retried next tick without holding up the others.
+func (s *CustomerService) settleAuctions(ctx context.Context) error {
+	jokeIDs, err := s.repo.ListDueAuctions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, jokeID := range jokeIDs {
+		if err := s.settleAuction(ctx, jokeID); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.log.Error("auction settlement failed", "joke_id", jokeID, "error", err)
+		}
+	}
+	return nil
+}
+
+// settleAuction settles one auction, reporting a panic as an error so the closer, and
+// the server it runs in, survive it.
+func (s *CustomerService) settleAuction(ctx context.Context, jokeID int64) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("auction settlement panic: %v", r)
+		}
+	}()
+	res, err := s.repo.SettleAuction(ctx, jokeID)
+	if err != nil {
+		return err
+	}
+	if res != nil {
+		s.logAuction(res)
+	}
+	return nil
+}
+
+func (s *CustomerService) logAuction(res *ports.AuctionResult) {
+	if res.WinnerUserID == nil {
+		s.log.Info("auction closed without a winner", "round_id", res.RoundID, "joke_id", res.JokeID, "bids", res.Bids)
+		return
+	}
+	s.log.Info("auction closed", "round_id", res.RoundID, "joke_id", res.JokeID, "team_id", res.TeamID,
+		"winner_user_id", *res.WinnerUserID, "price", res.Price, "bids", res.Bids)
+}
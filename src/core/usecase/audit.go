@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// Audit actions recorded for instructor mutations.
+const (
+	AuditRoundConfig      = "round.config"
+	AuditRoundAssign      = "round.assign"
+	AuditRoundStart       = "round.start"
+	AuditRoundEnd         = "round.end"
+	AuditRoundPopups      = "round.popups"
+	AuditRoundQCRouting   = "round.qc_routing"
+	AuditRoundWIPLimits   = "round.wip_limits"
+	AuditRoundLateJoiners = "round.late_joiners"
+	AuditRoundChat        = "round.chat"
+	AuditRoundPricing     = "round.pricing"
+	AuditRoundReturns     = "round.returns"
+	AuditUserPatch        = "user.patch"
+	AuditUserDelete       = "user.delete"
+	AuditUserRestore      = "user.restore"
+	AuditUserReplace      = "user.replace"
+	AuditUserMerge        = "user.merge"
+	AuditLobbyUndo        = "round.lobby_undo"
+	AuditGameReset        = "game.reset"
+	AuditGameSettings     = "game.settings"
+	AuditRosterImport     = "roster.import"
+	AuditAnnouncement     = "announcement.create"
+	AuditScriptSave       = "script.save"
+	AuditScriptDelete     = "script.delete"
+	AuditScriptPlay       = "script.play"
+	AuditScriptPause      = "script.pause"
+	AuditScriptSkip       = "script.skip"
+	AuditScriptStop       = "script.stop"
+	AuditSnapshotImport   = "game.snapshot_import"
+	AuditChatBroadcast    = "chat.broadcast"
+)
+
+const (
+	auditTargetRound        = "round"
+	auditTargetUser         = "user"
+	auditTargetGame         = "game"
+	auditTargetAnnouncement = "announcement"
+	auditTargetScript       = "script"
+	auditTargetScriptRun    = "script_run"
+	auditTargetChatMessage  = "chat_message"
+
+	maxAuditPageSize = 500
+)
+
+// DefaultAuditPageSize is the audit log page size when the caller doesn't pick one.
+const DefaultAuditPageSize = 50
+
+// AuditActor identifies who is making a request, for the audit log.
+type AuditActor struct {
+	UserID      int64
+	DisplayName string
+	RequestID   string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor attaches the acting instructor to ctx so mutations can be attributed.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+func auditActorFrom(ctx context.Context) (AuditActor, bool) {
+	actor, ok := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor, ok
+}
+
+// auditLog writes audit events on behalf of a service.
+type auditLog struct {
+	repo ports.GameRepository
+	log  *slog.Logger
+}
+
+// record stores one audit event. before/after are marshalled to JSON; nil means the
+// target didn't exist on that side. Failures are logged rather than returned because the
+// mutation being audited has already been committed.
+func (a auditLog) record(ctx context.Context, action, targetType string, targetID, roundID *int64, before, after any) {
+	event := &domain.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		RoundID:    roundID,
+	}
+	if actor, ok := auditActorFrom(ctx); ok {
+		event.ActorUserID = &actor.UserID
+		event.ActorDisplayName = actor.DisplayName
+		event.RequestID = actor.RequestID
+	}
+	var err error
+	if event.Before, err = auditJSON(before); err == nil {
+		event.After, err = auditJSON(after)
+	}
+	if err == nil {
+		err = a.repo.InsertAuditEvent(ctx, event)
+	}
+	if err != nil {
+		a.logger().Error("audit record failed", "action", action, "target_type", targetType, "error", err)
+	}
+}
+
+func (a auditLog) logger() *slog.Logger {
+	if a.log == nil {
+		return slog.Default()
+	}
+	return a.log
+}
+
+func auditJSON(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// auditRound is the audited view of a round.
+func auditRound(r *domain.Round) any {
+	if r == nil {
+		return nil
+	}
+	return map[string]any{
+		"round_id":            r.ID,
+		"round_number":        r.RoundNumber,
+		"status":              r.Status,
+		"customer_budget":     r.CustomerBudget,
+		"batch_size":          r.BatchSize,
+		"market_price":        r.MarketPrice,
+		"cost_of_publishing":  r.CostOfPublishing,
+		"is_popped_active":    r.IsPoppedActive,
+		"qc_routing_mode":     r.QCRoutingMode,
+		"max_unrated_batches": r.MaxUnratedBatches,
+		"max_unrated_jokes":   r.MaxUnratedJokes,
+		"late_joiner_policy":  r.LateJoinerPolicy,
+		"chat_enabled":        r.ChatEnabled,
+		"pricing_mode":        r.PricingMode,
+		"min_joke_price":      r.MinJokePrice,
+		"max_joke_price":      r.MaxJokePrice,
+		"price_step":          r.PriceStep,
+		"price_decay":         r.PriceDecay,
+		"auction_rule":        r.AuctionRule,
+		"auction_window_secs": r.AuctionWindowSeconds,
+		"returns_allowed":     r.ReturnsAllowed,
+		"return_window_secs":  r.ReturnWindowSeconds,
+		"max_returns":         r.MaxReturns,
+		"refund_percent":      r.RefundPercent,
+		"started_at":          r.StartedAt,
+		"ended_at":            r.EndedAt,
+	}
+}
+
+// auditUser is the audited view of a user.
+func auditUser(u *domain.User) any {
+	if u == nil {
+		return nil
+	}
+	return map[string]any{
+		"user_id":      u.ID,
+		"display_name": u.DisplayName,
+		"role":         u.Role,
+		"team_id":      u.TeamID,
+		"status":       u.Status,
+	}
+}
+
+// auditLobby is the audited view of a lobby: who is on which team in which role.
+func auditLobby(l *ports.LobbySnapshot) any {
+	if l == nil {
+		return nil
+	}
+	teams := make([]map[string]any, 0, len(l.Teams))
+	for _, t := range l.Teams {
+		members := make([]map[string]any, 0, len(t.Members))
+		for _, m := range t.Members {
+			members = append(members, map[string]any{"user_id": m.UserID, "role": m.Role})
+		}
+		teams = append(teams, map[string]any{"team_id": t.Team.ID, "members": members})
+	}
+	customers := make([]int64, 0, len(l.Customers))
+	for _, c := range l.Customers {
+		customers = append(customers, c.UserID)
+	}
+	unassigned := make([]int64, 0, len(l.Unassigned))
+	for _, u := range l.Unassigned {
+		unassigned = append(unassigned, u.UserID)
+	}
+	return map[string]any{"teams": teams, "customers": customers, "unassigned": unassigned}
+}
+
+// AuditService reads the audit log.
+type AuditService struct {
+	repo ports.GameRepository
+	log  *slog.Logger
+}
+
+func NewAuditService(repo ports.GameRepository, log *slog.Logger) *AuditService {
+	return &AuditService{repo: repo, log: log}
+}
+
+// List returns one page (1-based) of matching events, newest first, and the total count.
+// A zero perPage uses the default page size.
+func (s *AuditService) List(ctx context.Context, filter ports.AuditFilter, page, perPage int) ([]domain.AuditEvent, int64, error) {
+	if page < 1 {
+		return nil, 0, domain.NewValidationError("page", "must be at least 1")
+	}
+	if perPage == 0 {
+		perPage = DefaultAuditPageSize
+	}
+	if perPage < 1 || perPage > maxAuditPageSize {
+		return nil, 0, domain.NewValidationError("per_page", "must be between 1 and 500")
+	}
+	filter.Limit = perPage
+	filter.Offset = (page - 1) * perPage
+	events, total, err := s.repo.ListAuditEvents(ctx, filter)
+	if err != nil {
+		s.log.Error("list audit events failed", "error", err)
+		return nil, 0, err
+	}
+	return events, total, nil
+}
@@ -54,9 +54,63 @@ func (s *BatchService) Submit(ctx context.Context, userID, roundID, teamID int64
 	if err := s.repo.EnsureTeamRoundState(ctx, roundID, teamID); err != nil {
 		return nil, err
 	}
+	// CreateBatch checks the WIP limits under a lock on the team's round state.
 	return s.repo.CreateBatch(ctx, roundID, teamID, jokes)
 }
 
+// SetJokePrice sets the asking price of one of the team's jokes, before or after it is
+// published; nil goes back to the market price. Only the team's JM or QC may price its
+// jokes, and only in a team-priced round that hasn't ended. Existing purchases keep the
+// price they were bought at.
+func (s *BatchService) SetJokePrice(ctx context.Context, userID, roundID, jokeID int64, price *float64) (*ports.JokePricing, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == nil || (*user.Role != domain.RoleJM && *user.Role != domain.RoleQC) {
+		return nil, domain.NewForbiddenError("user must be JM or QC")
+	}
+	joke, err := s.repo.GetJokePricing(ctx, jokeID)
+	if err != nil {
+		return nil, err
+	}
+	if joke.RoundID != roundID {
+		return nil, domain.NewNotFoundError("joke")
+	}
+	if user.TeamID == nil || *user.TeamID != joke.TeamID {
+		return nil, domain.NewForbiddenError("user not on this team")
+	}
+
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	if round.PricingMode != domain.PricingTeamSet {
+		return nil, domain.NewConflictError("round does not use team pricing")
+	}
+	if round.Status == domain.RoundEnded {
+		return nil, domain.NewConflictError("round has ended")
+	}
+	if price != nil {
+		if (round.MinJokePrice != nil && *price < *round.MinJokePrice) || (round.MaxJokePrice != nil && *price > *round.MaxJokePrice) {
+			return nil, domain.NewValidationError("price", fmt.Sprintf("must be between %s and %s", formatPrice(round.MinJokePrice), formatPrice(round.MaxJokePrice)))
+		}
+	}
+
+	if err := s.repo.SetJokeTeamPrice(ctx, jokeID, price); err != nil {
+		return nil, err
+	}
+	joke.TeamPrice = price
+	return joke, nil
+}
+
+func formatPrice(p *float64) string {
+	if p == nil {
+		return "any"
+	}
+	return fmt.Sprintf("%.2f", *p)
+}
+
 // List returns batches submitted by a team.
 func (s *BatchService) List(ctx context.Context, roundID, teamID, userID int64) ([]domain.Batch, error) {
 	user, err := s.repo.GetUserByID(ctx, userID)
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+const (
+	maxChatBody = 1000
+
+	// DefaultChatPageSize is how many messages a channel read returns when the caller
+	// doesn't pick a limit.
+	DefaultChatPageSize = 50
+	maxChatPageSize     = 200
+
+	// chatStreamBuffer is how many events a slow stream may fall behind by before
+	// further ones are dropped for it. Clients reload the channel on reconnect.
+	chatStreamBuffer = 64
+)
+
+// Chat event types pushed to open streams.
+const (
+	ChatEventMessage = "message"
+	ChatEventRead    = "read"
+)
+
+// ChatEvent is pushed to open chat streams: a new message, or a reader moving their
+// read position forward. Exactly one of Message and Read is set.
+type ChatEvent struct {
+	Type    string
+	RoundID int64
+	TeamID  *int64
+	Message *domain.ChatMessage
+	Read    *domain.ChatReadCursor
+}
+
+// ChatMessageView is a message with the users, other than its sender, who have read it.
+type ChatMessageView struct {
+	Message domain.ChatMessage
+	ReadBy  []domain.ChatReadCursor
+}
+
+// ChatService runs the per-team JM/QC channels and the instructor's round channel.
+// Messages are stored, read positions give read receipts, and new messages are pushed
+// to participants with an open stream. Instructor posts to the round channel are audited.
+type ChatService struct {
+	repo  ports.GameRepository
+	log   *slog.Logger
+	audit auditLog
+	hub   *hub[ChatEvent]
+}
+
+func NewChatService(repo ports.GameRepository, log *slog.Logger) *ChatService {
+	return &ChatService{repo: repo, log: log, audit: auditLog{repo: repo, log: log}, hub: newHub[ChatEvent](chatStreamBuffer)}
+}
+
+// Post adds a message to a channel. Only the team's JM and QC may post to a team channel,
+// and only while chat is enabled for the round; only instructors post to the round channel.
+func (s *ChatService) Post(ctx context.Context, userID, roundID int64, teamID *int64, body string) (*domain.ChatMessage, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, domain.NewValidationError("body", "is required")
+	}
+	if utf8.RuneCountInString(body) > maxChatBody {
+		return nil, domain.NewValidationError("body", fmt.Sprintf("must be at most %d characters", maxChatBody))
+	}
+
+	user, round, err := s.channel(ctx, userID, roundID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if teamID == nil {
+		if !isInstructor(user) {
+			return nil, domain.NewForbiddenError("only the instructor can post to the round channel")
+		}
+	} else {
+		if user.TeamID == nil || *user.TeamID != *teamID || user.Role == nil ||
+			(*user.Role != domain.RoleJM && *user.Role != domain.RoleQC) {
+			return nil, domain.NewForbiddenError("only the team's JM and QC can post here")
+		}
+		if !round.ChatEnabled {
+			return nil, domain.NewForbiddenError("chat is disabled for this round")
+		}
+	}
+
+	msg := &domain.ChatMessage{RoundID: roundID, TeamID: teamID, SenderID: &userID, Body: body}
+	if err := s.repo.CreateChatMessage(ctx, msg); err != nil {
+		return nil, err
+	}
+	if teamID == nil {
+		s.audit.record(ctx, AuditChatBroadcast, auditTargetChatMessage, &msg.ID, &roundID, nil, map[string]any{
+			"message_id": msg.ID,
+			"body":       msg.Body,
+		})
+	}
+	s.hub.publish(ChatEvent{Type: ChatEventMessage, RoundID: roundID, TeamID: teamID, Message: msg})
+	return msg, nil
+}
+
+// List returns a channel's messages oldest first with their read receipts: those after
+// afterID, or the latest page when afterID is 0.
+func (s *ChatService) List(ctx context.Context, userID, roundID int64, teamID *int64, afterID int64, limit int) ([]ChatMessageView, error) {
+	if afterID < 0 {
+		return nil, domain.NewValidationError("after_id", "must not be negative")
+	}
+	if limit <= 0 {
+		limit = DefaultChatPageSize
+	}
+	if limit > maxChatPageSize {
+		limit = maxChatPageSize
+	}
+	if _, _, err := s.channel(ctx, userID, roundID, teamID); err != nil {
+		return nil, err
+	}
+
+	msgs, err := s.repo.ListChatMessages(ctx, roundID, teamID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	cursors, err := s.repo.ListChatReadCursors(ctx, roundID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ChatMessageView, 0, len(msgs))
+	for _, m := range msgs {
+		v := ChatMessageView{Message: m, ReadBy: []domain.ChatReadCursor{}}
+		for _, c := range cursors {
+			if c.LastReadMessageID >= m.ID && (m.SenderID == nil || *m.SenderID != c.UserID) {
+				v.ReadBy = append(v.ReadBy, c)
+			}
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// MarkRead records that the user has read a channel up to messageID. Read positions only
+// move forward, so a stale client can't un-read messages.
+func (s *ChatService) MarkRead(ctx context.Context, userID, roundID int64, teamID *int64, messageID int64) (*domain.ChatReadCursor, error) {
+	if messageID <= 0 {
+		return nil, domain.NewValidationError("message_id", "must be positive")
+	}
+	if _, _, err := s.channel(ctx, userID, roundID, teamID); err != nil {
+		return nil, err
+	}
+	cursor, err := s.repo.MarkChatRead(ctx, roundID, teamID, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	s.hub.publish(ChatEvent{Type: ChatEventRead, RoundID: roundID, TeamID: teamID, Read: cursor})
+	return cursor, nil
+}
+
+// Subscribe streams the round's chat events the user may see: everything on the round
+// channel and, for team members and instructors, their team channels. The channel is
+// closed once ctx is done.
+func (s *ChatService) Subscribe(ctx context.Context, userID, roundID int64) (<-chan ChatEvent, error) {
+	if _, _, err := s.channel(ctx, userID, roundID, nil); err != nil {
+		return nil, err
+	}
+	in := s.hub.subscribe()
+	out := make(chan ChatEvent)
+	go func() {
+		defer close(out)
+		defer s.hub.unsubscribe(in)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-in:
+				if e.RoundID != roundID {
+					continue
+				}
+				if e.TeamID != nil {
+					// Reload the user each time: their team may have changed.
+					user, err := s.repo.GetUserByID(ctx, userID)
+					if err != nil {
+						if ctx.Err() == nil {
+							s.log.Warn("chat stream: load user failed", "user_id", userID, "error", err)
+						}
+						if domain.IsNotFound(err) {
+							return
+						}
+						continue
+					}
+					if !canReadTeamChat(user, *e.TeamID) {
+						continue
+					}
+				}
+				select {
+				case out <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// channel loads the user and round and checks the user may read the channel: anyone in
+// the game may read the round channel; a team channel is for its members and instructors.
+func (s *ChatService) channel(ctx context.Context, userID, roundID int64, teamID *int64) (*domain.User, *domain.Round, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if teamID != nil {
+		if _, err := s.repo.GetTeam(ctx, *teamID); err != nil {
+			return nil, nil, err
+		}
+		if !canReadTeamChat(user, *teamID) {
+			return nil, nil, domain.NewForbiddenError("user not on this team")
+		}
+	}
+	return user, round, nil
+}
+
+func isInstructor(u *domain.User) bool {
+	return u.Role != nil && *u.Role == domain.RoleInstructor
+}
+
+func canReadTeamChat(u *domain.User, teamID int64) bool {
+	return isInstructor(u) || (u.TeamID != nil && *u.TeamID == teamID)
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// CumulativeFlow rebuilds, at every interval across the round, how many jokes per team
+// were submitted, in review, rated-rejected, published-unsold and sold. Batches are
+// created already submitted, so there is no drafted band.
+// A zero interval uses defaultFlowBucket. Samples include both window edges.
+func (s *InstructorService) CumulativeFlow(ctx context.Context, roundID int64, interval time.Duration) (*ports.CumulativeFlow, error) {
+	if interval == 0 {
+		interval = defaultFlowBucket
+	}
+	if interval < minFlowBucket {
+		return nil, domain.NewValidationError("interval_seconds", "must be at least 5 seconds")
+	}
+
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	jokes, err := s.repo.ListJokeLifecycles(ctx, roundID)
+	if err != nil {
+		s.log.Error("cumulative flow: list jokes failed", "round_id", roundID, "error", err)
+		return nil, err
+	}
+	events, err := s.repo.ListPurchaseEvents(ctx, roundID)
+	if err != nil {
+		s.log.Error("cumulative flow: list purchase events failed", "round_id", roundID, "error", err)
+		return nil, err
+	}
+
+	var firstActivity time.Time
+	for _, j := range jokes {
+		if firstActivity.IsZero() || j.BatchCreatedAt.Before(firstActivity) {
+			firstActivity = j.BatchCreatedAt
+		}
+	}
+	start, end := flowWindow(round, firstActivity, time.Now())
+	if end.Sub(start) > interval*maxFlowBuckets {
+		return nil, domain.NewValidationError("interval_seconds", "too small for the length of this round")
+	}
+
+	return &ports.CumulativeFlow{
+		RoundID:         roundID,
+		WindowStart:     start,
+		WindowEnd:       end,
+		IntervalSeconds: int(interval / time.Second),
+		Points:          cumulativeFlowPoints(jokes, events, start, end, interval),
+	}, nil
+}
+
+func cumulativeFlowPoints(jokes []ports.JokeLifecycle, events []domain.PurchaseEvent, start, end time.Time, interval time.Duration) []ports.CFDPoint {
+	teamNames := make(map[int64]string)
+	var teamIDs []int64
+	for _, j := range jokes {
+		if _, ok := teamNames[j.TeamID]; !ok {
+			teamNames[j.TeamID] = j.TeamName
+			teamIDs = append(teamIDs, j.TeamID)
+		}
+	}
+	sort.Slice(teamIDs, func(i, j int) bool { return teamIDs[i] < teamIDs[j] })
+
+	var samples []time.Time
+	for t := start; t.Before(end); t = t.Add(interval) {
+		samples = append(samples, t)
+	}
+	samples = append(samples, end)
+
+	// Sweep purchase events forward in time, tracking active purchases per joke.
+	activeSales := make(map[int64]int)
+	nextEvent := 0
+
+	points := make([]ports.CFDPoint, 0, len(samples)*len(teamIDs))
+	for _, t := range samples {
+		for nextEvent < len(events) && !events[nextEvent].CreatedAt.After(t) {
+			activeSales[events[nextEvent].JokeID] += events[nextEvent].Delta
+			nextEvent++
+		}
+
+		byTeam := make(map[int64]*ports.CFDPoint, len(teamIDs))
+		for _, teamID := range teamIDs {
+			byTeam[teamID] = &ports.CFDPoint{Timestamp: t, TeamID: teamID, TeamName: teamNames[teamID]}
+		}
+		for _, j := range jokes {
+			pnt := byTeam[j.TeamID]
+			switch {
+			case j.BatchCreatedAt.After(t), j.SubmittedAt == nil || j.SubmittedAt.After(t):
+				// Not submitted yet.
+			case j.RatedAt == nil || j.RatedAt.After(t):
+				if j.LockedAt != nil && !j.LockedAt.After(t) {
+					pnt.InReview++
+				} else {
+					pnt.Submitted++
+				}
+			case !j.IsPublished:
+				pnt.RatedRejected++
+			case activeSales[j.JokeID] > 0:
+				pnt.Sold++
+			default:
+				pnt.PublishedUnsold++
+			}
+		}
+		for _, teamID := range teamIDs {
+			points = append(points, *byTeam[teamID])
+		}
+	}
+	return points
+}
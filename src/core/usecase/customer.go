@@ -2,12 +2,51 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"math"
+	"strings"
+	"unicode/utf8"
 
 	"jokefactory/src/core/domain"
 	"jokefactory/src/core/ports"
 )
 
+const (
+	maxCustomerReview = 500
+
+	// DefaultMarketPageSize is the market page size when the caller doesn't pick one.
+	DefaultMarketPageSize = 50
+	maxMarketPageSize     = 200
+)
+
+// marketLabels are the team performance labels the market can be filtered by.
+var marketLabels = map[string]bool{
+	"HIGH PERFORMING":    true,
+	"AVERAGE PERFORMING": true,
+	"LOW PERFORMING":     true,
+}
+
+// PurchaseHistory is a customer's buys and returns in a round with a running balance.
+// Reserved is held back by open auction bids. Reconciled reports whether replaying the
+// history from the starting budget, less what is reserved, lands on the stored remaining
+// budget.
+type PurchaseHistory struct {
+	Budget     *domain.CustomerRoundBudget
+	Entries    []ports.PurchaseHistoryEntry
+	Spent      float64
+	Reserved   float64
+	Reconciled bool
+}
+
+// ReturnAllowance is the round's return policy alongside how many returns a customer has
+// made. Left is nil when the policy sets no limit.
+type ReturnAllowance struct {
+	Policy domain.ReturnPolicy
+	Used   int
+	Left   *int
+}
+
 // CustomerService handles market flows.
 type CustomerService struct {
 	repo ports.GameRepository
@@ -18,21 +57,59 @@ func NewCustomerService(repo ports.GameRepository, log *slog.Logger) *CustomerSe
 	return &CustomerService{repo: repo, log: log}
 }
 
-func (s *CustomerService) Market(ctx context.Context, userID, roundID int64) ([]ports.MarketItem, error) {
+// Market returns one page of the round's market, filtered and sorted, with the total
+// number of matching items. Label may be given short ("high") or in full.
+func (s *CustomerService) Market(ctx context.Context, userID, roundID int64, filter ports.MarketFilter, page, perPage int) ([]ports.MarketItem, int64, error) {
+	if page < 1 {
+		return nil, 0, domain.NewValidationError("page", "must be at least 1")
+	}
+	if perPage == 0 {
+		perPage = DefaultMarketPageSize
+	}
+	if perPage < 1 || perPage > maxMarketPageSize {
+		return nil, 0, domain.NewValidationError("per_page", fmt.Sprintf("must be between 1 and %d", maxMarketPageSize))
+	}
+	if filter.Sort == "" {
+		filter.Sort = ports.MarketSortOldest
+	}
+	if !filter.Sort.IsValid() {
+		return nil, 0, domain.NewValidationError("sort", "must be one of oldest, newest, most_bought, team_profit")
+	}
+	filter.Search = strings.TrimSpace(filter.Search)
+	if filter.Label != "" {
+		label := strings.ToUpper(strings.TrimSpace(filter.Label))
+		if !strings.HasSuffix(label, " PERFORMING") {
+			label += " PERFORMING"
+		}
+		if !marketLabels[label] {
+			return nil, 0, domain.NewValidationError("label", "must be HIGH, AVERAGE or LOW")
+		}
+		filter.Label = label
+	}
+	filter.Limit = perPage
+	filter.Offset = (page - 1) * perPage
+
 	if err := s.ensureCustomerOrInstructor(ctx, userID); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	round, err := s.repo.GetRoundByID(ctx, roundID)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if round.Status != domain.RoundActive {
-		return nil, domain.NewConflictError("round not active")
+		return nil, 0, domain.NewConflictError("round not active")
 	}
 	if _, err := s.repo.EnsureCustomerBudget(ctx, roundID, userID, round.CustomerBudget); err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	items, total, err := s.repo.ListMarket(ctx, roundID, userID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range items {
+		items[i].Price = round.JokePrice(items[i].TeamPrice, items[i].PriceState)
 	}
-	return s.repo.ListMarket(ctx, roundID, userID)
+	return items, total, nil
 }
 
 func (s *CustomerService) Budget(ctx context.Context, userID, roundID int64) (*domain.CustomerRoundBudget, error) {
@@ -46,6 +123,22 @@ func (s *CustomerService) Budget(ctx context.Context, userID, roundID int64) (*d
 	return s.repo.EnsureCustomerBudget(ctx, roundID, userID, round.CustomerBudget)
 }
 
+// Returns reports the customer's return allowance in the round.
+func (s *CustomerService) Returns(ctx context.Context, userID, roundID int64) (*ReturnAllowance, error) {
+	if err := s.ensureCustomer(ctx, userID); err != nil {
+		return nil, err
+	}
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.repo.CountCustomerReturns(ctx, roundID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &ReturnAllowance{Policy: round.ReturnPolicy, Used: used, Left: round.ReturnsLeft(used)}, nil
+}
+
 func (s *CustomerService) Buy(ctx context.Context, userID, roundID, jokeID int64) (*domain.Purchase, *domain.CustomerRoundBudget, int64, error) {
 	if err := s.ensureCustomer(ctx, userID); err != nil {
 		return nil, nil, 0, err
@@ -57,10 +150,13 @@ func (s *CustomerService) Buy(ctx context.Context, userID, roundID, jokeID int64
 	if round.Status != domain.RoundActive {
 		return nil, nil, 0, domain.NewConflictError("round not active")
 	}
+	if round.PricingMode == domain.PricingAuction {
+		return nil, nil, 0, domain.NewConflictError("jokes are auctioned this round; place a bid instead")
+	}
 	if _, err := s.repo.EnsureCustomerBudget(ctx, roundID, userID, round.CustomerBudget); err != nil {
 		return nil, nil, 0, err
 	}
-	return s.repo.BuyJoke(ctx, roundID, userID, jokeID, round.MarketPrice)
+	return s.repo.BuyJoke(ctx, roundID, userID, jokeID)
 }
 
 func (s *CustomerService) Return(ctx context.Context, userID, roundID, jokeID int64) (*domain.Purchase, *domain.CustomerRoundBudget, int64, error) {
@@ -77,7 +173,82 @@ func (s *CustomerService) Return(ctx context.Context, userID, roundID, jokeID in
 	if _, err := s.repo.EnsureCustomerBudget(ctx, roundID, userID, round.CustomerBudget); err != nil {
 		return nil, nil, 0, err
 	}
-	return s.repo.ReturnJoke(ctx, roundID, userID, jokeID, round.MarketPrice)
+	return s.repo.ReturnJoke(ctx, roundID, userID, jokeID)
+}
+
+// Rate stores the customer's rating of a joke they currently hold, replacing any earlier
+// one. A blank review is stored as none.
+func (s *CustomerService) Rate(ctx context.Context, userID, roundID, jokeID int64, rating int, review *string) (*domain.CustomerRating, error) {
+	if rating < 1 || rating > 5 {
+		return nil, domain.NewValidationError("rating", "must be between 1 and 5")
+	}
+	if review != nil {
+		trimmed := strings.TrimSpace(*review)
+		if utf8.RuneCountInString(trimmed) > maxCustomerReview {
+			return nil, domain.NewValidationError("review", fmt.Sprintf("must be at most %d characters", maxCustomerReview))
+		}
+		review = &trimmed
+		if trimmed == "" {
+			review = nil
+		}
+	}
+	if err := s.ensureCustomer(ctx, userID); err != nil {
+		return nil, err
+	}
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	if round.Status != domain.RoundActive {
+		return nil, domain.NewConflictError("round not active")
+	}
+
+	cr := &domain.CustomerRating{RoundID: roundID, CustomerUserID: userID, JokeID: jokeID, Rating: rating, Review: review}
+	if err := s.repo.RateJoke(ctx, cr); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// Purchases returns the customer's buy and return history for the round, oldest first.
+// Each entry carries the budget left after it, starting from the round's starting budget.
+func (s *CustomerService) Purchases(ctx context.Context, userID, roundID int64) (*PurchaseHistory, error) {
+	budget, err := s.Budget(ctx, userID, roundID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListCustomerPurchaseHistory(ctx, roundID, userID)
+	if err != nil {
+		return nil, err
+	}
+	bids, err := s.repo.ListCustomerBids(ctx, roundID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var reserved float64
+	for _, b := range bids {
+		if b.Status == domain.BidOpen {
+			reserved += b.Amount
+		}
+	}
+
+	balance := budget.StartingBudget
+	for i := range entries {
+		balance = roundCents(balance - float64(entries[i].Delta)*entries[i].Price)
+		entries[i].Balance = balance
+	}
+	h := &PurchaseHistory{
+		Budget:     budget,
+		Entries:    entries,
+		Spent:      roundCents(budget.StartingBudget - balance),
+		Reserved:   roundCents(reserved),
+		Reconciled: math.Abs(balance-reserved-budget.RemainingBudget) < 0.005,
+	}
+	if !h.Reconciled {
+		s.log.Warn("purchase history does not reconcile with budget",
+			"round_id", roundID, "user_id", userID, "history_balance", balance, "reserved", reserved, "remaining_budget", budget.RemainingBudget)
+	}
+	return h, nil
 }
 
 func (s *CustomerService) ensureCustomer(ctx context.Context, userID int64) error {
@@ -103,3 +274,7 @@ func (s *CustomerService) ensureCustomerOrInstructor(ctx context.Context, userID
 	return nil
 }
 
+// roundCents rounds an amount of money to whole cents.
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// ExportService streams raw game data for offline analysis.
+type ExportService struct {
+	repo ports.GameRepository
+	log  *slog.Logger
+}
+
+func NewExportService(repo ports.GameRepository, log *slog.Logger) *ExportService {
+	return &ExportService{repo: repo, log: log}
+}
+
+// ParseEntity validates an export entity name.
+func (s *ExportService) ParseEntity(name string) (ports.ExportEntity, error) {
+	for _, e := range ports.ExportEntities {
+		if string(e) == name {
+			return e, nil
+		}
+	}
+	return "", domain.NewValidationError("entity", "unknown export entity")
+}
+
+// Export streams one entity: the header row first, then every matching row.
+// The leaderboard is per round, so it requires filter.RoundID.
+func (s *ExportService) Export(ctx context.Context, entity ports.ExportEntity, filter ports.ExportFilter, emit ports.ExportRowFunc) error {
+	if entity == ports.ExportLeaderboard {
+		return s.exportLeaderboard(ctx, filter, emit)
+	}
+	if err := s.repo.StreamExport(ctx, entity, filter, emit); err != nil {
+		s.log.Error("export failed", "entity", entity, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *ExportService) exportLeaderboard(ctx context.Context, filter ports.ExportFilter, emit ports.ExportRowFunc) error {
+	if filter.RoundID == nil {
+		return domain.NewValidationError("round_id", "required for the leaderboard export")
+	}
+	stats, err := s.repo.GetRoundStats(ctx, *filter.RoundID)
+	if err != nil {
+		return err
+	}
+	if err := emit([]string{
+		"round_id", "rank", "team_id", "team_name", "batches_rated", "total_sales", "accepted_jokes",
+		"unaccepted_jokes", "avg_score_overall", "total_jokes", "unsold_jokes", "profit",
+	}); err != nil {
+		return err
+	}
+	roundID := strconv.FormatInt(*filter.RoundID, 10)
+	for _, ts := range stats {
+		if filter.TeamID != nil && ts.Team.ID != *filter.TeamID {
+			continue
+		}
+		if err := emit([]string{
+			roundID,
+			strconv.Itoa(ts.Rank),
+			strconv.FormatInt(ts.Team.ID, 10),
+			ts.Team.Name,
+			strconv.Itoa(ts.BatchesRated),
+			strconv.Itoa(ts.TotalSales),
+			strconv.Itoa(ts.AcceptedJokes),
+			strconv.Itoa(ts.UnacceptedJokes),
+			strconv.FormatFloat(ts.AvgScoreOverall, 'f', -1, 64),
+			strconv.Itoa(ts.TotalJokes),
+			strconv.Itoa(ts.UnsoldJokes),
+			strconv.FormatFloat(ts.Profit, 'f', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+const (
+	// defaultFlowBucket is the time-series resolution when the caller doesn't pick one.
+	defaultFlowBucket = time.Minute
+	// minFlowBucket and maxFlowBuckets keep the time series a sensible size.
+	minFlowBucket  = 5 * time.Second
+	maxFlowBuckets = 2000
+)
+
+// FlowMetrics computes lean flow metrics for a round: per-batch wait time (submitted to
+// locked by a QC), rating time (locked to rated) and lead time (submitted to rated),
+// plus per-team throughput, time-weighted average WIP and a bucketed time series.
+//
+// The window runs from the round start (or first submission) to the round end (or now
+// while the round is still running). A zero bucket uses defaultFlowBucket.
+func (s *InstructorService) FlowMetrics(ctx context.Context, roundID int64, bucket time.Duration) (*ports.FlowMetrics, error) {
+	if bucket == 0 {
+		bucket = defaultFlowBucket
+	}
+	if bucket < minFlowBucket {
+		return nil, domain.NewValidationError("bucket_seconds", "must be at least 5 seconds")
+	}
+
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := s.repo.ListBatchFlow(ctx, roundID)
+	if err != nil {
+		s.log.Error("flow metrics: list batches failed", "round_id", roundID, "error", err)
+		return nil, err
+	}
+
+	var firstActivity time.Time
+	if len(batches) > 0 {
+		firstActivity = batches[0].SubmittedAt
+	}
+	start, end := flowWindow(round, firstActivity, time.Now())
+	if end.Sub(start) > bucket*maxFlowBuckets {
+		return nil, domain.NewValidationError("bucket_seconds", "too small for the length of this round")
+	}
+
+	for i := range batches {
+		fillBatchDurations(&batches[i])
+	}
+
+	return &ports.FlowMetrics{
+		RoundID:       roundID,
+		WindowStart:   start,
+		WindowEnd:     end,
+		BucketSeconds: int(bucket / time.Second),
+		Teams:         teamFlowMetrics(batches, start, end),
+		Batches:       batches,
+		TimeSeries:    flowTimeSeries(batches, start, end, bucket),
+	}, nil
+}
+
+// flowWindow picks the interval flow charts are measured over: round start (or the first
+// activity when the round never started; zero if none) to round end (or now).
+func flowWindow(round *domain.Round, firstActivity, now time.Time) (time.Time, time.Time) {
+	end := now
+	if round.EndedAt != nil {
+		end = *round.EndedAt
+	}
+	start := end
+	switch {
+	case round.StartedAt != nil:
+		start = *round.StartedAt
+	case !firstActivity.IsZero():
+		start = firstActivity
+	}
+	if end.Before(start) {
+		end = start
+	}
+	return start, end
+}
+
+func fillBatchDurations(b *ports.BatchFlow) {
+	if b.LockedAt != nil {
+		wait := b.LockedAt.Sub(b.SubmittedAt).Seconds()
+		b.WaitSeconds = &wait
+	}
+	if b.RatedAt != nil {
+		lead := b.RatedAt.Sub(b.SubmittedAt).Seconds()
+		b.LeadTimeSeconds = &lead
+		if b.LockedAt != nil {
+			rating := b.RatedAt.Sub(*b.LockedAt).Seconds()
+			b.RatingSeconds = &rating
+		}
+	}
+}
+
+// teamFlowMetrics aggregates batches per team, ordered by team id.
+func teamFlowMetrics(batches []ports.BatchFlow, start, end time.Time) []ports.TeamFlowMetrics {
+	window := end.Sub(start)
+	byTeam := make(map[int64]*ports.TeamFlowMetrics)
+	waits := make(map[int64][]float64)
+	ratings := make(map[int64][]float64)
+	leads := make(map[int64][]float64)
+	// Time each team spent holding unrated batches/jokes, for time-weighted WIP.
+	batchTime := make(map[int64]time.Duration)
+	jokeTime := make(map[int64]float64)
+
+	for _, b := range batches {
+		tm, ok := byTeam[b.TeamID]
+		if !ok {
+			tm = &ports.TeamFlowMetrics{TeamID: b.TeamID, TeamName: b.TeamName}
+			byTeam[b.TeamID] = tm
+		}
+		tm.BatchesSubmitted++
+		if b.WaitSeconds != nil {
+			waits[b.TeamID] = append(waits[b.TeamID], *b.WaitSeconds)
+		}
+		if b.RatingSeconds != nil {
+			ratings[b.TeamID] = append(ratings[b.TeamID], *b.RatingSeconds)
+		}
+		if b.LeadTimeSeconds != nil {
+			leads[b.TeamID] = append(leads[b.TeamID], *b.LeadTimeSeconds)
+		}
+		if b.RatedAt != nil && !b.RatedAt.Before(start) && !b.RatedAt.After(end) {
+			tm.BatchesRated++
+			tm.JokesRated += b.JokesCount
+		}
+
+		queuedUntil := end
+		if b.RatedAt != nil && b.RatedAt.Before(end) {
+			queuedUntil = *b.RatedAt
+		}
+		queuedFrom := b.SubmittedAt
+		if queuedFrom.Before(start) {
+			queuedFrom = start
+		}
+		if queued := queuedUntil.Sub(queuedFrom); queued > 0 {
+			batchTime[b.TeamID] += queued
+			jokeTime[b.TeamID] += queued.Seconds() * float64(b.JokesCount)
+		}
+	}
+
+	out := make([]ports.TeamFlowMetrics, 0, len(byTeam))
+	for teamID, tm := range byTeam {
+		tm.WaitTime = summarizeDurations(waits[teamID])
+		tm.RatingTime = summarizeDurations(ratings[teamID])
+		tm.LeadTime = summarizeDurations(leads[teamID])
+		if window > 0 {
+			tm.ThroughputJokesPerMinute = float64(tm.JokesRated) / window.Minutes()
+			tm.ThroughputBatchesPerMinute = float64(tm.BatchesRated) / window.Minutes()
+			tm.AvgWIPBatches = batchTime[teamID].Seconds() / window.Seconds()
+			tm.AvgWIPJokes = jokeTime[teamID] / window.Seconds()
+		}
+		out = append(out, *tm)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TeamID < out[j].TeamID })
+	return out
+}
+
+// flowTimeSeries reports, per bucket and team, WIP at the bucket end and jokes rated within it.
+func flowTimeSeries(batches []ports.BatchFlow, start, end time.Time, bucket time.Duration) []ports.FlowPoint {
+	teamNames := make(map[int64]string)
+	var teamIDs []int64
+	for _, b := range batches {
+		if _, ok := teamNames[b.TeamID]; !ok {
+			teamNames[b.TeamID] = b.TeamName
+			teamIDs = append(teamIDs, b.TeamID)
+		}
+	}
+	sort.Slice(teamIDs, func(i, j int) bool { return teamIDs[i] < teamIDs[j] })
+
+	var points []ports.FlowPoint
+	for bs := start; bs.Before(end); bs = bs.Add(bucket) {
+		be := bs.Add(bucket)
+		if be.After(end) {
+			be = end
+		}
+		for _, teamID := range teamIDs {
+			pnt := ports.FlowPoint{BucketStart: bs, TeamID: teamID, TeamName: teamNames[teamID]}
+			for _, b := range batches {
+				if b.TeamID != teamID {
+					continue
+				}
+				if b.RatedAt != nil && !b.RatedAt.Before(bs) && b.RatedAt.Before(be) {
+					pnt.RatedJokes += b.JokesCount
+				}
+				if !b.SubmittedAt.After(be) && (b.RatedAt == nil || b.RatedAt.After(be)) {
+					pnt.WIPBatches++
+					pnt.WIPJokes += b.JokesCount
+				}
+			}
+			if minutes := be.Sub(bs).Minutes(); minutes > 0 {
+				pnt.ThroughputPerMinute = float64(pnt.RatedJokes) / minutes
+			}
+			points = append(points, pnt)
+		}
+	}
+	return points
+}
+
+func summarizeDurations(values []float64) ports.DurationStats {
+	if len(values) == 0 {
+		return ports.DurationStats{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	return ports.DurationStats{
+		Count: len(sorted),
+		Mean:  sum / float64(len(sorted)),
+		Min:   sorted[0],
+		P50:   percentile(sorted, 0.5),
+		P90:   percentile(sorted, 0.9),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p (0..1] of an ascending slice.
+func percentile(sorted []float64, p float64) float64 {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
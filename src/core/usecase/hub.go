@@ -0,0 +1,43 @@
+package usecase
+
+import "sync"
+
+// hub fans events out to open streams. It is in-process, so when several API instances
+// run, a stream only sees events published on its own instance.
+type hub[T any] struct {
+	mu     sync.Mutex
+	buffer int
+	subs   map[chan T]struct{}
+}
+
+// newHub returns a hub whose subscribers may fall buffer events behind before further
+// ones are dropped for them.
+func newHub[T any](buffer int) *hub[T] {
+	return &hub[T]{buffer: buffer, subs: make(map[chan T]struct{})}
+}
+
+func (h *hub[T]) subscribe() chan T {
+	ch := make(chan T, h.buffer)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *hub[T]) unsubscribe(ch chan T) {
+	h.mu.Lock()
+	delete(h.subs, ch)
+	h.mu.Unlock()
+}
+
+// publish never blocks: a stream whose buffer is full misses the event.
+func (h *hub[T]) publish(v T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- v:
+		default:
+		}
+	}
+}
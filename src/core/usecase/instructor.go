@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"math/rand"
 	"time"
@@ -12,16 +13,37 @@ import (
 
 // InstructorService handles instructor endpoints.
 type InstructorService struct {
-	repo ports.GameRepository
-	log  *slog.Logger
+	repo       ports.GameRepository
+	log        *slog.Logger
+	audit      auditLog
+	presence   domain.PresencePolicy
+	admissions admissions
 }
 
-func NewInstructorService(repo ports.GameRepository, log *slog.Logger) *InstructorService {
-	return &InstructorService{repo: repo, log: log}
+func NewInstructorService(repo ports.GameRepository, log *slog.Logger, presence domain.PresencePolicy) *InstructorService {
+	return &InstructorService{
+		repo:       repo,
+		log:        log,
+		audit:      auditLog{repo: repo, log: log},
+		presence:   presence,
+		admissions: admissions{repo: repo, log: log, presence: presence},
+	}
 }
 
+// Lobby returns the lobby. It only reads: admissions run on join, when the instructor
+// changes the cap or the late-joiner policy, and after a user is deleted or merged.
 func (s *InstructorService) Lobby(ctx context.Context, roundID int64) (*ports.LobbySnapshot, error) {
-	return s.repo.GetLobby(ctx, roundID)
+	return s.lobby(ctx, roundID)
+}
+
+// lobby returns the lobby with each participant's current presence.
+func (s *InstructorService) lobby(ctx context.Context, roundID int64) (*ports.LobbySnapshot, error) {
+	lobby, err := s.repo.GetLobby(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	annotatePresence(lobby, s.presence, time.Now())
+	return lobby, nil
 }
 
 // GetRound returns a round by id.
@@ -30,11 +52,32 @@ func (s *InstructorService) GetRound(ctx context.Context, roundID int64) (*domai
 }
 
 func (s *InstructorService) InsertConfig(ctx context.Context, roundID int64, customerBudget, batchSize int, marketPrice, costOfPublishing float64) (*domain.Round, error) {
-	return s.repo.InsertRoundConfig(ctx, roundID, customerBudget, batchSize, marketPrice, costOfPublishing)
+	return s.auditRoundChange(ctx, AuditRoundConfig, roundID, func() (*domain.Round, error) {
+		return s.repo.InsertRoundConfig(ctx, roundID, customerBudget, batchSize, marketPrice, costOfPublishing)
+	})
+}
+
+// auditRoundChange runs a round mutation and records the round before and after it.
+func (s *InstructorService) auditRoundChange(ctx context.Context, action string, roundID int64, mutate func() (*domain.Round, error)) (*domain.Round, error) {
+	before, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil && !domain.IsNotFound(err) {
+		return nil, err
+	}
+	after, err := mutate()
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, action, auditTargetRound, &roundID, &roundID, auditRound(before), auditRound(after))
+	return after, nil
 }
 
-// Assign auto-assigns waiting participants into JM/QC/Customer roles.
+// Assign auto-assigns waiting participants into JM/QC/Customer roles, honouring roster
+// team/role preferences where a matching seat is free.
 func (s *InstructorService) Assign(ctx context.Context, roundID int64, customerCount, teamCount int) (*ports.LobbySnapshot, error) {
+	before, err := s.lobby(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
 	teams, err := s.repo.EnsureTeamCount(ctx, teamCount)
 	if err != nil {
 		return nil, err
@@ -52,6 +95,15 @@ func (s *InstructorService) Assign(ctx context.Context, roundID int64, customerC
 
 	participants := append(waiting, assigned...)
 
+	participantIDs := make([]int64, 0, len(participants))
+	for _, u := range participants {
+		participantIDs = append(participantIDs, u.ID)
+	}
+	undo, err := s.beginLobbyUndo(ctx, roundID, AuditRoundAssign, participantIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(participants) > 1 {
 		r := rand.New(rand.NewSource(time.Now().UnixNano()))
 		r.Shuffle(len(participants), func(i, j int) {
@@ -59,45 +111,72 @@ func (s *InstructorService) Assign(ctx context.Context, roundID int64, customerC
 		})
 	}
 
-	assignIdx := 0
-	assign := func(role domain.Role, teamID *int64) error {
-		if assignIdx >= len(participants) {
-			return nil
+	// Seats in fill order: one JM + one QC per team, then the customers.
+	type seat struct {
+		role   domain.Role
+		teamID *int64
+		teamNo int // 1-based position of the team, 0 for customers
+		user   *domain.User
+	}
+	var seats []seat
+	for i, team := range teams {
+		tid := team.ID
+		seats = append(seats, seat{role: domain.RoleJM, teamID: &tid, teamNo: i + 1}, seat{role: domain.RoleQC, teamID: &tid, teamNo: i + 1})
+	}
+	for i := 0; i < customerCount; i++ {
+		seats = append(seats, seat{role: domain.RoleCustomer})
+	}
+
+	// Roster preferences are honoured first (where a matching seat is free); everyone
+	// else fills the remaining seats in random order.
+	prefs, err := s.rosterPreferences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var rest []*domain.User
+	for i := range participants {
+		u := &participants[i]
+		placed := false
+		if p, ok := prefs[u.ID]; ok {
+			for j := range seats {
+				sl := &seats[j]
+				if sl.user == nil && (p.PreferredRole == nil || *p.PreferredRole == sl.role) && (p.PreferredTeam == nil || *p.PreferredTeam == sl.teamNo) {
+					sl.user = u
+					placed = true
+					break
+				}
+			}
 		}
-		u := participants[assignIdx]
-		assignIdx++
-		if err := s.repo.UpdateUserAssignment(ctx, u.ID, &role, teamID); err != nil {
-			return err
+		if !placed {
+			rest = append(rest, u)
 		}
-		if err := s.repo.MarkUserAssigned(ctx, u.ID); err != nil {
-			return err
+	}
+	for j := range seats {
+		if seats[j].user == nil && len(rest) > 0 {
+			seats[j].user, rest = rest[0], rest[1:]
 		}
-		return nil
 	}
 
-	// One JM + one QC per team (best-effort with available waiting users)
-	for _, team := range teams {
-		tid := team.ID
-		if err := assign(domain.RoleJM, &tid); err != nil {
-			return nil, err
+	for _, sl := range seats {
+		if sl.user == nil {
+			continue
 		}
-		if err := assign(domain.RoleQC, &tid); err != nil {
+		role := sl.role
+		if err := s.repo.UpdateUserAssignment(ctx, sl.user.ID, &role, sl.teamID); err != nil {
 			return nil, err
 		}
-		if err := s.repo.EnsureTeamRoundState(ctx, roundID, tid); err != nil {
+		if err := s.repo.MarkUserAssigned(ctx, sl.user.ID); err != nil {
 			return nil, err
 		}
 	}
-
-	for i := 0; i < customerCount; i++ {
-		if err := assign(domain.RoleCustomer, nil); err != nil {
+	for _, team := range teams {
+		if err := s.repo.EnsureTeamRoundState(ctx, roundID, team.ID); err != nil {
 			return nil, err
 		}
 	}
 
 	// Any remaining participants should return to waiting with no assignment.
-	for ; assignIdx < len(participants); assignIdx++ {
-		u := participants[assignIdx]
+	for _, u := range rest {
 		if err := s.repo.UpdateUserAssignment(ctx, u.ID, nil, nil); err != nil {
 			return nil, err
 		}
@@ -106,7 +185,14 @@ func (s *InstructorService) Assign(ctx context.Context, roundID int64, customerC
 		}
 	}
 
-	return s.repo.GetLobby(ctx, roundID)
+	s.commitLobbyUndo(ctx, undo)
+
+	after, err := s.lobby(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditRoundAssign, auditTargetRound, &roundID, &roundID, auditLobby(before), auditLobby(after))
+	return after, nil
 }
 
 func (s *InstructorService) PatchUser(ctx context.Context, roundID, userID int64, status domain.ParticipantStatus, role *domain.Role, teamID *int64) (*ports.LobbySnapshot, error) {
@@ -151,30 +237,203 @@ func (s *InstructorService) PatchUser(ctx context.Context, roundID, userID int64
 		}
 	}
 
+	undo, err := s.beginLobbyUndo(ctx, roundID, AuditUserPatch, []int64{userID})
+	if err != nil {
+		return nil, err
+	}
 	// Atomic update in repo (also syncs customer_round_budget when role changes to/from CUSTOMER)
 	if err := s.repo.PatchUserInRound(ctx, roundID, userID, status, desiredRole, desiredTeamID); err != nil {
 		return nil, err
 	}
-	return s.repo.GetLobby(ctx, roundID)
+	s.commitLobbyUndo(ctx, undo)
+	if updated, err := s.repo.GetUserByID(ctx, userID); err == nil {
+		s.audit.record(ctx, AuditUserPatch, auditTargetUser, &userID, &roundID, auditUser(existing), auditUser(updated))
+	} else {
+		s.log.Error("patch user: reload for audit failed", "user_id", userID, "error", err)
+	}
+	return s.lobby(ctx, roundID)
 }
 
 func (s *InstructorService) StartRound(ctx context.Context, roundID int64) (*domain.Round, error) {
 	// Start without updating budget/batch is no longer used; see StartRoundWithConfig.
-	return s.repo.StartRound(ctx, roundID, 0, 1, 1, 0.1)
+	return s.auditRoundChange(ctx, AuditRoundStart, roundID, func() (*domain.Round, error) {
+		return s.repo.StartRound(ctx, roundID, 0, 1, 1, 0.1)
+	})
 }
 
 func (s *InstructorService) EndRound(ctx context.Context, roundID int64) (*domain.Round, error) {
-	return s.repo.EndRound(ctx, roundID)
+	return s.auditRoundChange(ctx, AuditRoundEnd, roundID, func() (*domain.Round, error) {
+		return s.repo.EndRound(ctx, roundID)
+	})
 }
 
 // SetPopupState toggles whether popups are active for a round.
 func (s *InstructorService) SetPopupState(ctx context.Context, roundID int64, isActive bool) (*domain.Round, error) {
-	return s.repo.SetRoundPopupState(ctx, roundID, isActive)
+	return s.auditRoundChange(ctx, AuditRoundPopups, roundID, func() (*domain.Round, error) {
+		return s.repo.SetRoundPopupState(ctx, roundID, isActive)
+	})
+}
+
+// SetQCRoutingMode changes how QC queues are routed for a round.
+func (s *InstructorService) SetQCRoutingMode(ctx context.Context, roundID int64, mode domain.QCRoutingMode) (*domain.Round, error) {
+	if !mode.IsValid() {
+		return nil, domain.NewValidationError("qc_routing_mode", "must be one of OWN_TEAM, ROTATION, GLOBAL_POOL")
+	}
+	return s.auditRoundChange(ctx, AuditRoundQCRouting, roundID, func() (*domain.Round, error) {
+		return s.repo.SetRoundQCRoutingMode(ctx, roundID, mode)
+	})
+}
+
+// SetLateJoinerPolicy decides what happens to participants joining after the round's teams
+// have been assigned. Late joiners already waiting are seated under the new policy now.
+func (s *InstructorService) SetLateJoinerPolicy(ctx context.Context, roundID int64, policy domain.LateJoinerPolicy) (*domain.Round, error) {
+	if !policy.IsValid() {
+		return nil, domain.NewValidationError("late_joiner_policy", "must be one of WAIT, CUSTOMER, SMALLEST_TEAM")
+	}
+	round, err := s.auditRoundChange(ctx, AuditRoundLateJoiners, roundID, func() (*domain.Round, error) {
+		return s.repo.SetRoundLateJoinerPolicy(ctx, roundID, policy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.admissions.apply(ctx, round); err != nil {
+		s.log.Error("late joiner policy: admissions failed", "round_id", roundID, "error", err)
+	}
+	return round, nil
+}
+
+// SetPricing picks how a round's jokes are priced. Team pricing needs both bounds; prices
+// teams have already set are kept, and sell clamped to the new bounds. Dynamic pricing
+// starts every joke on sale from the market price when it is switched on; changing the
+// step or decay later reshapes the curve from each joke's current price. Auctions open
+// bidding on jokes already on sale when switched on; an empty rule and zero window take
+// the defaults (first price, one minute).
+func (s *InstructorService) SetPricing(ctx context.Context, roundID int64, p domain.RoundPricing) (*domain.Round, error) {
+	if !p.PricingMode.IsValid() {
+		return nil, domain.NewValidationError("pricing_mode", "must be FLAT, TEAM_SET, DYNAMIC or AUCTION")
+	}
+	if p.MinJokePrice != nil && *p.MinJokePrice < 0 {
+		return nil, domain.NewValidationError("min_price", "must not be negative")
+	}
+	if p.MaxJokePrice != nil && *p.MaxJokePrice < 0 {
+		return nil, domain.NewValidationError("max_price", "must not be negative")
+	}
+	if p.MinJokePrice != nil && p.MaxJokePrice != nil && *p.MinJokePrice > *p.MaxJokePrice {
+		return nil, domain.NewValidationError("max_price", "must not be below min_price")
+	}
+	if p.PricingMode == domain.PricingTeamSet && (p.MinJokePrice == nil || p.MaxJokePrice == nil) {
+		return nil, domain.NewValidationError("min_price", "team pricing needs both min_price and max_price")
+	}
+	if p.PriceStep < 0 {
+		return nil, domain.NewValidationError("price_step", "must not be negative")
+	}
+	if p.PriceDecay < 0 {
+		return nil, domain.NewValidationError("price_decay", "must not be negative")
+	}
+	if p.AuctionRule == "" {
+		p.AuctionRule = domain.AuctionFirstPrice
+	}
+	if !p.AuctionRule.IsValid() {
+		return nil, domain.NewValidationError("auction_rule", "must be FIRST_PRICE or SECOND_PRICE")
+	}
+	if p.AuctionWindowSeconds == 0 {
+		p.AuctionWindowSeconds = defaultAuctionWindowSeconds
+	}
+	if p.AuctionWindowSeconds < minAuctionWindowSeconds || p.AuctionWindowSeconds > maxAuctionWindowSeconds {
+		return nil, domain.NewValidationError("auction_window_seconds", fmt.Sprintf("must be between %d and %d", minAuctionWindowSeconds, maxAuctionWindowSeconds))
+	}
+	return s.auditRoundChange(ctx, AuditRoundPricing, roundID, func() (*domain.Round, error) {
+		return s.repo.SetRoundPricing(ctx, roundID, p)
+	})
+}
+
+// defaultRefundPercent is what a return pays back when the instructor doesn't say.
+const defaultRefundPercent = 100
+
+// SetReturnPolicy sets whether and how customers may return purchases in a round. The
+// policy applies to returns from now on, including of jokes bought before the change.
+// p.RefundPercent is taken from refundPercent, which defaults to a full refund when nil.
+func (s *InstructorService) SetReturnPolicy(ctx context.Context, roundID int64, p domain.ReturnPolicy, refundPercent *int) (*domain.Round, error) {
+	p.RefundPercent = defaultRefundPercent
+	if refundPercent != nil {
+		p.RefundPercent = *refundPercent
+	}
+	if p.ReturnWindowSeconds != nil && *p.ReturnWindowSeconds < 1 {
+		return nil, domain.NewValidationError("return_window_seconds", "must be at least 1")
+	}
+	if p.MaxReturns != nil && *p.MaxReturns < 0 {
+		return nil, domain.NewValidationError("max_returns", "must not be negative")
+	}
+	if p.RefundPercent < 0 || p.RefundPercent > 100 {
+		return nil, domain.NewValidationError("refund_percent", "must be between 0 and 100")
+	}
+	return s.auditRoundChange(ctx, AuditRoundReturns, roundID, func() (*domain.Round, error) {
+		return s.repo.SetRoundReturnPolicy(ctx, roundID, p)
+	})
+}
+
+// SetChatEnabled turns team chat on or off for a round. The round channel stays open
+// either way so the instructor can still reach everyone.
+func (s *InstructorService) SetChatEnabled(ctx context.Context, roundID int64, enabled bool) (*domain.Round, error) {
+	return s.auditRoundChange(ctx, AuditRoundChat, roundID, func() (*domain.Round, error) {
+		return s.repo.SetRoundChatEnabled(ctx, roundID, enabled)
+	})
+}
+
+// SetParticipantCap limits how many students can be in the game (nil removes the cap).
+// Lowering it never evicts anyone; raising it lets waitlisted participants in.
+func (s *InstructorService) SetParticipantCap(ctx context.Context, maxParticipants *int) (*domain.GameSettings, error) {
+	if maxParticipants != nil && *maxParticipants < 1 {
+		return nil, domain.NewValidationError("max_participants", "must be at least 1")
+	}
+	settings, err := s.repo.GetGameSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	before := *settings
+	settings.MaxParticipants = maxParticipants
+	if err := s.repo.UpdateGameSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditGameSettings, auditTargetGame, nil, nil, before, *settings)
+	if err := s.admissions.applyActive(ctx); err != nil {
+		s.log.Error("participant cap: admissions failed", "error", err)
+	}
+	return settings, nil
+}
+
+// SetWIPLimits sets the per-team limits on unrated batches/jokes; nil clears a limit.
+func (s *InstructorService) SetWIPLimits(ctx context.Context, roundID int64, maxUnratedBatches, maxUnratedJokes *int) (*domain.Round, error) {
+	if maxUnratedBatches != nil && *maxUnratedBatches < 1 {
+		return nil, domain.NewValidationError("max_unrated_batches", "must be at least 1")
+	}
+	if maxUnratedJokes != nil && *maxUnratedJokes < 1 {
+		return nil, domain.NewValidationError("max_unrated_jokes", "must be at least 1")
+	}
+	return s.auditRoundChange(ctx, AuditRoundWIPLimits, roundID, func() (*domain.Round, error) {
+		return s.repo.SetRoundWIPLimits(ctx, roundID, maxUnratedBatches, maxUnratedJokes)
+	})
+}
+
+// SetMarketPrice changes a round's market price, leaving the rest of its config as is.
+func (s *InstructorService) SetMarketPrice(ctx context.Context, roundID int64, marketPrice float64) (*domain.Round, error) {
+	if marketPrice <= 0 {
+		return nil, domain.NewValidationError("market_price", "must be positive")
+	}
+	return s.auditRoundChange(ctx, AuditRoundConfig, roundID, func() (*domain.Round, error) {
+		rd, err := s.repo.GetRoundByID(ctx, roundID)
+		if err != nil {
+			return nil, err
+		}
+		return s.repo.UpdateRoundConfig(ctx, roundID, rd.CustomerBudget, rd.BatchSize, marketPrice, rd.CostOfPublishing)
+	})
 }
 
 // StartRoundWithConfig activates a round with provided configuration.
 func (s *InstructorService) StartRoundWithConfig(ctx context.Context, roundID int64, customerBudget, batchSize int, marketPrice, costOfPublishing float64) (*domain.Round, error) {
-	return s.repo.StartRound(ctx, roundID, customerBudget, batchSize, marketPrice, costOfPublishing)
+	return s.auditRoundChange(ctx, AuditRoundStart, roundID, func() (*domain.Round, error) {
+		return s.repo.StartRound(ctx, roundID, customerBudget, batchSize, marketPrice, costOfPublishing)
+	})
 }
 
 func (s *InstructorService) Stats(ctx context.Context, roundID int64) (*ports.RoundStats, error) {
@@ -186,7 +445,65 @@ func (s *InstructorService) Stats(ctx context.Context, roundID int64) (*ports.Ro
 	return stats, nil
 }
 
-// DeleteUser removes a non-instructor user from the database.
-func (s *InstructorService) DeleteUser(ctx context.Context, roundID, userID int64) error {
-	return s.repo.DeleteUser(ctx, userID)
+// DeleteUser soft-deletes a non-instructor user: they drop out of the lobby and game
+// flows, but their history still counts in stats.
+func (s *InstructorService) DeleteUser(ctx context.Context, roundID, userID, removedBy int64) error {
+	existing, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	undo, err := s.beginLobbyUndo(ctx, roundID, AuditUserDelete, []int64{userID})
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteUser(ctx, userID, removedBy); err != nil {
+		return err
+	}
+	s.commitLobbyUndo(ctx, undo)
+	s.audit.record(ctx, AuditUserDelete, auditTargetUser, &userID, &roundID, auditUser(existing), nil)
+	if err := s.admissions.applyActive(ctx); err != nil {
+		s.log.Error("delete user: admissions failed", "user_id", userID, "error", err)
+	}
+	return nil
+}
+
+// RestoreUser brings a soft-deleted user back with the role and team they had.
+func (s *InstructorService) RestoreUser(ctx context.Context, roundID, userID int64) (*ports.LobbySnapshot, error) {
+	undo, err := s.beginLobbyUndo(ctx, roundID, AuditUserRestore, []int64{userID})
+	if err != nil {
+		return nil, err
+	}
+	restored, err := s.repo.RestoreUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.commitLobbyUndo(ctx, undo)
+	s.audit.record(ctx, AuditUserRestore, auditTargetUser, &userID, &roundID, nil, auditUser(restored))
+	return s.lobby(ctx, roundID)
+}
+
+// MergeUsers folds an accidental duplicate (source) into the real user (target). The
+// duplicate's history moves to the target and its rejoin secret resolves to the target.
+func (s *InstructorService) MergeUsers(ctx context.Context, sourceID, targetID, mergedBy int64) (*domain.User, error) {
+	if sourceID == targetID {
+		return nil, domain.NewValidationError("target_user_id", "must differ from source_user_id")
+	}
+	source, err := s.repo.GetUserByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.repo.GetUserByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := s.repo.MergeUsers(ctx, sourceID, targetID, mergedBy)
+	if err != nil {
+		return nil, err
+	}
+	before := map[string]any{"source": auditUser(source), "target": auditUser(target)}
+	s.audit.record(ctx, AuditUserMerge, auditTargetUser, &targetID, nil, before, map[string]any{"target": auditUser(merged)})
+	if err := s.admissions.applyActive(ctx); err != nil {
+		s.log.Error("merge users: admissions failed", "source_user_id", sourceID, "error", err)
+	}
+	return merged, nil
 }
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// maxLobbyUndoListed bounds how much of the undo stack ListLobbyUndo returns.
+const maxLobbyUndoListed = 20
+
+// lobbyUndo tracks one lobby mutation on its way onto the round's undo stack.
+type lobbyUndo struct {
+	roundID int64
+	action  string
+	userIDs []int64
+	before  []ports.LobbyUserState
+}
+
+// beginLobbyUndo captures the users a lobby mutation is about to touch. It returns nil
+// (nothing to record) once the round has started, since undo is only offered before that.
+func (s *InstructorService) beginLobbyUndo(ctx context.Context, roundID int64, action string, userIDs []int64) (*lobbyUndo, error) {
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		if domain.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if round.Status != domain.RoundConfigured || len(userIDs) == 0 {
+		return nil, nil
+	}
+	before, err := s.repo.CaptureLobbyUsers(ctx, roundID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &lobbyUndo{roundID: roundID, action: action, userIDs: userIDs, before: before}, nil
+}
+
+// commitLobbyUndo captures the users again after the mutation and pushes the entry.
+// Failures are logged: the mutation itself succeeded, it just won't be undoable.
+func (s *InstructorService) commitLobbyUndo(ctx context.Context, u *lobbyUndo) {
+	if u == nil {
+		return
+	}
+	after, err := s.repo.CaptureLobbyUsers(ctx, u.roundID, u.userIDs)
+	if err == nil {
+		err = s.repo.PushLobbyUndo(ctx, &ports.LobbyUndoEntry{
+			RoundID:     u.roundID,
+			Action:      u.action,
+			UsersBefore: u.before,
+			UsersAfter:  after,
+		})
+	}
+	if err != nil {
+		s.log.Error("record lobby undo failed", "round_id", u.roundID, "action", u.action, "error", err)
+	}
+}
+
+// UndoLobby reverts the round's most recent lobby operation (assign, user patch, delete,
+// restore or replace) and returns it with the resulting lobby. Teams created by an undone Assign are
+// left in place, empty.
+func (s *InstructorService) UndoLobby(ctx context.Context, roundID int64) (*ports.LobbyUndoEntry, *ports.LobbySnapshot, error) {
+	entry, err := s.repo.UndoLobbyChange(ctx, roundID)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.audit.record(ctx, AuditLobbyUndo, auditTargetRound, &roundID, &roundID, entry.UsersAfter, entry.UsersBefore)
+	lobby, err := s.lobby(ctx, roundID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry, lobby, nil
+}
+
+// ListLobbyUndo returns the round's undoable operations, most recent (next to undo) first.
+func (s *InstructorService) ListLobbyUndo(ctx context.Context, roundID int64) ([]ports.LobbyUndoEntry, error) {
+	if _, err := s.repo.GetRoundByID(ctx, roundID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListLobbyUndo(ctx, roundID, maxLobbyUndoListed)
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// annotatePresence fills in each participant's presence and the idle/dropped counts.
+func annotatePresence(l *ports.LobbySnapshot, policy domain.PresencePolicy, now time.Time) {
+	count := func(p domain.Presence) {
+		switch p {
+		case domain.PresenceIdle:
+			l.Summary.Idle++
+		case domain.PresenceDropped:
+			l.Summary.Dropped++
+		}
+	}
+	l.Summary.Idle, l.Summary.Dropped = 0, 0
+	for i := range l.Teams {
+		for j := range l.Teams[i].Members {
+			m := &l.Teams[i].Members[j]
+			m.Presence = policy.At(m.LastSeenAt, now)
+			count(m.Presence)
+		}
+	}
+	for i := range l.Customers {
+		c := &l.Customers[i]
+		c.Presence = policy.At(c.LastSeenAt, now)
+		count(c.Presence)
+	}
+	for i := range l.Unassigned {
+		u := &l.Unassigned[i]
+		u.Presence = policy.At(u.LastSeenAt, now)
+		count(u.Presence)
+	}
+}
+
+// ReplaceDropped hands a dropped JM/QC's role and team to a waiting participant and puts
+// the dropped user back in the waiting pool. With no replacement given, the longest-waiting
+// participant who is still online is picked.
+func (s *InstructorService) ReplaceDropped(ctx context.Context, roundID, userID int64, replacementID *int64) (*ports.LobbySnapshot, error) {
+	if _, err := s.repo.GetRoundByID(ctx, roundID); err != nil {
+		return nil, err
+	}
+	dropped, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if s.presence.At(dropped.LastSeenAt, now) != domain.PresenceDropped {
+		return nil, domain.NewConflictError(fmt.Sprintf("user %d has not dropped", userID))
+	}
+
+	var replacement *domain.User
+	if replacementID != nil {
+		if *replacementID == userID {
+			return nil, domain.NewValidationError("replacement_user_id", "must be a different user")
+		}
+		if replacement, err = s.repo.GetUserByID(ctx, *replacementID); err != nil {
+			return nil, err
+		}
+		if s.presence.At(replacement.LastSeenAt, now) == domain.PresenceDropped {
+			return nil, domain.NewConflictError(fmt.Sprintf("replacement user %d has dropped too", replacement.ID))
+		}
+	} else {
+		waiting, err := s.repo.ListUsersByStatus(ctx, domain.ParticipantWaiting)
+		if err != nil {
+			return nil, err
+		}
+		for i := range waiting {
+			if s.presence.At(waiting[i].LastSeenAt, now) == domain.PresenceOnline {
+				replacement = &waiting[i]
+				break
+			}
+		}
+		if replacement == nil {
+			return nil, domain.NewConflictError("no online waiting participant to take over")
+		}
+	}
+
+	undo, err := s.beginLobbyUndo(ctx, roundID, AuditUserReplace, []int64{dropped.ID, replacement.ID})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceTeamMember(ctx, dropped.ID, replacement.ID); err != nil {
+		return nil, err
+	}
+	s.commitLobbyUndo(ctx, undo)
+
+	before := map[string]any{"user": auditUser(dropped), "replacement": auditUser(replacement)}
+	after := map[string]any{}
+	if u, err := s.repo.GetUserByID(ctx, dropped.ID); err == nil {
+		after["user"] = auditUser(u)
+	}
+	if u, err := s.repo.GetUserByID(ctx, replacement.ID); err == nil {
+		after["replacement"] = auditUser(u)
+	}
+	s.audit.record(ctx, AuditUserReplace, auditTargetUser, &userID, &roundID, before, after)
+	return s.lobby(ctx, roundID)
+}
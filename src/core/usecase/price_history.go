@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// PriceHistory charts a published joke's price from listing to now (or the round end).
+// Sales and the switch to dynamic pricing come from the recorded price changes, so the
+// prices paid are exact; the decay between them is redrawn with the round's current
+// curve, adding a FLOORED point where the price bottoms out.
+func (s *InstructorService) PriceHistory(ctx context.Context, roundID, jokeID int64) (*ports.PriceHistory, error) {
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	joke, err := s.repo.GetJokePricing(ctx, jokeID)
+	if err != nil {
+		return nil, err
+	}
+	if joke.RoundID != roundID || joke.PublishedAt == nil {
+		return nil, domain.NewNotFoundError("joke")
+	}
+	changes, err := s.repo.ListJokePriceChanges(ctx, jokeID)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &ports.PriceHistory{
+		RoundID:     roundID,
+		JokeID:      jokeID,
+		TeamID:      joke.TeamID,
+		PricingMode: round.PricingMode,
+		Points:      []ports.PricePoint{},
+	}
+	price, at := round.MarketPrice, *joke.PublishedAt
+	if len(changes) == 0 || changes[0].SalePrice != nil {
+		price = round.JokePrice(joke.TeamPrice, domain.PriceState{Price: price})
+		h.Points = append(h.Points, ports.PricePoint{Timestamp: at, Price: price, Event: ports.PriceListed})
+	}
+	for _, c := range changes {
+		h.Points = append(h.Points, decayPoints(round, price, at, c.CreatedAt)...)
+		if c.SalePrice != nil {
+			h.Points = append(h.Points,
+				ports.PricePoint{Timestamp: c.CreatedAt, Price: *c.SalePrice, Event: ports.PriceSold},
+				ports.PricePoint{Timestamp: c.CreatedAt, Price: c.Price, Event: ports.PriceRaised})
+		} else {
+			h.Points = append(h.Points, ports.PricePoint{Timestamp: c.CreatedAt, Price: c.Price, Event: ports.PriceStarted})
+		}
+		price, at = c.Price, c.CreatedAt
+	}
+
+	end := time.Now()
+	if round.EndedAt != nil && round.EndedAt.Before(end) {
+		end = *round.EndedAt
+	}
+	if end.Before(at) {
+		end = at
+	}
+	h.Points = append(h.Points, decayPoints(round, price, at, end)...)
+	h.CurrentPrice = round.JokePrice(joke.TeamPrice, domain.PriceState{Price: price, Idle: end.Sub(at)})
+	h.Points = append(h.Points, ports.PricePoint{Timestamp: end, Price: h.CurrentPrice, Event: ports.PriceCurrent})
+	return h, nil
+}
+
+// decayPoints returns the point where a dynamic price set to price at from reaches the
+// round's floor, if that happens before to.
+func decayPoints(round *domain.Round, price float64, from, to time.Time) []ports.PricePoint {
+	floor := round.PriceFloor()
+	if round.PricingMode != domain.PricingDynamic || round.PriceDecay <= 0 || price <= floor {
+		return nil
+	}
+	floorAt := from.Add(time.Duration((price - floor) / round.PriceDecay * float64(time.Minute)))
+	if !floorAt.Before(to) {
+		return nil
+	}
+	return []ports.PricePoint{{Timestamp: floorAt, Price: floor, Event: ports.PriceFloored}}
+}
@@ -46,7 +46,11 @@ func (s *QCService) Next(ctx context.Context, userID, roundID int64) (*QCQueueIt
 		return nil, domain.NewConflictError("round not active")
 	}
 
-	bw, size, err := s.repo.GetNextBatchForQC(ctx, roundID, userID, *user.TeamID)
+	sourceTeamID, err := s.queueSourceTeam(ctx, round, *user.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	bw, size, err := s.repo.GetNextBatchForQC(ctx, roundID, userID, sourceTeamID)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +110,49 @@ func (s *QCService) Rate(ctx context.Context, userID, batchID int64, ratings []d
 	return s.repo.RateBatch(ctx, batchID, userID, ratings, feedback)
 }
 
-func (s *QCService) QueueCount(ctx context.Context, roundID int64) (int, error) {
-	return s.repo.CountSubmittedBatches(ctx, roundID)
+// QueueCount returns the number of batches waiting for QC in a round. When userID is a
+// QC on a team, the count is that QC's queue under the round's routing mode; otherwise
+// it covers every team in the round.
+func (s *QCService) QueueCount(ctx context.Context, roundID int64, userID *int64) (int, error) {
+	if userID == nil {
+		return s.repo.CountSubmittedBatches(ctx, roundID, nil)
+	}
+	user, err := s.repo.GetUserByID(ctx, *userID)
+	if err != nil {
+		return 0, err
+	}
+	if user.Role == nil || *user.Role != domain.RoleQC || user.TeamID == nil {
+		return s.repo.CountSubmittedBatches(ctx, roundID, nil)
+	}
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return 0, err
+	}
+	sourceTeamID, err := s.queueSourceTeam(ctx, round, *user.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.CountSubmittedBatches(ctx, roundID, sourceTeamID)
+}
+
+// queueSourceTeam resolves whose batches a QC on qcTeamID is served under the round's
+// routing mode. A nil team means the shared pool across all teams.
+func (s *QCService) queueSourceTeam(ctx context.Context, round *domain.Round, qcTeamID int64) (*int64, error) {
+	switch round.QCRoutingMode {
+	case domain.QCRoutingGlobalPool:
+		return nil, nil
+	case domain.QCRoutingRotation:
+		teamIDs, err := s.repo.ListRoundTeamIDs(ctx, round.ID)
+		if err != nil {
+			return nil, err
+		}
+		sourceTeamID, ok := domain.QCRotationSourceTeam(teamIDs, qcTeamID)
+		if !ok {
+			return nil, domain.NewConflictError("qc team is not part of this round")
+		}
+		return &sourceTeamID, nil
+	default:
+		return &qcTeamID, nil
+	}
 }
 
@@ -0,0 +1,280 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+const (
+	defaultReplayPageSize = 500
+	maxReplayPageSize     = 5000
+)
+
+// ReplayState reconstructs the round's leaderboard, market and per-team QC queues as they
+// were at the given instant, from batch/rating timestamps and the purchase event log.
+// Leaderboard numbers follow the same rules as GetRoundStats.
+func (s *InstructorService) ReplayState(ctx context.Context, roundID int64, at time.Time) (*ports.ReplayState, error) {
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.roundTeams(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	jokes, err := s.repo.ListJokeLifecycles(ctx, roundID)
+	if err != nil {
+		s.log.Error("replay: list jokes failed", "round_id", roundID, "error", err)
+		return nil, err
+	}
+	events, err := s.repo.ListPurchaseEvents(ctx, roundID)
+	if err != nil {
+		s.log.Error("replay: list purchase events failed", "round_id", roundID, "error", err)
+		return nil, err
+	}
+
+	activeSales := make(map[int64]int)
+	teamSales := make(map[int64]int)
+	teamRevenue := make(map[int64]float64)
+	for _, e := range events {
+		if e.CreatedAt.After(at) {
+			break
+		}
+		activeSales[e.JokeID] += e.Delta
+		teamSales[e.TeamID] += e.Delta
+		// A return event carries the refund, so this nets to what the team actually kept.
+		teamRevenue[e.TeamID] += float64(e.Delta) * e.Price
+	}
+
+	stats := make(map[int64]*ports.TeamStats, len(teams))
+	queues := make(map[int64]*ports.ReplayQueue, len(teams))
+	var teamIDs []int64
+	addTeam := func(team domain.Team) {
+		stats[team.ID] = &ports.TeamStats{Team: team}
+		queues[team.ID] = &ports.ReplayQueue{TeamID: team.ID, TeamName: team.Name, BatchIDs: []int64{}}
+		teamIDs = append(teamIDs, team.ID)
+	}
+	for _, team := range teams {
+		addTeam(team)
+	}
+
+	scoreSums := make(map[int64]float64)
+	published := make(map[int64]int)
+	seenBatches := make(map[int64]bool)
+	market := []ports.ReplayMarketItem{}
+	for _, j := range jokes {
+		if j.BatchCreatedAt.After(at) {
+			continue
+		}
+		if _, ok := stats[j.TeamID]; !ok {
+			addTeam(domain.Team{ID: j.TeamID, Name: j.TeamName})
+		}
+		ts, q := stats[j.TeamID], queues[j.TeamID]
+		ts.TotalJokes++
+
+		firstOfBatch := !seenBatches[j.BatchID]
+		seenBatches[j.BatchID] = true
+		rated := j.RatedAt != nil && !j.RatedAt.After(at)
+		submitted := j.SubmittedAt != nil && !j.SubmittedAt.After(at)
+
+		switch {
+		case rated:
+			if firstOfBatch {
+				ts.BatchesRated++
+				if j.BatchAvgScore != nil {
+					scoreSums[j.TeamID] += *j.BatchAvgScore
+				}
+			}
+		case submitted:
+			q.QueuedJokes++
+			if firstOfBatch {
+				q.BatchIDs = append(q.BatchIDs, j.BatchID)
+				if j.LockedAt != nil && !j.LockedAt.After(at) {
+					q.InReviewBatches++
+				} else {
+					q.WaitingBatches++
+				}
+			}
+		}
+
+		if j.PublishedAt != nil && !j.PublishedAt.After(at) {
+			published[j.TeamID]++
+			ts.AcceptedJokes++
+			if activeSales[j.JokeID] <= 0 {
+				ts.UnsoldJokes++
+			}
+			market = append(market, ports.ReplayMarketItem{
+				JokeID:      j.JokeID,
+				JokeTitle:   j.JokeTitle,
+				JokeText:    j.JokeText,
+				TeamID:      j.TeamID,
+				TeamName:    j.TeamName,
+				PublishedAt: *j.PublishedAt,
+				BoughtCount: activeSales[j.JokeID],
+			})
+		} else if rated && j.Rating != nil && *j.Rating < 5 {
+			ts.UnacceptedJokes++
+		}
+	}
+
+	leaderboard := make([]ports.TeamStats, 0, len(teamIDs))
+	for _, teamID := range teamIDs {
+		ts := stats[teamID]
+		ts.TotalSales = teamSales[teamID]
+		ts.Profit = teamRevenue[teamID] - round.CostOfPublishing*float64(published[teamID])
+		if ts.BatchesRated > 0 {
+			ts.AvgScoreOverall = scoreSums[teamID] / float64(ts.BatchesRated)
+		}
+		leaderboard = append(leaderboard, *ts)
+	}
+	rankByProfit(leaderboard)
+
+	sort.Slice(teamIDs, func(i, j int) bool { return teamIDs[i] < teamIDs[j] })
+	out := &ports.ReplayState{RoundID: roundID, At: at, Leaderboard: leaderboard, Market: market, Queues: []ports.ReplayQueue{}}
+	for _, teamID := range teamIDs {
+		out.Queues = append(out.Queues, *queues[teamID])
+	}
+	sort.SliceStable(out.Market, func(i, j int) bool { return out.Market[i].PublishedAt.Before(out.Market[j].PublishedAt) })
+	return out, nil
+}
+
+// ReplayEvents returns the round's history as one time-ordered event stream, paged by
+// sequence number: events with Seq > afterSeq, at most limit of them (0 = default page).
+func (s *InstructorService) ReplayEvents(ctx context.Context, roundID int64, afterSeq, limit int) (*ports.ReplayFeed, error) {
+	if limit == 0 {
+		limit = defaultReplayPageSize
+	}
+	if limit < 0 || limit > maxReplayPageSize {
+		return nil, domain.NewValidationError("limit", "must be between 1 and 5000")
+	}
+	if afterSeq < 0 {
+		return nil, domain.NewValidationError("after_seq", "must not be negative")
+	}
+
+	if _, err := s.repo.GetRoundByID(ctx, roundID); err != nil {
+		return nil, err
+	}
+	teams, err := s.roundTeams(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	jokes, err := s.repo.ListJokeLifecycles(ctx, roundID)
+	if err != nil {
+		s.log.Error("replay: list jokes failed", "round_id", roundID, "error", err)
+		return nil, err
+	}
+	purchases, err := s.repo.ListPurchaseEvents(ctx, roundID)
+	if err != nil {
+		s.log.Error("replay: list purchase events failed", "round_id", roundID, "error", err)
+		return nil, err
+	}
+
+	all := replayEvents(teams, jokes, purchases)
+	feed := &ports.ReplayFeed{RoundID: roundID, Events: []ports.ReplayEvent{}, NextSeq: afterSeq}
+	if afterSeq < len(all) {
+		page := all[afterSeq:]
+		if len(page) > limit {
+			page = page[:limit]
+		}
+		feed.Events = page
+		feed.NextSeq = page[len(page)-1].Seq
+	}
+	feed.HasMore = feed.NextSeq < len(all)
+	return feed, nil
+}
+
+// replayEvents merges batch, publication and purchase history into one stream ordered by
+// time, numbering events from 1. Events at the same instant keep lifecycle order.
+func replayEvents(teams []domain.Team, jokes []ports.JokeLifecycle, purchases []domain.PurchaseEvent) []ports.ReplayEvent {
+	teamNames := make(map[int64]string, len(teams))
+	for _, t := range teams {
+		teamNames[t.ID] = t.Name
+	}
+
+	// Collapse joke rows into batches, keeping one representative row per batch.
+	batchJokes := make(map[int64]int)
+	var batches []ports.JokeLifecycle
+	for _, j := range jokes {
+		teamNames[j.TeamID] = j.TeamName
+		if batchJokes[j.BatchID] == 0 {
+			batches = append(batches, j)
+		}
+		batchJokes[j.BatchID]++
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].BatchID < batches[j].BatchID })
+
+	var events []ports.ReplayEvent
+	for _, b := range batches {
+		batchID := b.BatchID
+		count := batchJokes[b.BatchID]
+		if b.SubmittedAt != nil {
+			events = append(events, ports.ReplayEvent{Type: ports.ReplayBatchSubmitted, Timestamp: *b.SubmittedAt, TeamID: b.TeamID, BatchID: &batchID, JokesCount: &count})
+		}
+		if b.LockedAt != nil {
+			events = append(events, ports.ReplayEvent{Type: ports.ReplayBatchLocked, Timestamp: *b.LockedAt, TeamID: b.TeamID, BatchID: &batchID})
+		}
+		if b.RatedAt != nil {
+			events = append(events, ports.ReplayEvent{Type: ports.ReplayBatchRated, Timestamp: *b.RatedAt, TeamID: b.TeamID, BatchID: &batchID, JokesCount: &count, AvgScore: b.BatchAvgScore})
+		}
+	}
+	for _, j := range jokes {
+		if j.PublishedAt == nil {
+			continue
+		}
+		batchID, jokeID := j.BatchID, j.JokeID
+		events = append(events, ports.ReplayEvent{Type: ports.ReplayJokePublished, Timestamp: *j.PublishedAt, TeamID: j.TeamID, BatchID: &batchID, JokeID: &jokeID})
+	}
+	for _, p := range purchases {
+		jokeID, customerID := p.JokeID, p.CustomerUserID
+		typ := ports.ReplayPurchase
+		if p.Delta < 0 {
+			typ = ports.ReplayReturn
+		}
+		events = append(events, ports.ReplayEvent{Type: typ, Timestamp: p.CreatedAt, TeamID: p.TeamID, JokeID: &jokeID, CustomerUserID: &customerID})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	for i := range events {
+		events[i].Seq = i + 1
+		events[i].TeamName = teamNames[events[i].TeamID]
+	}
+	return events
+}
+
+// roundTeams returns the teams taking part in a round, ordered by id.
+func (s *InstructorService) roundTeams(ctx context.Context, roundID int64) ([]domain.Team, error) {
+	ids, err := s.repo.ListRoundTeamIDs(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	teams := make([]domain.Team, 0, len(ids))
+	for _, id := range ids {
+		team, err := s.repo.GetTeam(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, *team)
+	}
+	return teams, nil
+}
+
+// rankByProfit assigns dense ranks by profit (highest first) and sorts by rank, then team.
+func rankByProfit(stats []ports.TeamStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Profit != stats[j].Profit {
+			return stats[i].Profit > stats[j].Profit
+		}
+		return stats[i].Team.ID < stats[j].Team.ID
+	})
+	rank := 0
+	for i := range stats {
+		if i == 0 || stats[i].Profit != stats[i-1].Profit {
+			rank++
+		}
+		stats[i].Rank = rank
+	}
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+const (
+	// joinCodeAlphabet leaves out characters that are easy to misread (0/O, 1/I/L).
+	joinCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	joinCodeLength   = 8
+
+	maxRosterEntries = 2000
+)
+
+// RosterService manages the pre-registered class roster and join-code settings.
+type RosterService struct {
+	repo  ports.GameRepository
+	log   *slog.Logger
+	audit auditLog
+}
+
+func NewRosterService(repo ports.GameRepository, log *slog.Logger) *RosterService {
+	return &RosterService{repo: repo, log: log, audit: auditLog{repo: repo, log: log}}
+}
+
+// Roster is the roster listing returned to instructors.
+type Roster struct {
+	RequireJoinCode bool
+	Entries         []domain.RosterEntry
+}
+
+// Import validates entries and stores them, issuing a join code to each new student.
+// Entries with a student id replace an earlier import of the same student and keep their
+// code, so a corrected roster can be re-imported safely.
+func (s *RosterService) Import(ctx context.Context, entries []domain.RosterEntry) ([]domain.RosterEntry, error) {
+	if len(entries) == 0 {
+		return nil, domain.NewValidationError("entries", "roster is empty")
+	}
+	if len(entries) > maxRosterEntries {
+		return nil, domain.NewValidationError("entries", fmt.Sprintf("at most %d entries per import", maxRosterEntries))
+	}
+	seen := make(map[string]int, len(entries))
+	for i := range entries {
+		e := &entries[i]
+		field := fmt.Sprintf("entries[%d]", i)
+		e.DisplayName = strings.TrimSpace(e.DisplayName)
+		if e.DisplayName == "" {
+			return nil, domain.NewValidationError(field+".display_name", "is required")
+		}
+		if e.StudentID != nil {
+			id := strings.TrimSpace(*e.StudentID)
+			if id == "" {
+				e.StudentID = nil
+			} else {
+				if j, dup := seen[id]; dup {
+					return nil, domain.NewValidationError(field+".student_id", fmt.Sprintf("duplicates entries[%d]", j))
+				}
+				seen[id] = i
+				e.StudentID = &id
+			}
+		}
+		if e.PreferredTeam != nil && *e.PreferredTeam < 1 {
+			return nil, domain.NewValidationError(field+".preferred_team", "must be at least 1")
+		}
+		if e.PreferredRole != nil {
+			switch *e.PreferredRole {
+			case domain.RoleJM, domain.RoleQC:
+			case domain.RoleCustomer:
+				if e.PreferredTeam != nil {
+					return nil, domain.NewValidationError(field+".preferred_team", "customers don't belong to a team")
+				}
+			default:
+				return nil, domain.NewValidationError(field+".preferred_role", "must be one of JM, QC, CUSTOMER")
+			}
+		}
+		code, err := newJoinCode()
+		if err != nil {
+			return nil, err
+		}
+		e.JoinCode = code
+	}
+
+	stored, err := s.repo.ImportRoster(ctx, entries)
+	if err != nil {
+		s.log.Error("roster import failed", "error", err)
+		return nil, err
+	}
+	s.audit.record(ctx, AuditRosterImport, auditTargetGame, nil, nil, nil, map[string]any{"entries": len(stored)})
+	return stored, nil
+}
+
+// List returns the whole roster with join codes and whether codes are required.
+func (s *RosterService) List(ctx context.Context) (*Roster, error) {
+	settings, err := s.repo.GetGameSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListRoster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Roster{RequireJoinCode: settings.RequireJoinCode, Entries: entries}, nil
+}
+
+// SetRequireJoinCode turns the join-code requirement on or off.
+func (s *RosterService) SetRequireJoinCode(ctx context.Context, require bool) (*domain.GameSettings, error) {
+	settings, err := s.repo.GetGameSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	before := *settings
+	settings.RequireJoinCode = require
+	if err := s.repo.UpdateGameSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditGameSettings, auditTargetGame, nil, nil, before, *settings)
+	return settings, nil
+}
+
+// rosterPreferences maps joined students to their roster entry, for those with a
+// preferred team or role.
+func (s *InstructorService) rosterPreferences(ctx context.Context) (map[int64]domain.RosterEntry, error) {
+	entries, err := s.repo.ListRoster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefs := make(map[int64]domain.RosterEntry)
+	for _, e := range entries {
+		if e.UserID != nil && (e.PreferredRole != nil || e.PreferredTeam != nil) {
+			prefs[*e.UserID] = e
+		}
+	}
+	return prefs, nil
+}
+
+func newJoinCode() (string, error) {
+	b := make([]byte, joinCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = joinCodeAlphabet[int(b[i])%len(joinCodeAlphabet)]
+	}
+	return string(b), nil
+}
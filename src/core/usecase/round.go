@@ -28,8 +28,20 @@ func (s *RoundService) List(ctx context.Context) ([]domain.Round, error) {
 	return s.repo.ListRounds(ctx)
 }
 
-// TeamSummary returns stats for a team in a round.
+// TeamSummary returns stats for a team in a round, including its WIP limit status.
 func (s *RoundService) TeamSummary(ctx context.Context, roundID, teamID int64) (*ports.TeamSummary, error) {
-	return s.repo.GetTeamSummary(ctx, roundID, teamID)
+	summary, err := s.repo.GetTeamSummary(ctx, roundID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	round, err := s.repo.GetRoundByID(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	summary.MaxUnratedBatches = round.MaxUnratedBatches
+	summary.MaxUnratedJokes = round.MaxUnratedJokes
+	summary.CanSubmit = round.Status == domain.RoundActive &&
+		round.CheckWIPLimit(summary.UnratedBatches, summary.UnratedJokes, round.BatchSize) == nil
+	return summary, nil
 }
 
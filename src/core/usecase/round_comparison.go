@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// maxComparedRounds bounds how many rounds one comparison may cover.
+const maxComparedRounds = 10
+
+// CompareRounds builds the debrief comparison for the given rounds, in the order given:
+// per team and round the headline metrics, the change from each round to the next one
+// the team played, and the class-wide aggregate per round.
+func (s *InstructorService) CompareRounds(ctx context.Context, roundIDs []int64) (*ports.RoundComparison, error) {
+	if len(roundIDs) == 0 {
+		return nil, domain.NewValidationError("round_ids", "at least one round id required")
+	}
+	if len(roundIDs) > maxComparedRounds {
+		return nil, domain.NewValidationError("round_ids", "too many rounds to compare")
+	}
+	seen := make(map[int64]bool, len(roundIDs))
+	for _, id := range roundIDs {
+		if seen[id] {
+			return nil, domain.NewValidationError("round_ids", "round ids must be unique")
+		}
+		seen[id] = true
+	}
+
+	out := &ports.RoundComparison{RoundIDs: roundIDs}
+	teams := make(map[int64]*ports.TeamRoundComparison)
+
+	for _, roundID := range roundIDs {
+		round, err := s.repo.GetRoundByID(ctx, roundID)
+		if err != nil {
+			return nil, err
+		}
+		leaderboard, err := s.repo.GetRoundStats(ctx, roundID)
+		if err != nil {
+			s.log.Error("compare rounds: stats failed", "round_id", roundID, "error", err)
+			return nil, err
+		}
+		batches, err := s.repo.ListBatchFlow(ctx, roundID)
+		if err != nil {
+			s.log.Error("compare rounds: batch flow failed", "round_id", roundID, "error", err)
+			return nil, err
+		}
+
+		leadTimes := make(map[int64][]float64)
+		var classLeadTimes []float64
+		for i := range batches {
+			fillBatchDurations(&batches[i])
+			if lt := batches[i].LeadTimeSeconds; lt != nil {
+				leadTimes[batches[i].TeamID] = append(leadTimes[batches[i].TeamID], *lt)
+				classLeadTimes = append(classLeadTimes, *lt)
+			}
+		}
+
+		class := ports.ClassRoundAggregate{RoundID: round.ID, RoundNumber: round.RoundNumber}
+		var classAccepted, classReviewed, classBatchesRated int
+		var classScoreSum float64
+		for _, ts := range leaderboard {
+			metrics := ports.RoundMetrics{
+				Profit:             ts.Profit,
+				Sales:              ts.TotalSales,
+				AcceptanceRate:     ratio(ts.AcceptedJokes, ts.AcceptedJokes+ts.UnacceptedJokes),
+				AvgScore:           ts.AvgScoreOverall,
+				AvgLeadTimeSeconds: summarizeDurations(leadTimes[ts.Team.ID]).Mean,
+				JokesProduced:      ts.TotalJokes,
+			}
+			tc, ok := teams[ts.Team.ID]
+			if !ok {
+				tc = &ports.TeamRoundComparison{TeamID: ts.Team.ID, TeamName: ts.Team.Name}
+				teams[ts.Team.ID] = tc
+			}
+			tc.Rounds = append(tc.Rounds, ports.TeamRoundResult{RoundID: round.ID, RoundNumber: round.RoundNumber, RoundMetrics: metrics})
+
+			class.TeamCount++
+			class.Profit += ts.Profit
+			class.Sales += ts.TotalSales
+			class.JokesProduced += ts.TotalJokes
+			classAccepted += ts.AcceptedJokes
+			classReviewed += ts.AcceptedJokes + ts.UnacceptedJokes
+			// Team averages are per batch, so weight them by batches rated.
+			classScoreSum += ts.AvgScoreOverall * float64(ts.BatchesRated)
+			classBatchesRated += ts.BatchesRated
+		}
+		class.AcceptanceRate = ratio(classAccepted, classReviewed)
+		if classBatchesRated > 0 {
+			class.AvgScore = classScoreSum / float64(classBatchesRated)
+		}
+		class.AvgLeadTimeSeconds = summarizeDurations(classLeadTimes).Mean
+		out.Class = append(out.Class, class)
+	}
+
+	for i := 1; i < len(out.Class); i++ {
+		out.ClassDeltas = append(out.ClassDeltas, metricsDelta(out.Class[i-1].RoundID, out.Class[i].RoundID, out.Class[i-1].RoundMetrics, out.Class[i].RoundMetrics))
+	}
+
+	for _, tc := range teams {
+		for i := 1; i < len(tc.Rounds); i++ {
+			tc.Deltas = append(tc.Deltas, metricsDelta(tc.Rounds[i-1].RoundID, tc.Rounds[i].RoundID, tc.Rounds[i-1].RoundMetrics, tc.Rounds[i].RoundMetrics))
+		}
+		out.Teams = append(out.Teams, *tc)
+	}
+	sort.Slice(out.Teams, func(i, j int) bool { return out.Teams[i].TeamID < out.Teams[j].TeamID })
+
+	return out, nil
+}
+
+func metricsDelta(fromRoundID, toRoundID int64, from, to ports.RoundMetrics) ports.RoundMetricsDelta {
+	return ports.RoundMetricsDelta{
+		FromRoundID: fromRoundID,
+		ToRoundID:   toRoundID,
+		RoundMetrics: ports.RoundMetrics{
+			Profit:             to.Profit - from.Profit,
+			Sales:              to.Sales - from.Sales,
+			AcceptanceRate:     to.AcceptanceRate - from.AcceptanceRate,
+			AvgScore:           to.AvgScore - from.AvgScore,
+			AvgLeadTimeSeconds: to.AvgLeadTimeSeconds - from.AvgLeadTimeSeconds,
+			JokesProduced:      to.JokesProduced - from.JokesProduced,
+		},
+	}
+}
+
+func ratio(num, den int) float64 {
+	if den == 0 {
+		return 0
+	}
+	return float64(num) / float64(den)
+}
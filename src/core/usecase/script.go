@@ -0,0 +1,462 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+const (
+	maxScriptSteps    = 200
+	maxScriptName     = 100
+	maxScriptDuration = 24 * time.Hour
+
+	// scriptTick is how often the scheduler looks for due steps, and so how late a step
+	// may run.
+	scriptTick = time.Second
+)
+
+// ScriptService stores game scripts and runs them: a server-side scheduler performs each
+// step's instructor operation when it falls due, and logs the outcome.
+type ScriptService struct {
+	repo          ports.GameRepository
+	log           *slog.Logger
+	audit         auditLog
+	instructor    *InstructorService
+	announcements *AnnouncementService
+}
+
+func NewScriptService(repo ports.GameRepository, log *slog.Logger, instructor *InstructorService, announcements *AnnouncementService) *ScriptService {
+	return &ScriptService{
+		repo:          repo,
+		log:           log,
+		audit:         auditLog{repo: repo, log: log},
+		instructor:    instructor,
+		announcements: announcements,
+	}
+}
+
+// ScriptRunView is a run with the log of the steps it has executed or skipped so far.
+type ScriptRunView struct {
+	Run *domain.ScriptRun
+	Log []domain.ScriptStepLog
+}
+
+// Create validates and stores a new script. Steps are stored ordered by time.
+func (s *ScriptService) Create(ctx context.Context, createdBy int64, name string, steps []domain.ScriptStep) (*domain.GameScript, error) {
+	script := &domain.GameScript{Name: name, Steps: steps, CreatedBy: &createdBy}
+	if err := validateScript(script); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateScript(ctx, script); err != nil {
+		s.log.Error("create script failed", "error", err)
+		return nil, err
+	}
+	s.audit.record(ctx, AuditScriptSave, auditTargetScript, &script.ID, nil, nil, script)
+	return script, nil
+}
+
+// Update replaces a script's name and steps. A run already in progress keeps the steps
+// it started with.
+func (s *ScriptService) Update(ctx context.Context, scriptID int64, name string, steps []domain.ScriptStep) (*domain.GameScript, error) {
+	before, err := s.repo.GetScript(ctx, scriptID)
+	if err != nil {
+		return nil, err
+	}
+	script := &domain.GameScript{ID: scriptID, Name: name, Steps: steps}
+	if err := validateScript(script); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateScript(ctx, script); err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditScriptSave, auditTargetScript, &scriptID, nil, before, script)
+	return script, nil
+}
+
+func (s *ScriptService) Get(ctx context.Context, scriptID int64) (*domain.GameScript, error) {
+	return s.repo.GetScript(ctx, scriptID)
+}
+
+func (s *ScriptService) List(ctx context.Context) ([]domain.GameScript, error) {
+	return s.repo.ListScripts(ctx)
+}
+
+// Delete removes a script. Past and current runs keep their copy of the steps.
+func (s *ScriptService) Delete(ctx context.Context, scriptID int64) error {
+	before, err := s.repo.GetScript(ctx, scriptID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteScript(ctx, scriptID); err != nil {
+		return err
+	}
+	s.audit.record(ctx, AuditScriptDelete, auditTargetScript, &scriptID, nil, before, nil)
+	return nil
+}
+
+// Play starts a run of the script, or resumes it if this script's run is paused. Only
+// one run can be in progress at a time.
+func (s *ScriptService) Play(ctx context.Context, scriptID, startedBy int64) (*domain.ScriptRun, error) {
+	current, err := s.repo.GetCurrentScriptRun(ctx)
+	if err != nil && !domain.IsNotFound(err) {
+		return nil, err
+	}
+	if current != nil {
+		if current.Status != domain.ScriptPaused || current.ScriptID == nil || *current.ScriptID != scriptID {
+			return nil, domain.NewConflictError(fmt.Sprintf("script %q is already in progress", current.ScriptName))
+		}
+		return s.changeRun(ctx, AuditScriptPlay, current, domain.ScriptRunning, domain.ScriptPaused)
+	}
+
+	script, err := s.repo.GetScript(ctx, scriptID)
+	if err != nil {
+		return nil, err
+	}
+	run := &domain.ScriptRun{ScriptID: &script.ID, ScriptName: script.Name, Steps: script.Steps, StartedBy: &startedBy}
+	if err := s.repo.StartScriptRun(ctx, run); err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditScriptPlay, auditTargetScriptRun, &run.ID, nil, nil, auditScriptRun(run))
+	return run, nil
+}
+
+// Pause stops the script clock; no steps run until it is played again.
+func (s *ScriptService) Pause(ctx context.Context) (*domain.ScriptRun, error) {
+	current, err := s.repo.GetCurrentScriptRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.changeRun(ctx, AuditScriptPause, current, domain.ScriptPaused, domain.ScriptRunning)
+}
+
+// Stop ends the current run; its remaining steps never run.
+func (s *ScriptService) Stop(ctx context.Context) (*domain.ScriptRun, error) {
+	current, err := s.repo.GetCurrentScriptRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.changeRun(ctx, AuditScriptStop, current, domain.ScriptStopped, domain.ScriptRunning, domain.ScriptPaused)
+}
+
+// Skip passes over the current run's next step without running it. Skipping the last
+// step finishes the run.
+func (s *ScriptService) Skip(ctx context.Context) (*domain.ScriptRun, error) {
+	current, err := s.repo.GetCurrentScriptRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idx := current.NextStep
+	if idx >= len(current.Steps) {
+		return nil, domain.NewConflictError("no steps left to skip")
+	}
+	ok, err := s.repo.AdvanceScriptRun(ctx, current.ID, idx, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.NewConflictError("the script moved on; reload and try again")
+	}
+	s.logStep(ctx, current, idx, domain.ScriptStepSkipped, nil)
+
+	var after *domain.ScriptRun
+	if idx+1 >= len(current.Steps) {
+		after, err = s.repo.SetScriptRunStatus(ctx, current.ID, domain.ScriptFinished, domain.ScriptRunning, domain.ScriptPaused)
+	} else {
+		after, err = s.repo.GetScriptRun(ctx, current.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, AuditScriptSkip, auditTargetScriptRun, &current.ID, nil, auditScriptRun(current), auditScriptRun(after))
+	return after, nil
+}
+
+func (s *ScriptService) changeRun(ctx context.Context, action string, run *domain.ScriptRun, to domain.ScriptRunStatus, from ...domain.ScriptRunStatus) (*domain.ScriptRun, error) {
+	after, err := s.repo.SetScriptRunStatus(ctx, run.ID, to, from...)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(ctx, action, auditTargetScriptRun, &run.ID, nil, auditScriptRun(run), auditScriptRun(after))
+	return after, nil
+}
+
+// Current returns the run in progress with its step log.
+func (s *ScriptService) Current(ctx context.Context) (*ScriptRunView, error) {
+	run, err := s.repo.GetCurrentScriptRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(ctx, run)
+}
+
+// Run returns any run, finished or not, with its step log.
+func (s *ScriptService) Run(ctx context.Context, runID int64) (*ScriptRunView, error) {
+	run, err := s.repo.GetScriptRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(ctx, run)
+}
+
+func (s *ScriptService) view(ctx context.Context, run *domain.ScriptRun) (*ScriptRunView, error) {
+	log, err := s.repo.ListScriptStepLog(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ScriptRunView{Run: run, Log: log}, nil
+}
+
+// RunScheduler runs due script steps until ctx is cancelled.
+func (s *ScriptService) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(scriptTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.safeTick(ctx); err != nil && ctx.Err() == nil {
+				s.log.Error("script scheduler tick failed", "error", err)
+			}
+		}
+	}
+}
+
+// safeTick runs a tick, turning a panic into an error so the scheduler, and the server
+// it runs in, survive it.
+func (s *ScriptService) safeTick(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("script scheduler panic: %v", r)
+		}
+	}()
+	return s.tick(ctx)
+}
+
+// tick runs every step of the current run that has fallen due. Each step is claimed
+// before it runs, so a step never runs twice even with several schedulers, and a pause
+// or skip in between takes effect at once. A failed step is logged and the run goes on.
+func (s *ScriptService) tick(ctx context.Context) error {
+	run, err := s.repo.GetCurrentScriptRun(ctx)
+	if err != nil {
+		if domain.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if run.Status != domain.ScriptRunning {
+		return nil
+	}
+
+	elapsed := run.ElapsedAt(time.Now())
+	for run.NextStep < len(run.Steps) && run.Steps[run.NextStep].At() <= elapsed {
+		idx := run.NextStep
+		ok, err := s.repo.AdvanceScriptRun(ctx, run.ID, idx, true)
+		if err != nil || !ok {
+			return err
+		}
+		run.NextStep++
+		s.logStep(ctx, run, idx, domain.ScriptStepDone, s.runStep(s.actorContext(ctx, run), run, run.Steps[idx]))
+	}
+	if run.NextStep >= len(run.Steps) {
+		if _, err := s.repo.SetScriptRunStatus(ctx, run.ID, domain.ScriptFinished, domain.ScriptRunning); err != nil && !domain.IsConflict(err) {
+			return err
+		}
+		s.log.Info("script run finished", "run_id", run.ID, "script", run.ScriptName)
+	}
+	return nil
+}
+
+// actorContext attributes what the run's steps change to the instructor who played it.
+func (s *ScriptService) actorContext(ctx context.Context, run *domain.ScriptRun) context.Context {
+	if run.StartedBy == nil {
+		return ctx
+	}
+	return WithAuditActor(ctx, AuditActor{
+		UserID:      *run.StartedBy,
+		DisplayName: fmt.Sprintf("script %q", run.ScriptName),
+		RequestID:   fmt.Sprintf("script-run-%d", run.ID),
+	})
+}
+
+// runStep executes a step, reporting a panic as the step's error so the run goes on.
+func (s *ScriptService) runStep(ctx context.Context, run *domain.ScriptRun, step domain.ScriptStep) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error("script step panicked", "run_id", run.ID, "action", step.Action, "panic", r)
+			err = fmt.Errorf("step panicked: %v", r)
+		}
+	}()
+	return s.execute(ctx, run, step)
+}
+
+// execute performs one step through the same services the instructor endpoints use.
+func (s *ScriptService) execute(ctx context.Context, run *domain.ScriptRun, step domain.ScriptStep) error {
+	switch step.Action {
+	case domain.ScriptStartRound:
+		rd, err := s.instructor.GetRound(ctx, *step.RoundID)
+		if err != nil {
+			return err
+		}
+		budget, batchSize, price, cost := rd.CustomerBudget, rd.BatchSize, rd.MarketPrice, rd.CostOfPublishing
+		if step.CustomerBudget != nil {
+			budget = *step.CustomerBudget
+		}
+		if step.BatchSize != nil {
+			batchSize = *step.BatchSize
+		}
+		if step.MarketPrice != nil {
+			price = *step.MarketPrice
+		}
+		if step.CostOfPublishing != nil {
+			cost = *step.CostOfPublishing
+		}
+		_, err = s.instructor.StartRoundWithConfig(ctx, rd.ID, budget, batchSize, price, cost)
+		return err
+	case domain.ScriptEndRound:
+		_, err := s.instructor.EndRound(ctx, *step.RoundID)
+		return err
+	case domain.ScriptSetPopup:
+		_, err := s.instructor.SetPopupState(ctx, *step.RoundID, *step.PopupActive)
+		return err
+	case domain.ScriptSetMarketPrice:
+		_, err := s.instructor.SetMarketPrice(ctx, *step.RoundID, *step.MarketPrice)
+		return err
+	case domain.ScriptAnnounce:
+		if run.StartedBy == nil {
+			return errors.New("the instructor who played the script no longer exists")
+		}
+		sa := step.Announcement
+		a := &domain.Announcement{
+			Title:        sa.Title,
+			Body:         sa.Body,
+			Severity:     sa.Severity,
+			Target:       sa.Target,
+			TargetRole:   sa.TargetRole,
+			TargetTeamID: sa.TargetTeamID,
+			TargetUserID: sa.TargetUserID,
+		}
+		if sa.ExpiresInSeconds != nil {
+			expires := time.Now().Add(time.Duration(*sa.ExpiresInSeconds) * time.Second)
+			a.ExpiresAt = &expires
+		}
+		_, err := s.announcements.Create(ctx, *run.StartedBy, a)
+		return err
+	}
+	return fmt.Errorf("unknown script action %q", step.Action)
+}
+
+// logStep records what happened to a step, in the run's log and the server log.
+func (s *ScriptService) logStep(ctx context.Context, run *domain.ScriptRun, idx int, outcome domain.ScriptStepOutcome, stepErr error) {
+	entry := &domain.ScriptStepLog{RunID: run.ID, StepIndex: idx, Action: run.Steps[idx].Action, Outcome: outcome}
+	if stepErr != nil {
+		entry.Outcome = domain.ScriptStepFailed
+		entry.Error = stepErr.Error()
+	}
+	s.log.Info("script step", "run_id", run.ID, "step", idx, "action", entry.Action, "outcome", entry.Outcome, "error", entry.Error)
+	if err := s.repo.InsertScriptStepLog(ctx, entry); err != nil {
+		s.log.Error("script step log failed", "run_id", run.ID, "step", idx, "error", err)
+	}
+}
+
+// validateScript checks a script and orders its steps by time.
+func validateScript(script *domain.GameScript) error {
+	script.Name = strings.TrimSpace(script.Name)
+	if script.Name == "" {
+		return domain.NewValidationError("name", "is required")
+	}
+	if len(script.Name) > maxScriptName {
+		return domain.NewValidationError("name", fmt.Sprintf("must be at most %d characters", maxScriptName))
+	}
+	if len(script.Steps) == 0 {
+		return domain.NewValidationError("steps", "a script needs at least one step")
+	}
+	if len(script.Steps) > maxScriptSteps {
+		return domain.NewValidationError("steps", fmt.Sprintf("at most %d steps per script", maxScriptSteps))
+	}
+	for i := range script.Steps {
+		if err := validateScriptStep(fmt.Sprintf("steps[%d]", i), &script.Steps[i]); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(script.Steps, func(i, j int) bool {
+		return script.Steps[i].AtSeconds < script.Steps[j].AtSeconds
+	})
+	return nil
+}
+
+func validateScriptStep(field string, step *domain.ScriptStep) error {
+	if step.AtSeconds < 0 || step.At() > maxScriptDuration {
+		return domain.NewValidationError(field+".at_seconds", fmt.Sprintf("must be between 0 and %d", int(maxScriptDuration.Seconds())))
+	}
+	step.Action = domain.ScriptAction(strings.ToUpper(string(step.Action)))
+	if step.Action != domain.ScriptAnnounce && (step.RoundID == nil || *step.RoundID <= 0) {
+		return domain.NewValidationError(field+".round_id", "is required for "+string(step.Action))
+	}
+	switch step.Action {
+	case domain.ScriptStartRound:
+		if step.CustomerBudget != nil && *step.CustomerBudget < 0 {
+			return domain.NewValidationError(field+".customer_budget", "must not be negative")
+		}
+		if step.BatchSize != nil && *step.BatchSize < 1 {
+			return domain.NewValidationError(field+".batch_size", "must be at least 1")
+		}
+		if step.MarketPrice != nil && *step.MarketPrice <= 0 {
+			return domain.NewValidationError(field+".market_price", "must be positive")
+		}
+		if step.CostOfPublishing != nil && *step.CostOfPublishing < 0 {
+			return domain.NewValidationError(field+".cost_of_publishing", "must not be negative")
+		}
+	case domain.ScriptEndRound:
+	case domain.ScriptSetPopup:
+		if step.PopupActive == nil {
+			return domain.NewValidationError(field+".popup_active", "is required for SET_POPUP")
+		}
+	case domain.ScriptSetMarketPrice:
+		if step.MarketPrice == nil || *step.MarketPrice <= 0 {
+			return domain.NewValidationError(field+".market_price", "must be positive")
+		}
+	case domain.ScriptAnnounce:
+		a := step.Announcement
+		if a == nil || strings.TrimSpace(a.Title) == "" {
+			return domain.NewValidationError(field+".announcement.title", "is required for ANNOUNCE")
+		}
+		a.Severity = domain.AnnouncementSeverity(strings.ToUpper(string(a.Severity)))
+		if a.Severity != "" && !a.Severity.IsValid() {
+			return domain.NewValidationError(field+".announcement.severity", "must be INFO, WARNING or CRITICAL")
+		}
+		a.Target = domain.AnnouncementTarget(strings.ToUpper(string(a.Target)))
+		if a.Target != "" && !a.Target.IsValid() {
+			return domain.NewValidationError(field+".announcement.target", "must be ALL, ROLE, TEAM or USER")
+		}
+		if a.ExpiresInSeconds != nil && *a.ExpiresInSeconds <= 0 {
+			return domain.NewValidationError(field+".announcement.expires_in_seconds", "must be positive")
+		}
+	default:
+		return domain.NewValidationError(field+".action", "must be START_ROUND, END_ROUND, SET_POPUP, ANNOUNCE or SET_MARKET_PRICE")
+	}
+	return nil
+}
+
+// auditScriptRun is the audited view of a script run.
+func auditScriptRun(run *domain.ScriptRun) any {
+	if run == nil {
+		return nil
+	}
+	return map[string]any{
+		"run_id":      run.ID,
+		"script_id":   run.ScriptID,
+		"script_name": run.ScriptName,
+		"status":      run.Status,
+		"next_step":   run.NextStep,
+		"elapsed_ms":  run.ElapsedAt(time.Now()).Milliseconds(),
+	}
+}
@@ -2,7 +2,13 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"log/slog"
+	"strings"
+	"time"
 
 	"jokefactory/src/core/domain"
 	"jokefactory/src/core/ports"
@@ -10,61 +16,127 @@ import (
 
 // SessionService handles join/me flows.
 type SessionService struct {
-	repo ports.GameRepository
-	log  *slog.Logger
+	repo       ports.GameRepository
+	log        *slog.Logger
+	presence   domain.PresencePolicy
+	admissions admissions
 }
 
 const firstRoundID int64 = 1
 
-func NewSessionService(repo ports.GameRepository, log *slog.Logger) *SessionService {
-	return &SessionService{repo: repo, log: log}
+func NewSessionService(repo ports.GameRepository, log *slog.Logger, presence domain.PresencePolicy) *SessionService {
+	return &SessionService{repo: repo, log: log, presence: presence, admissions: admissions{repo: repo, log: log, presence: presence}}
 }
 
 type SessionJoinResult struct {
 	User *domain.User
+	// RejoinSecret lets the client reclaim this user later; it is only known to the client.
+	RejoinSecret string
+	// Rejoined is true when the secret matched an existing user.
+	Rejoined bool
 }
 
-// Join registers a user into the latest round as waiting.
-func (s *SessionService) Join(ctx context.Context, displayName string) (*SessionJoinResult, error) {
-	// Idempotent "login": reuse existing user if the display name already exists.
-	var user *domain.User
-	user, err := s.repo.GetUserByDisplayName(ctx, displayName)
-	if err != nil {
-		if domain.IsNotFound(err) {
-			user, err = s.repo.CreateUser(ctx, displayName)
-			if err != nil {
+// Join registers a participant as waiting, or reclaims an existing one. In order:
+//   - a rejoin secret the client stored earlier reclaims that user;
+//   - a roster join code creates (or reclaims) that student's user;
+//   - otherwise, unless the game requires join codes, a new participant is created. If the
+//     display name is taken, the new user gets a numbered variant of it.
+//
+// New participants are waitlisted once the game is full; during an active round they are
+// seated according to the round's late-joiner policy.
+func (s *SessionService) Join(ctx context.Context, displayName, rejoinSecret, joinCode string) (*SessionJoinResult, error) {
+	if rejoinSecret != "" {
+		user, err := s.repo.GetUserByRejoinSecret(ctx, hashRejoinSecret(rejoinSecret))
+		if err == nil && (user.Role == nil || *user.Role != domain.RoleInstructor) {
+			// Rejoining counts as a heartbeat, so a returning participant isn't shown as dropped.
+			if user.LastSeenAt, err = s.repo.TouchUser(ctx, user.ID); err != nil {
+				s.log.Error("join: touch user failed", "user_id", user.ID, "error", err)
 				return nil, err
 			}
-		} else {
-			return nil, err
+			return &SessionJoinResult{User: user, RejoinSecret: rejoinSecret, Rejoined: true}, nil
 		}
-	} else if user.Role != nil && *user.Role == domain.RoleInstructor {
-		// Avoid logging into instructor accounts from the student join flow.
-		// Create a fresh user with the same display name but no role.
-		user, err = s.repo.CreateUser(ctx, displayName)
-		if err != nil {
+		if err != nil && !domain.IsNotFound(err) {
 			return nil, err
 		}
+		// Unknown or stale secret (e.g. after a reset): fall through to a fresh join.
 	}
 
-	// Preserve existing status; only set to WAITING for brand-new users lacking a status.
-	if user.Status == "" {
-		if err := s.repo.UpdateUserStatus(ctx, user.ID, domain.ParticipantWaiting); err != nil {
-			s.log.Error("join: update user status failed", "user_id", user.ID, "error", err)
+	secret, err := newRejoinSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if joinCode = strings.TrimSpace(joinCode); joinCode != "" {
+		user, reclaimed, err := s.repo.ClaimJoinCode(ctx, joinCode, hashRejoinSecret(secret))
+		if err != nil {
 			return nil, err
 		}
-		// Refresh status in the returned struct
-		user.Status = domain.ParticipantWaiting
+		if !reclaimed {
+			user = s.admit(ctx, user)
+		}
+		return &SessionJoinResult{User: user, RejoinSecret: secret, Rejoined: reclaimed}, nil
 	}
 
-	return &SessionJoinResult{
-		User: user,
-	}, nil
+	settings, err := s.repo.GetGameSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings.RequireJoinCode {
+		return nil, domain.NewUnauthorizedError("a join code is required to join this game")
+	}
+	if displayName = strings.TrimSpace(displayName); displayName == "" {
+		return nil, domain.NewValidationError("display_name", "is required")
+	}
+
+	user, err := s.repo.CreateParticipant(ctx, displayName, hashRejoinSecret(secret))
+	if err != nil {
+		s.log.Error("join: create participant failed", "error", err)
+		return nil, err
+	}
+	return &SessionJoinResult{User: s.admit(ctx, user), RejoinSecret: secret}, nil
+}
+
+// admit applies the active round's late-joiner policy to a newly joined participant and
+// returns them as they now stand. The join itself has succeeded either way, so failures
+// are logged and leave the participant waiting.
+func (s *SessionService) admit(ctx context.Context, user *domain.User) *domain.User {
+	if user.Status != domain.ParticipantWaiting {
+		return user
+	}
+	round, err := s.repo.GetActiveRound(ctx)
+	if err == nil && round != nil {
+		err = s.admissions.apply(ctx, round)
+	}
+	if err != nil {
+		s.log.Error("join: admissions failed", "user_id", user.ID, "error", err)
+		return user
+	}
+	if updated, err := s.repo.GetUserByID(ctx, user.ID); err == nil {
+		return updated
+	}
+	return user
+}
+
+// newRejoinSecret returns a random URL-safe secret.
+func newRejoinSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashRejoinSecret is what gets stored; secrets are high-entropy, so a plain SHA-256 is enough.
+func hashRejoinSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
 }
 
 type SessionMeResult struct {
 	User      *domain.User
 	Teammates []ports.TeamMember
+	// Announcements are the unexpired ones addressed to the user, newest first.
+	Announcements []ports.UserAnnouncement
 }
 
 // Me returns session info for a user.
@@ -99,8 +171,34 @@ func (s *SessionService) Me(ctx context.Context, userID int64) (*SessionMeResult
 		}
 	}
 
+	announcements, err := s.repo.ListAnnouncementsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &SessionMeResult{
-		User:      user,
-		Teammates: teammates,
+		User:          user,
+		Teammates:     teammates,
+		Announcements: announcements,
+	}, nil
+}
+
+type SessionHeartbeatResult struct {
+	LastSeenAt time.Time
+	Presence   domain.Presence
+	// IdleAfter tells the client how often it must send heartbeats to stay online.
+	IdleAfter time.Duration
+}
+
+// Heartbeat records that the user's client is still connected.
+func (s *SessionService) Heartbeat(ctx context.Context, userID int64) (*SessionHeartbeatResult, error) {
+	lastSeen, err := s.repo.TouchUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionHeartbeatResult{
+		LastSeenAt: lastSeen,
+		Presence:   domain.PresenceOnline,
+		IdleAfter:  s.presence.IdleAfter,
 	}, nil
 }
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// SnapshotService archives and restores whole games.
+type SnapshotService struct {
+	repo  ports.GameRepository
+	log   *slog.Logger
+	audit auditLog
+}
+
+func NewSnapshotService(repo ports.GameRepository, log *slog.Logger) *SnapshotService {
+	return &SnapshotService{repo: repo, log: log, audit: auditLog{repo: repo, log: log}}
+}
+
+// Export captures the current game as a versioned snapshot.
+func (s *SnapshotService) Export(ctx context.Context) (*ports.GameSnapshot, error) {
+	snap, err := s.repo.ExportSnapshot(ctx)
+	if err != nil {
+		s.log.Error("snapshot export failed", "error", err)
+		return nil, err
+	}
+	snap.Version = ports.SnapshotVersion
+	snap.ExportedAt = time.Now().UTC()
+	return snap, nil
+}
+
+// Import restores a snapshot into an empty game. Snapshots from MinSnapshotVersion up to
+// the current format are accepted.
+func (s *SnapshotService) Import(ctx context.Context, snap *ports.GameSnapshot) (*ports.SnapshotImportResult, error) {
+	if snap.Version < ports.MinSnapshotVersion || snap.Version > ports.SnapshotVersion {
+		return nil, domain.NewValidationError("version", fmt.Sprintf("unsupported snapshot version %d (expected %d to %d)", snap.Version, ports.MinSnapshotVersion, ports.SnapshotVersion))
+	}
+	res, err := s.repo.ImportSnapshot(ctx, snap)
+	if err != nil {
+		s.log.Error("snapshot import failed", "error", err)
+		return nil, err
+	}
+	s.audit.record(ctx, AuditSnapshotImport, auditTargetGame, nil, nil, nil, res)
+	return res, nil
+}
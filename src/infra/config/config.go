@@ -24,6 +24,9 @@ type Config struct {
 
 	// Admin configuration
 	Admin AdminConfig
+
+	// Participant presence configuration
+	Presence PresenceConfig
 }
 
 // ServerConfig holds HTTP server settings.
@@ -89,6 +92,15 @@ type AdminConfig struct {
 	AdminPassword string `envconfig:"ADMIN_PASSWORD" default:"Toyota410"`
 }
 
+// PresenceConfig holds the heartbeat timeouts used to derive participant presence.
+type PresenceConfig struct {
+	// IdleAfter is how long without a heartbeat before a participant is IDLE (default: 30s)
+	IdleAfter time.Duration `envconfig:"PRESENCE_IDLE_AFTER" default:"30s"`
+
+	// DropAfter is how long without a heartbeat before a participant is DROPPED (default: 2m)
+	DropAfter time.Duration `envconfig:"PRESENCE_DROP_AFTER" default:"2m"`
+}
+
 // DSN returns the PostgreSQL connection string.
 func (c *DatabaseConfig) DSN() string {
 	return fmt.Sprintf(
@@ -121,6 +133,12 @@ func Load() (*Config, error) {
 	if err := envconfig.Process("APP", &cfg.Admin); err != nil {
 		return nil, fmt.Errorf("failed to load admin config: %w", err)
 	}
+	if err := envconfig.Process("APP", &cfg.Presence); err != nil {
+		return nil, fmt.Errorf("failed to load presence config: %w", err)
+	}
+	if cfg.Presence.IdleAfter <= 0 || cfg.Presence.DropAfter < cfg.Presence.IdleAfter {
+		return nil, fmt.Errorf("invalid presence config: need 0 < PRESENCE_IDLE_AFTER <= PRESENCE_DROP_AFTER")
+	}
 
 	return &cfg, nil
 }
@@ -0,0 +1,18 @@
+-- +goose Up
+BEGIN;
+
+-- Round-level QC routing: who rates whose batches.
+CREATE TYPE qc_routing_mode AS ENUM ('OWN_TEAM', 'ROTATION', 'GLOBAL_POOL');
+
+ALTER TABLE rounds
+  ADD COLUMN IF NOT EXISTS qc_routing_mode qc_routing_mode NOT NULL DEFAULT 'OWN_TEAM';
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE rounds DROP COLUMN IF EXISTS qc_routing_mode;
+DROP TYPE IF EXISTS qc_routing_mode;
+
+COMMIT;
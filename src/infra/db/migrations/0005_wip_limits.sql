@@ -0,0 +1,18 @@
+-- +goose Up
+BEGIN;
+
+-- Optional per-team work-in-progress limits on unrated work (NULL = unlimited).
+ALTER TABLE rounds
+  ADD COLUMN IF NOT EXISTS max_unrated_batches INT NULL CHECK (max_unrated_batches >= 1),
+  ADD COLUMN IF NOT EXISTS max_unrated_jokes   INT NULL CHECK (max_unrated_jokes >= 1);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE rounds
+  DROP COLUMN IF EXISTS max_unrated_jokes,
+  DROP COLUMN IF EXISTS max_unrated_batches;
+
+COMMIT;
@@ -0,0 +1,31 @@
+-- +goose Up
+BEGIN;
+
+-- Trail of instructor mutations. Deliberately not touched by ResetGame and without a
+-- round FK, so the record outlives the data it describes.
+CREATE TABLE IF NOT EXISTS audit_events (
+  event_id           BIGSERIAL PRIMARY KEY,
+  actor_user_id      BIGINT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+  actor_display_name TEXT NOT NULL DEFAULT '',
+  action             TEXT NOT NULL,
+  target_type        TEXT NOT NULL,
+  target_id          BIGINT NULL,
+  round_id           BIGINT NULL,
+  before_state       JSONB NULL,
+  after_state        JSONB NULL,
+  request_id         TEXT NOT NULL DEFAULT '',
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created ON audit_events(created_at, event_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit_events(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_user_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS audit_events;
+
+COMMIT;
@@ -0,0 +1,28 @@
+-- +goose Up
+BEGIN;
+
+-- Undo stack for lobby operations. users_before/users_after hold the full state of every
+-- user the operation touched (including their budget row for the round), so an undo can
+-- restore it and detect later conflicting changes.
+CREATE TABLE IF NOT EXISTS lobby_undo_entries (
+  undo_id      BIGSERIAL PRIMARY KEY,
+  round_id     BIGINT NOT NULL REFERENCES rounds(round_id) ON DELETE CASCADE,
+  action       TEXT NOT NULL,
+  users_before JSONB NOT NULL,
+  users_after  JSONB NOT NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  undone_at    TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_lobby_undo_entries_pending
+ON lobby_undo_entries(round_id, undo_id)
+WHERE undone_at IS NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS lobby_undo_entries;
+
+COMMIT;
@@ -0,0 +1,24 @@
+-- +goose Up
+BEGIN;
+
+-- Soft deletion: removed participants stay in the table so their ratings, purchases and
+-- events still count in stats, but are hidden from the lobby and game flows.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS removed_at TIMESTAMPTZ NULL,
+  ADD COLUMN IF NOT EXISTS removed_by BIGINT NULL REFERENCES users(user_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_removed
+ON users(removed_at)
+WHERE removed_at IS NOT NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS idx_users_removed;
+ALTER TABLE users
+  DROP COLUMN IF EXISTS removed_by,
+  DROP COLUMN IF EXISTS removed_at;
+
+COMMIT;
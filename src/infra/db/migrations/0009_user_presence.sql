@@ -0,0 +1,22 @@
+-- +goose Up
+BEGIN;
+
+-- Last heartbeat from a participant's client. Presence (ONLINE/IDLE/DROPPED) is derived
+-- from this at read time using the configured timeouts; it is never stored.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ NULL;
+
+UPDATE users SET last_seen_at = joined_at WHERE last_seen_at IS NULL;
+
+ALTER TABLE users
+  ALTER COLUMN last_seen_at SET DEFAULT now(),
+  ALTER COLUMN last_seen_at SET NOT NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE users DROP COLUMN IF EXISTS last_seen_at;
+
+COMMIT;
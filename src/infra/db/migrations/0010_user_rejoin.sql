@@ -0,0 +1,30 @@
+-- +goose Up
+BEGIN;
+
+-- rejoin_secret_hash: SHA-256 of the secret handed to a participant on join, which lets
+-- their client reclaim the same user after a refresh. The secret itself is never stored.
+-- merged_into: set when an instructor merges a duplicate into another user; the duplicate's
+-- secret then resolves to the user it was merged into.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS rejoin_secret_hash TEXT NULL,
+  ADD COLUMN IF NOT EXISTS merged_into BIGINT NULL REFERENCES users(user_id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_rejoin_secret_hash
+ON users(rejoin_secret_hash)
+WHERE rejoin_secret_hash IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_display_name_lower
+ON users(lower(display_name));
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS idx_users_display_name_lower;
+DROP INDEX IF EXISTS idx_users_rejoin_secret_hash;
+ALTER TABLE users
+  DROP COLUMN IF EXISTS merged_into,
+  DROP COLUMN IF EXISTS rejoin_secret_hash;
+
+COMMIT;
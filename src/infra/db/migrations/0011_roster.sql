@@ -0,0 +1,43 @@
+-- +goose Up
+BEGIN;
+
+-- Pre-registered class roster. Each student gets a personal join code; joining with it
+-- claims the entry (user_id). Entries survive a game reset, only their claims are cleared.
+CREATE TABLE IF NOT EXISTS roster_entries (
+  roster_id      BIGSERIAL PRIMARY KEY,
+  display_name   TEXT NOT NULL,
+  student_id     TEXT NULL,
+  preferred_team INT NULL CHECK (preferred_team IS NULL OR preferred_team >= 1),
+  preferred_role user_role NULL CHECK (preferred_role IS NULL OR preferred_role IN ('JM', 'QC', 'CUSTOMER')),
+  join_code      TEXT NOT NULL UNIQUE,
+  user_id        BIGINT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+  claimed_at     TIMESTAMPTZ NULL,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roster_entries_student_id
+ON roster_entries(student_id)
+WHERE student_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_roster_entries_user_id
+ON roster_entries(user_id)
+WHERE user_id IS NOT NULL;
+
+-- Game-wide settings, a single row.
+CREATE TABLE IF NOT EXISTS game_settings (
+  singleton         BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (singleton),
+  require_join_code BOOLEAN NOT NULL DEFAULT FALSE,
+  updated_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO game_settings (singleton) VALUES (TRUE) ON CONFLICT DO NOTHING;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS game_settings;
+DROP TABLE IF EXISTS roster_entries;
+
+COMMIT;
@@ -0,0 +1,28 @@
+-- +goose Up
+BEGIN;
+
+-- Participants who join once the game is full wait here until a place frees up.
+ALTER TYPE participant_status ADD VALUE IF NOT EXISTS 'WAITLISTED';
+
+-- What happens to someone who joins after the round's teams have been assigned.
+CREATE TYPE late_joiner_policy AS ENUM ('WAIT', 'CUSTOMER', 'SMALLEST_TEAM');
+
+ALTER TABLE rounds
+  ADD COLUMN IF NOT EXISTS late_joiner_policy late_joiner_policy NOT NULL DEFAULT 'WAIT';
+
+-- NULL means no cap.
+ALTER TABLE game_settings
+  ADD COLUMN IF NOT EXISTS max_participants INT NULL CHECK (max_participants IS NULL OR max_participants >= 1);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE game_settings DROP COLUMN IF EXISTS max_participants;
+ALTER TABLE rounds DROP COLUMN IF EXISTS late_joiner_policy;
+DROP TYPE IF EXISTS late_joiner_policy;
+-- Postgres can't drop an enum value; move anyone waitlisted back to waiting instead.
+UPDATE users SET status = 'WAITING' WHERE status = 'WAITLISTED';
+
+COMMIT;
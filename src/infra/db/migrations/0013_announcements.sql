@@ -0,0 +1,52 @@
+-- +goose Up
+BEGIN;
+
+CREATE TYPE announcement_severity AS ENUM ('INFO', 'WARNING', 'CRITICAL');
+CREATE TYPE announcement_target AS ENUM ('ALL', 'ROLE', 'TEAM', 'USER');
+
+-- Instructor messages to participants. The target columns matching target are set, the
+-- others are NULL; recipients are resolved when read, so someone moved onto a targeted
+-- team later still sees the message.
+CREATE TABLE IF NOT EXISTS announcements (
+  announcement_id BIGSERIAL PRIMARY KEY,
+  title           TEXT NOT NULL,
+  body            TEXT NOT NULL DEFAULT '',
+  severity        announcement_severity NOT NULL DEFAULT 'INFO',
+  target          announcement_target NOT NULL DEFAULT 'ALL',
+  target_role     user_role NULL,
+  target_team_id  BIGINT NULL REFERENCES teams(id) ON DELETE CASCADE,
+  target_user_id  BIGINT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  expires_at      TIMESTAMPTZ NULL,
+  created_by      BIGINT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT announcements_target_chk CHECK (
+    (target = 'ALL' AND target_role IS NULL AND target_team_id IS NULL AND target_user_id IS NULL)
+    OR (target = 'ROLE' AND target_role IN ('JM', 'QC', 'CUSTOMER') AND target_team_id IS NULL AND target_user_id IS NULL)
+    OR (target = 'TEAM' AND target_role IS NULL AND target_team_id IS NOT NULL AND target_user_id IS NULL)
+    OR (target = 'USER' AND target_role IS NULL AND target_team_id IS NULL AND target_user_id IS NOT NULL)
+  )
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_created ON announcements(created_at, announcement_id);
+
+-- Read receipts.
+CREATE TABLE IF NOT EXISTS announcement_acks (
+  announcement_id BIGINT NOT NULL REFERENCES announcements(announcement_id) ON DELETE CASCADE,
+  user_id         BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  acked_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (announcement_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcement_acks_user ON announcement_acks(user_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS announcement_acks;
+DROP TABLE IF EXISTS announcements;
+DROP TYPE IF EXISTS announcement_target;
+DROP TYPE IF EXISTS announcement_severity;
+
+COMMIT;
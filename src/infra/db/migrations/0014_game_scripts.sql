@@ -0,0 +1,61 @@
+-- +goose Up
+BEGIN;
+
+-- Stored game scripts: timed steps the scheduler runs on the instructor's behalf.
+-- Scripts are reused class after class, so a game reset keeps them.
+CREATE TABLE IF NOT EXISTS game_scripts (
+  script_id  BIGSERIAL PRIMARY KEY,
+  name       TEXT NOT NULL,
+  steps      JSONB NOT NULL,
+  created_by BIGINT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TYPE script_run_status AS ENUM ('RUNNING', 'PAUSED', 'FINISHED', 'STOPPED');
+
+-- One play-through of a script. Steps are copied from the script so editing it doesn't
+-- affect a run in progress. Elapsed script time is elapsed_ms plus, while RUNNING, the
+-- time since resumed_at; next_step is the index of the first step not yet run or skipped.
+CREATE TABLE IF NOT EXISTS script_runs (
+  run_id      BIGSERIAL PRIMARY KEY,
+  script_id   BIGINT NULL REFERENCES game_scripts(script_id) ON DELETE SET NULL,
+  script_name TEXT NOT NULL,
+  steps       JSONB NOT NULL,
+  status      script_run_status NOT NULL DEFAULT 'RUNNING',
+  next_step   INT NOT NULL DEFAULT 0,
+  elapsed_ms  BIGINT NOT NULL DEFAULT 0,
+  resumed_at  TIMESTAMPTZ NULL,
+  started_by  BIGINT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+  started_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ended_at    TIMESTAMPTZ NULL
+);
+
+-- At most one run is in progress at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_script_runs_current
+ON script_runs((TRUE))
+WHERE status IN ('RUNNING', 'PAUSED');
+
+CREATE TABLE IF NOT EXISTS script_step_log (
+  log_id      BIGSERIAL PRIMARY KEY,
+  run_id      BIGINT NOT NULL REFERENCES script_runs(run_id) ON DELETE CASCADE,
+  step_index  INT NOT NULL,
+  action      TEXT NOT NULL,
+  outcome     TEXT NOT NULL,
+  error       TEXT NOT NULL DEFAULT '',
+  executed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_script_step_log_run ON script_step_log(run_id, log_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS script_step_log;
+DROP TABLE IF EXISTS script_runs;
+DROP TYPE IF EXISTS script_run_status;
+DROP TABLE IF EXISTS game_scripts;
+
+COMMIT;
@@ -0,0 +1,45 @@
+-- +goose Up
+BEGIN;
+
+-- Some rounds deliberately forbid JM/QC talking.
+ALTER TABLE rounds
+  ADD COLUMN IF NOT EXISTS chat_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Chat messages. team_id NULL is the round channel, where the instructor posts to
+-- everyone; otherwise it is that team's channel. sender_role is the sender's role when
+-- they posted, for stats.
+CREATE TABLE IF NOT EXISTS chat_messages (
+  message_id     BIGSERIAL PRIMARY KEY,
+  round_id       BIGINT NOT NULL REFERENCES rounds(round_id) ON DELETE CASCADE,
+  team_id        BIGINT NULL REFERENCES teams(id) ON DELETE CASCADE,
+  sender_user_id BIGINT NULL REFERENCES users(user_id) ON DELETE SET NULL,
+  sender_role    user_role NULL,
+  body           TEXT NOT NULL,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_messages_channel ON chat_messages(round_id, team_id, message_id);
+
+-- Read receipts: each reader's position in a channel. Everything up to
+-- last_read_message_id counts as read.
+CREATE TABLE IF NOT EXISTS chat_read_cursors (
+  round_id             BIGINT NOT NULL REFERENCES rounds(round_id) ON DELETE CASCADE,
+  team_id              BIGINT NULL REFERENCES teams(id) ON DELETE CASCADE,
+  user_id              BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  last_read_message_id BIGINT NOT NULL,
+  read_at              TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_chat_read_cursors_reader
+ON chat_read_cursors(round_id, (COALESCE(team_id, 0)), user_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS chat_read_cursors;
+DROP TABLE IF EXISTS chat_messages;
+ALTER TABLE rounds DROP COLUMN IF EXISTS chat_enabled;
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- +goose Up
+BEGIN;
+
+-- What each buy charged or each return refunded. The market price can change mid-round,
+-- so a customer's history needs the amount at the time to reconcile with their budget.
+ALTER TABLE purchase_events
+  ADD COLUMN IF NOT EXISTS price NUMERIC(10,2) NULL;
+
+-- Older events predate the column; the round's current price is the best we know.
+UPDATE purchase_events pe
+SET price = r.market_price
+FROM rounds r
+WHERE r.round_id = pe.round_id AND pe.price IS NULL;
+
+ALTER TABLE purchase_events
+  ALTER COLUMN price SET NOT NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE purchase_events DROP COLUMN IF EXISTS price;
+
+COMMIT;
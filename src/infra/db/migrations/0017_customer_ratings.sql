@@ -0,0 +1,26 @@
+-- +goose Up
+BEGIN;
+
+-- A customer's opinion of a joke they bought. It only lives as long as the purchase:
+-- returning the joke deletes the rating.
+CREATE TABLE IF NOT EXISTS customer_ratings (
+  round_id         BIGINT NOT NULL REFERENCES rounds(round_id) ON DELETE CASCADE,
+  customer_user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  joke_id          BIGINT NOT NULL REFERENCES published_jokes(joke_id) ON DELETE CASCADE,
+  rating           INT NOT NULL CHECK (rating >= 1 AND rating <= 5),
+  review           TEXT NULL CHECK (char_length(review) <= 500),
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (customer_user_id, joke_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_ratings_round_joke ON customer_ratings(round_id, joke_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS customer_ratings;
+
+COMMIT;
@@ -0,0 +1,50 @@
+-- +goose Up
+BEGIN;
+
+-- FLAT sells every joke at the round's market_price; TEAM_SET lets each team price its
+-- own jokes between min_joke_price and max_joke_price.
+CREATE TYPE pricing_mode AS ENUM ('FLAT', 'TEAM_SET');
+
+ALTER TABLE rounds
+  ADD COLUMN IF NOT EXISTS pricing_mode pricing_mode NOT NULL DEFAULT 'FLAT',
+  ADD COLUMN IF NOT EXISTS min_joke_price NUMERIC(8,2) NULL CHECK (min_joke_price >= 0),
+  ADD COLUMN IF NOT EXISTS max_joke_price NUMERIC(8,2) NULL CHECK (max_joke_price >= 0);
+
+-- The team's asking price; NULL means the round's market_price. It can be set before or
+-- after the joke is published.
+ALTER TABLE jokes
+  ADD COLUMN IF NOT EXISTS team_price NUMERIC(8,2) NULL CHECK (team_price >= 0);
+
+-- What the customer actually paid, so profit and refunds don't depend on today's price.
+ALTER TABLE purchases
+  ADD COLUMN IF NOT EXISTS price NUMERIC(10,2) NULL;
+
+-- Existing purchases paid what their latest buy event recorded.
+UPDATE purchases p
+SET price = COALESCE((
+  SELECT pe.price
+  FROM purchase_events pe
+  WHERE pe.round_id = p.round_id AND pe.customer_user_id = p.customer_user_id
+    AND pe.joke_id = p.joke_id AND pe.delta = 1
+  ORDER BY pe.event_id DESC
+  LIMIT 1
+), (SELECT market_price FROM rounds r WHERE r.round_id = p.round_id))
+WHERE p.price IS NULL;
+
+ALTER TABLE purchases
+  ALTER COLUMN price SET NOT NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE purchases DROP COLUMN IF EXISTS price;
+ALTER TABLE jokes DROP COLUMN IF EXISTS team_price;
+ALTER TABLE rounds
+  DROP COLUMN IF EXISTS max_joke_price,
+  DROP COLUMN IF EXISTS min_joke_price,
+  DROP COLUMN IF EXISTS pricing_mode;
+DROP TYPE IF EXISTS pricing_mode;
+
+COMMIT;
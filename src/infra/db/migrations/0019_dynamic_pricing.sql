@@ -0,0 +1,38 @@
+-- +goose Up
+BEGIN;
+
+-- DYNAMIC moves each joke's price with demand: a sale raises it by price_step and it
+-- decays by price_decay per minute without one, kept between min_joke_price and
+-- max_joke_price.
+ALTER TYPE pricing_mode ADD VALUE IF NOT EXISTS 'DYNAMIC';
+
+ALTER TABLE rounds
+  ADD COLUMN IF NOT EXISTS price_step NUMERIC(8,2) NOT NULL DEFAULT 0 CHECK (price_step >= 0),
+  ADD COLUMN IF NOT EXISTS price_decay NUMERIC(8,2) NOT NULL DEFAULT 0 CHECK (price_decay >= 0);
+
+-- Every time a joke's dynamic price is set: when dynamic pricing starts (sale_price NULL)
+-- and after each sale. The latest row is where the price decays from.
+CREATE TABLE IF NOT EXISTS joke_price_changes (
+  change_id  BIGSERIAL PRIMARY KEY,
+  round_id   BIGINT NOT NULL REFERENCES rounds(round_id) ON DELETE CASCADE,
+  joke_id    BIGINT NOT NULL REFERENCES published_jokes(joke_id) ON DELETE CASCADE,
+  sale_price NUMERIC(10,2) NULL,
+  price      NUMERIC(10,2) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_joke_price_changes_joke ON joke_price_changes(joke_id, change_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS joke_price_changes;
+ALTER TABLE rounds
+  DROP COLUMN IF EXISTS price_decay,
+  DROP COLUMN IF EXISTS price_step;
+-- Postgres can't drop an enum value; fall back to flat pricing instead.
+UPDATE rounds SET pricing_mode = 'FLAT' WHERE pricing_mode = 'DYNAMIC';
+
+COMMIT;
@@ -0,0 +1,53 @@
+-- +goose Up
+BEGIN;
+
+-- AUCTION sells each published joke by sealed bid instead of at a fixed price. Bidding on
+-- a joke stays open for auction_window_seconds from its publication (or from when the
+-- round switched to auctions, if later); the highest bid then wins and pays its own bid
+-- (FIRST_PRICE) or the runner-up's (SECOND_PRICE).
+ALTER TYPE pricing_mode ADD VALUE IF NOT EXISTS 'AUCTION';
+
+CREATE TYPE auction_rule AS ENUM ('FIRST_PRICE', 'SECOND_PRICE');
+
+ALTER TABLE rounds
+  ADD COLUMN IF NOT EXISTS auction_rule auction_rule NOT NULL DEFAULT 'FIRST_PRICE',
+  ADD COLUMN IF NOT EXISTS auction_window_seconds INT NOT NULL DEFAULT 60 CHECK (auction_window_seconds > 0),
+  ADD COLUMN IF NOT EXISTS auction_started_at TIMESTAMPTZ NULL;
+
+CREATE TYPE bid_status AS ENUM ('OPEN', 'WON', 'LOST');
+
+-- One sealed bid per customer and joke. An open bid's amount is held back from the
+-- customer's remaining budget until the auction closes; price is what a winning bid paid.
+CREATE TABLE IF NOT EXISTS joke_bids (
+  bid_id           BIGSERIAL PRIMARY KEY,
+  round_id         BIGINT NOT NULL REFERENCES rounds(round_id) ON DELETE CASCADE,
+  joke_id          BIGINT NOT NULL REFERENCES published_jokes(joke_id) ON DELETE CASCADE,
+  customer_user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  amount           NUMERIC(10,2) NOT NULL CHECK (amount > 0),
+  status           bid_status NOT NULL DEFAULT 'OPEN',
+  price            NUMERIC(10,2) NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  settled_at       TIMESTAMPTZ NULL,
+  UNIQUE (joke_id, customer_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_joke_bids_open ON joke_bids(joke_id) WHERE status = 'OPEN';
+CREATE INDEX IF NOT EXISTS idx_joke_bids_round_customer ON joke_bids(round_id, customer_user_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS joke_bids;
+DROP TYPE IF EXISTS bid_status;
+ALTER TABLE rounds
+  DROP COLUMN IF EXISTS auction_started_at,
+  DROP COLUMN IF EXISTS auction_window_seconds,
+  DROP COLUMN IF EXISTS auction_rule;
+DROP TYPE IF EXISTS auction_rule;
+-- Postgres can't drop an enum value; fall back to flat pricing instead.
+UPDATE rounds SET pricing_mode = 'FLAT' WHERE pricing_mode = 'AUCTION';
+
+COMMIT;
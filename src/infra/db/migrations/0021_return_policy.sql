@@ -0,0 +1,25 @@
+-- +goose Up
+BEGIN;
+
+-- How customers may return purchases in a round. NULL window or max means no limit; a
+-- return refunds return_refund_percent of what was paid. The defaults keep the old
+-- behaviour: unlimited returns at any time for a full refund.
+ALTER TABLE rounds
+  ADD COLUMN IF NOT EXISTS returns_allowed BOOLEAN NOT NULL DEFAULT TRUE,
+  ADD COLUMN IF NOT EXISTS return_window_seconds INT NULL CHECK (return_window_seconds > 0),
+  ADD COLUMN IF NOT EXISTS max_returns INT NULL CHECK (max_returns >= 0),
+  ADD COLUMN IF NOT EXISTS return_refund_percent INT NOT NULL DEFAULT 100
+    CHECK (return_refund_percent >= 0 AND return_refund_percent <= 100);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE rounds
+  DROP COLUMN IF EXISTS return_refund_percent,
+  DROP COLUMN IF EXISTS max_returns,
+  DROP COLUMN IF EXISTS return_window_seconds,
+  DROP COLUMN IF EXISTS returns_allowed;
+
+COMMIT;
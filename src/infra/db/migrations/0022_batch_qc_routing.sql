@@ -0,0 +1,25 @@
+-- +goose Up
+BEGIN;
+
+-- The QC routing a batch was submitted under, so queue history survives the instructor
+-- changing the round's mode. Existing batches take their round's current mode.
+ALTER TABLE batches
+  ADD COLUMN IF NOT EXISTS qc_routing_mode qc_routing_mode NULL;
+
+UPDATE batches b
+SET qc_routing_mode = r.qc_routing_mode
+FROM rounds r
+WHERE r.round_id = b.round_id AND b.qc_routing_mode IS NULL;
+
+ALTER TABLE batches
+  ALTER COLUMN qc_routing_mode SET NOT NULL,
+  ALTER COLUMN qc_routing_mode SET DEFAULT 'OWN_TEAM';
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE batches DROP COLUMN IF EXISTS qc_routing_mode;
+
+COMMIT;
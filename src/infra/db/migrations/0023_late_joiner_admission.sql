@@ -0,0 +1,16 @@
+-- +goose Up
+BEGIN;
+
+-- When the round's late-joiner policy seated the participant. They are seated at most
+-- once, so an instructor moving them back to waiting (or undoing the seat) sticks.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS late_admitted_at TIMESTAMPTZ NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE users DROP COLUMN IF EXISTS late_admitted_at;
+
+COMMIT;
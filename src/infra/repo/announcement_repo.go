@@ -0,0 +1,161 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// announcementColumns lists the columns scanned by scanAnnouncement; the table is aliased a.
+const announcementColumns = `a.announcement_id, a.title, a.body, a.severity::text, a.target::text, a.target_role::text,
+	a.target_team_id, a.target_user_id, a.expires_at, a.created_by, a.created_at`
+
+// announcementRecipientQ matches users u who receive announcement a; it mirrors
+// domain.Announcement.AppliesTo.
+const announcementRecipientQ = `
+	u.removed_at IS NULL
+	AND u.role IS DISTINCT FROM 'INSTRUCTOR'
+	AND (a.target = 'ALL'
+	     OR (a.target = 'ROLE' AND u.role = a.target_role)
+	     OR (a.target = 'TEAM' AND u.team_id = a.target_team_id)
+	     OR (a.target = 'USER' AND u.user_id = a.target_user_id))
+`
+
+// scanAnnouncement scans announcementColumns followed by any extra destinations.
+func scanAnnouncement(row pgx.Row, extra ...any) (*domain.Announcement, error) {
+	var a domain.Announcement
+	dest := append([]any{&a.ID, &a.Title, &a.Body, &a.Severity, &a.Target, &a.TargetRole,
+		&a.TargetTeamID, &a.TargetUserID, &a.ExpiresAt, &a.CreatedBy, &a.CreatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *PostgresRepository) CreateAnnouncement(ctx context.Context, a *domain.Announcement) error {
+	const q = `
+		INSERT INTO announcements (title, body, severity, target, target_role, target_team_id,
+		                           target_user_id, expires_at, created_by)
+		VALUES ($1, $2, $3::announcement_severity, $4::announcement_target, $5::user_role, $6, $7, $8, $9)
+		RETURNING announcement_id, created_at
+	`
+	return r.pool.QueryRow(ctx, q, a.Title, a.Body, string(a.Severity), string(a.Target), a.TargetRole, a.TargetTeamID,
+		a.TargetUserID, a.ExpiresAt, a.CreatedBy).Scan(&a.ID, &a.CreatedAt)
+}
+
+func (r *PostgresRepository) GetAnnouncement(ctx context.Context, announcementID int64) (*domain.Announcement, error) {
+	a, err := scanAnnouncement(r.pool.QueryRow(ctx, `SELECT `+announcementColumns+` FROM announcements a WHERE a.announcement_id = $1`, announcementID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("announcement")
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+func (r *PostgresRepository) ListAnnouncements(ctx context.Context) ([]ports.AnnouncementSummary, error) {
+	q := `
+		SELECT ` + announcementColumns + `, rc.recipients, rc.acknowledged
+		FROM announcements a
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*)::int AS recipients, COUNT(k.acked_at)::int AS acknowledged
+			FROM users u
+			LEFT JOIN announcement_acks k ON k.announcement_id = a.announcement_id AND k.user_id = u.user_id
+			WHERE ` + announcementRecipientQ + `
+		) rc
+		ORDER BY a.created_at DESC, a.announcement_id DESC
+	`
+	rows, err := r.pool.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ports.AnnouncementSummary{}
+	for rows.Next() {
+		var s ports.AnnouncementSummary
+		a, err := scanAnnouncement(rows, &s.Recipients, &s.Acknowledged)
+		if err != nil {
+			return nil, err
+		}
+		s.Announcement = *a
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *PostgresRepository) ListAnnouncementsForUser(ctx context.Context, userID int64) ([]ports.UserAnnouncement, error) {
+	q := `
+		SELECT ` + announcementColumns + `, k.acked_at
+		FROM announcements a
+		JOIN users u ON u.user_id = $1
+		LEFT JOIN announcement_acks k ON k.announcement_id = a.announcement_id AND k.user_id = u.user_id
+		WHERE (a.expires_at IS NULL OR a.expires_at > now())
+		  AND ` + announcementRecipientQ + `
+		ORDER BY a.created_at DESC, a.announcement_id DESC
+	`
+	rows, err := r.pool.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ports.UserAnnouncement{}
+	for rows.Next() {
+		var ua ports.UserAnnouncement
+		a, err := scanAnnouncement(rows, &ua.AckedAt)
+		if err != nil {
+			return nil, err
+		}
+		ua.Announcement = *a
+		out = append(out, ua)
+	}
+	return out, rows.Err()
+}
+
+func (r *PostgresRepository) AckAnnouncement(ctx context.Context, announcementID, userID int64) (time.Time, error) {
+	// The no-op update makes RETURNING yield the existing receipt on a repeat ack.
+	const q = `
+		INSERT INTO announcement_acks (announcement_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (announcement_id, user_id) DO UPDATE SET acked_at = announcement_acks.acked_at
+		RETURNING acked_at
+	`
+	var ackedAt time.Time
+	if err := r.pool.QueryRow(ctx, q, announcementID, userID).Scan(&ackedAt); err != nil {
+		return time.Time{}, err
+	}
+	return ackedAt, nil
+}
+
+func (r *PostgresRepository) ListAnnouncementRecipients(ctx context.Context, announcementID int64) ([]ports.AnnouncementRecipient, error) {
+	q := `
+		SELECT u.user_id, u.display_name, u.role::text, u.team_id, k.acked_at
+		FROM announcements a
+		JOIN users u ON ` + announcementRecipientQ + `
+		LEFT JOIN announcement_acks k ON k.announcement_id = a.announcement_id AND k.user_id = u.user_id
+		WHERE a.announcement_id = $1
+		ORDER BY k.acked_at NULLS LAST, u.display_name, u.user_id
+	`
+	rows, err := r.pool.Query(ctx, q, announcementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ports.AnnouncementRecipient{}
+	for rows.Next() {
+		var rc ports.AnnouncementRecipient
+		if err := rows.Scan(&rc.UserID, &rc.DisplayName, &rc.Role, &rc.TeamID, &rc.AckedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, rc)
+	}
+	return out, rows.Err()
+}
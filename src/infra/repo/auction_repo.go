@@ -0,0 +1,273 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// auctionClosesAt is when bidding on a published joke (pj) ends in its round (r): the
+// window runs from publication, or from the switch to auctions if that came later.
+const auctionClosesAt = `(GREATEST(pj.created_at, COALESCE(r.auction_started_at, pj.created_at)) + make_interval(secs => r.auction_window_seconds))`
+
+// bidColumns lists the columns scanned by scanBid; bids are aliased b and joined to
+// their published joke pj and round r.
+const bidColumns = `b.bid_id, b.round_id, b.joke_id, b.customer_user_id, b.amount::float8, b.status, b.price::float8,
+	` + auctionClosesAt + `, b.created_at, b.updated_at, b.settled_at`
+
+func scanBid(row pgx.Row) (*domain.Bid, error) {
+	var b domain.Bid
+	if err := row.Scan(&b.ID, &b.RoundID, &b.JokeID, &b.CustomerUserID, &b.Amount, &b.Status, &b.Price,
+		&b.ClosesAt, &b.CreatedAt, &b.UpdatedAt, &b.SettledAt); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *PostgresRepository) PlaceBid(ctx context.Context, roundID, customerID, jokeID int64, amount float64) (*domain.Bid, *domain.CustomerRoundBudget, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the joke so a bid can't slip in while its auction is being settled.
+	const openQ = `
+		SELECT now() < ` + auctionClosesAt + ` AND r.ended_at IS NULL
+		FROM published_jokes pj
+		JOIN rounds r ON r.round_id = pj.round_id
+		WHERE pj.joke_id = $1 AND pj.round_id = $2
+		FOR UPDATE OF pj
+	`
+	var open bool
+	if err := tx.QueryRow(ctx, openQ, jokeID, roundID).Scan(&open); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, domain.NewNotFoundError("joke")
+		}
+		return nil, nil, err
+	}
+	if !open {
+		return nil, nil, domain.NewConflictError("auction closed")
+	}
+
+	var held bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM purchases WHERE round_id = $1 AND customer_user_id = $2 AND joke_id = $3)`, roundID, customerID, jokeID).Scan(&held); err != nil {
+		return nil, nil, err
+	}
+	if held {
+		return nil, nil, domain.NewConflictError("already bought")
+	}
+
+	// A settled bid has already been refunded or paid, so only an open one is still held.
+	var previous float64
+	if err := tx.QueryRow(ctx, `SELECT amount::float8 FROM joke_bids WHERE joke_id = $1 AND customer_user_id = $2 AND status = 'OPEN' FOR UPDATE`, jokeID, customerID).Scan(&previous); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, err
+	}
+
+	// Only the difference to an earlier bid is held back on top of what already is.
+	const holdQ = `
+		UPDATE customer_round_budget
+		SET remaining_budget = remaining_budget - $3, updated_at = now()
+		WHERE round_id = $1 AND customer_user_id = $2 AND remaining_budget >= $3
+	`
+	tag, err := tx.Exec(ctx, holdQ, roundID, customerID, amount-previous)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil, domain.NewConflictError("insufficient budget")
+	}
+
+	const bidQ = `
+		WITH b AS (
+			INSERT INTO joke_bids (round_id, joke_id, customer_user_id, amount)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (joke_id, customer_user_id) DO UPDATE
+			SET amount = EXCLUDED.amount, status = 'OPEN', price = NULL, settled_at = NULL, updated_at = now()
+			RETURNING *
+		)
+		SELECT ` + bidColumns + `
+		FROM b
+		JOIN published_jokes pj ON pj.joke_id = b.joke_id
+		JOIN rounds r ON r.round_id = b.round_id
+	`
+	bid, err := scanBid(tx.QueryRow(ctx, bidQ, roundID, jokeID, customerID, amount))
+	if err != nil {
+		return nil, nil, err
+	}
+	budget, err := r.getCustomerBudgetTx(ctx, tx, roundID, customerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return bid, budget, nil
+}
+
+func (r *PostgresRepository) ListCustomerBids(ctx context.Context, roundID, customerID int64) ([]domain.Bid, error) {
+	const q = `
+		SELECT ` + bidColumns + `
+		FROM joke_bids b
+		JOIN published_jokes pj ON pj.joke_id = b.joke_id
+		JOIN rounds r ON r.round_id = b.round_id
+		WHERE b.round_id = $1 AND b.customer_user_id = $2
+		ORDER BY b.updated_at DESC, b.bid_id DESC
+	`
+	rows, err := r.pool.Query(ctx, q, roundID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bids := []domain.Bid{}
+	for rows.Next() {
+		b, err := scanBid(rows)
+		if err != nil {
+			return nil, err
+		}
+		bids = append(bids, *b)
+	}
+	return bids, rows.Err()
+}
+
+func (r *PostgresRepository) ListDueAuctions(ctx context.Context) ([]int64, error) {
+	const q = `
+		SELECT DISTINCT b.joke_id
+		FROM joke_bids b
+		JOIN published_jokes pj ON pj.joke_id = b.joke_id
+		JOIN rounds r ON r.round_id = b.round_id
+		WHERE b.status = 'OPEN' AND (now() >= ` + auctionClosesAt + ` OR r.ended_at IS NOT NULL)
+		ORDER BY b.joke_id
+	`
+	rows, err := r.pool.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *PostgresRepository) SettleAuction(ctx context.Context, jokeID int64) (*ports.AuctionResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// The same lock PlaceBid takes, so no bid changes while the auction closes and a
+	// second closer waits, then finds nothing left to settle.
+	const dueQ = `
+		SELECT pj.round_id, pj.team_id, now() >= ` + auctionClosesAt + ` OR r.ended_at IS NOT NULL
+		FROM published_jokes pj
+		JOIN rounds r ON r.round_id = pj.round_id
+		WHERE pj.joke_id = $1
+		FOR UPDATE OF pj
+	`
+	res := &ports.AuctionResult{JokeID: jokeID}
+	var due bool
+	if err := tx.QueryRow(ctx, dueQ, jokeID).Scan(&res.RoundID, &res.TeamID, &due); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !due {
+		return nil, nil
+	}
+	round, err := scanRound(tx.QueryRow(ctx, `SELECT `+roundColumns+` FROM rounds WHERE round_id = $1`, res.RoundID))
+	if err != nil {
+		return nil, err
+	}
+
+	// Highest bid first; on a tie the bid that got there first wins. A bidder who came to
+	// hold the joke some other way can't win it again.
+	const bidsQ = `
+		SELECT b.bid_id, b.customer_user_id, b.amount::float8,
+		       EXISTS (SELECT 1 FROM purchases p WHERE p.joke_id = b.joke_id AND p.customer_user_id = b.customer_user_id)
+		FROM joke_bids b
+		WHERE b.joke_id = $1 AND b.status = 'OPEN'
+		ORDER BY b.amount DESC, b.updated_at, b.bid_id
+		FOR UPDATE OF b
+	`
+	type openBid struct {
+		id, customerID int64
+		amount         float64
+		held           bool
+	}
+	rows, err := tx.Query(ctx, bidsQ, jokeID)
+	if err != nil {
+		return nil, err
+	}
+	var bids []openBid
+	var amounts []float64
+	for rows.Next() {
+		var b openBid
+		if err := rows.Scan(&b.id, &b.customerID, &b.amount, &b.held); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		bids = append(bids, b)
+		if !b.held {
+			amounts = append(amounts, b.amount)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(bids) == 0 {
+		return nil, nil
+	}
+	res.Bids = len(bids)
+	res.Price = round.ClearingPrice(amounts)
+
+	const refundQ = `UPDATE customer_round_budget SET remaining_budget = remaining_budget + $3, updated_at = now() WHERE round_id = $1 AND customer_user_id = $2`
+	const closeQ = `UPDATE joke_bids SET status = $2, price = $3, settled_at = now() WHERE bid_id = $1`
+	for _, b := range bids {
+		if res.WinnerUserID != nil || b.held {
+			if _, err := tx.Exec(ctx, refundQ, res.RoundID, b.customerID, b.amount); err != nil {
+				return nil, err
+			}
+			if _, err := tx.Exec(ctx, closeQ, b.id, domain.BidLost, nil); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		winner := b.customerID
+		res.WinnerUserID = &winner
+		if _, err := tx.Exec(ctx, refundQ, res.RoundID, winner, b.amount-res.Price); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, closeQ, b.id, domain.BidWon, res.Price); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO purchases (round_id, customer_user_id, joke_id, price) VALUES ($1, $2, $3, $4)`, res.RoundID, winner, jokeID, res.Price); err != nil {
+			return nil, err
+		}
+		if err := r.updateTeamPoints(ctx, tx, res.RoundID, res.TeamID, 1); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO purchase_events (round_id, customer_user_id, joke_id, team_id, delta, price) VALUES ($1, $2, $3, $4, 1, $5)`, res.RoundID, winner, jokeID, res.TeamID, res.Price); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"context"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+func (r *PostgresRepository) InsertAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	const q = `
+		INSERT INTO audit_events (actor_user_id, actor_display_name, action, target_type, target_id,
+		                          round_id, before_state, after_state, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, $8::jsonb, $9)
+		RETURNING event_id, created_at
+	`
+	// Pass JSON as text so empty states become NULL rather than invalid jsonb.
+	var before, after *string
+	if len(event.Before) > 0 {
+		s := string(event.Before)
+		before = &s
+	}
+	if len(event.After) > 0 {
+		s := string(event.After)
+		after = &s
+	}
+	return r.pool.QueryRow(ctx, q, event.ActorUserID, event.ActorDisplayName, event.Action, event.TargetType,
+		event.TargetID, event.RoundID, before, after, event.RequestID).Scan(&event.ID, &event.CreatedAt)
+}
+
+func (r *PostgresRepository) ListAuditEvents(ctx context.Context, filter ports.AuditFilter) ([]domain.AuditEvent, int64, error) {
+	const where = `
+		WHERE ($1::bigint IS NULL OR actor_user_id = $1)
+		  AND ($2 = '' OR action = $2)
+		  AND ($3 = '' OR target_type = $3)
+		  AND ($4::bigint IS NULL OR target_id = $4)
+		  AND ($5::bigint IS NULL OR round_id = $5)
+		  AND ($6::timestamptz IS NULL OR created_at >= $6)
+		  AND ($7::timestamptz IS NULL OR created_at < $7)
+	`
+	args := []any{filter.ActorUserID, filter.Action, filter.TargetType, filter.TargetID, filter.RoundID, filter.Since, filter.Until}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_events `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	q := `
+		SELECT event_id, actor_user_id, actor_display_name, action, target_type, target_id, round_id,
+		       before_state::text, after_state::text, request_id, created_at
+		FROM audit_events
+	` + where + `
+		ORDER BY created_at DESC, event_id DESC
+		LIMIT $8 OFFSET $9
+	`
+	rows, err := r.pool.Query(ctx, q, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := []domain.AuditEvent{}
+	for rows.Next() {
+		var e domain.AuditEvent
+		var before, after *string
+		if err := rows.Scan(&e.ID, &e.ActorUserID, &e.ActorDisplayName, &e.Action, &e.TargetType, &e.TargetID,
+			&e.RoundID, &before, &after, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if before != nil {
+			e.Before = []byte(*before)
+		}
+		if after != nil {
+			e.After = []byte(*after)
+		}
+		out = append(out, e)
+	}
+	return out, total, rows.Err()
+}
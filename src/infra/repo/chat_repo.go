@@ -0,0 +1,125 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"jokefactory/src/core/domain"
+)
+
+// chatMessageColumns lists the columns scanned into a domain.ChatMessage; messages are
+// aliased m and joined to their sender u.
+const chatMessageColumns = `m.message_id, m.round_id, m.team_id, m.sender_user_id, COALESCE(u.display_name, ''),
+	m.sender_role, m.body, m.created_at`
+
+func (r *PostgresRepository) CreateChatMessage(ctx context.Context, msg *domain.ChatMessage) error {
+	const q = `
+		WITH m AS (
+			INSERT INTO chat_messages (round_id, team_id, sender_user_id, sender_role, body)
+			SELECT $1, $2, u.user_id, u.role, $4
+			FROM users u
+			WHERE u.user_id = $3
+			RETURNING *
+		)
+		SELECT ` + chatMessageColumns + `
+		FROM m
+		LEFT JOIN users u ON u.user_id = m.sender_user_id
+	`
+	if err := r.pool.QueryRow(ctx, q, msg.RoundID, msg.TeamID, msg.SenderID, msg.Body).Scan(&msg.ID, &msg.RoundID,
+		&msg.TeamID, &msg.SenderID, &msg.SenderName, &msg.SenderRole, &msg.Body, &msg.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewNotFoundError("user")
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresRepository) ListChatMessages(ctx context.Context, roundID int64, teamID *int64, afterID int64, limit int) ([]domain.ChatMessage, error) {
+	// Without a cursor the caller wants the latest page, so take it newest first and
+	// flip it back to oldest first.
+	order := "DESC"
+	if afterID > 0 {
+		order = "ASC"
+	}
+	q := `
+		SELECT * FROM (
+			SELECT ` + chatMessageColumns + `
+			FROM chat_messages m
+			LEFT JOIN users u ON u.user_id = m.sender_user_id
+			WHERE m.round_id = $1 AND m.team_id IS NOT DISTINCT FROM $2
+			  AND m.message_id > $3
+			ORDER BY m.message_id ` + order + `
+			LIMIT $4
+		) page
+		ORDER BY message_id
+	`
+	rows, err := r.pool.Query(ctx, q, roundID, teamID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.ChatMessage{}
+	for rows.Next() {
+		var m domain.ChatMessage
+		if err := rows.Scan(&m.ID, &m.RoundID, &m.TeamID, &m.SenderID, &m.SenderName, &m.SenderRole, &m.Body, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (r *PostgresRepository) MarkChatRead(ctx context.Context, roundID int64, teamID *int64, userID, messageID int64) (*domain.ChatReadCursor, error) {
+	const q = `
+		INSERT INTO chat_read_cursors (round_id, team_id, user_id, last_read_message_id)
+		SELECT m.round_id, m.team_id, $3, m.message_id
+		FROM chat_messages m
+		WHERE m.message_id = $4 AND m.round_id = $1 AND m.team_id IS NOT DISTINCT FROM $2
+		ON CONFLICT (round_id, (COALESCE(team_id, 0)), user_id) DO UPDATE
+		SET last_read_message_id = GREATEST(chat_read_cursors.last_read_message_id, EXCLUDED.last_read_message_id),
+		    read_at = CASE
+		        WHEN EXCLUDED.last_read_message_id > chat_read_cursors.last_read_message_id THEN now()
+		        ELSE chat_read_cursors.read_at
+		    END
+		RETURNING round_id, team_id, user_id, last_read_message_id, read_at,
+		          (SELECT display_name FROM users WHERE user_id = $3)
+	`
+	var c domain.ChatReadCursor
+	if err := r.pool.QueryRow(ctx, q, roundID, teamID, userID, messageID).Scan(&c.RoundID, &c.TeamID, &c.UserID,
+		&c.LastReadMessageID, &c.ReadAt, &c.DisplayName); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("message")
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *PostgresRepository) ListChatReadCursors(ctx context.Context, roundID int64, teamID *int64) ([]domain.ChatReadCursor, error) {
+	const q = `
+		SELECT c.round_id, c.team_id, c.user_id, u.display_name, c.last_read_message_id, c.read_at
+		FROM chat_read_cursors c
+		JOIN users u ON u.user_id = c.user_id
+		WHERE c.round_id = $1 AND c.team_id IS NOT DISTINCT FROM $2
+		ORDER BY c.user_id
+	`
+	rows, err := r.pool.Query(ctx, q, roundID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.ChatReadCursor{}
+	for rows.Next() {
+		var c domain.ChatReadCursor
+		if err := rows.Scan(&c.RoundID, &c.TeamID, &c.UserID, &c.DisplayName, &c.LastReadMessageID, &c.ReadAt); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
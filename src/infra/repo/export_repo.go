@@ -0,0 +1,165 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// exportQuery is a raw table export. Queries take $1 = round id and $2 = team id, both
+// nullable; tables that aren't round-scoped set teamOnly and take the team id as $1.
+type exportQuery struct {
+	header   []string
+	sql      string
+	teamOnly bool
+}
+
+var exportQueries = map[ports.ExportEntity]exportQuery{
+	ports.ExportUsers: {
+		header: []string{"user_id", "display_name", "role", "team_id", "status", "assigned_at", "joined_at", "created_at", "removed_at", "removed_by"},
+		sql: `
+			SELECT user_id, display_name, role::text, team_id, status::text, assigned_at, joined_at, created_at, removed_at, removed_by
+			FROM users
+			WHERE ($1::bigint IS NULL OR team_id = $1)
+			ORDER BY user_id
+		`,
+		teamOnly: true,
+	},
+	ports.ExportTeams: {
+		header: []string{"team_id", "name", "created_at"},
+		sql: `
+			SELECT id, name, created_at
+			FROM teams
+			WHERE ($1::bigint IS NULL OR id IN (SELECT team_id FROM team_rounds_state WHERE round_id = $1))
+			  AND ($2::bigint IS NULL OR id = $2)
+			ORDER BY id
+		`,
+	},
+	ports.ExportBatches: {
+		header: []string{"batch_id", "round_id", "team_id", "status", "submitted_at", "locked_at", "locked_by_qc", "rated_at", "avg_score", "passes_count", "feedback", "created_at"},
+		sql: `
+			SELECT batch_id, round_id, team_id, status::text, submitted_at, locked_at, locked_by_qc, rated_at,
+			       avg_score::float8, passes_count, feedback, created_at
+			FROM batches
+			WHERE ($1::bigint IS NULL OR round_id = $1)
+			  AND ($2::bigint IS NULL OR team_id = $2)
+			ORDER BY batch_id
+		`,
+	},
+	ports.ExportJokes: {
+		header: []string{"joke_id", "batch_id", "round_id", "team_id", "joke_title", "joke_text", "rating", "tag", "qc_user_id", "rated_at", "is_published", "created_at"},
+		sql: `
+			SELECT j.joke_id, j.batch_id, b.round_id, b.team_id, j.joke_title, j.joke_text,
+			       jr.rating, jr.tag::text, jr.qc_user_id, jr.rated_at, (pj.joke_id IS NOT NULL), j.created_at
+			FROM jokes j
+			JOIN batches b ON b.batch_id = j.batch_id
+			LEFT JOIN joke_ratings jr ON jr.joke_id = j.joke_id
+			LEFT JOIN published_jokes pj ON pj.joke_id = j.joke_id
+			WHERE ($1::bigint IS NULL OR b.round_id = $1)
+			  AND ($2::bigint IS NULL OR b.team_id = $2)
+			ORDER BY j.joke_id
+		`,
+	},
+	ports.ExportPublishedJokes: {
+		header: []string{"joke_id", "round_id", "team_id", "created_at"},
+		sql: `
+			SELECT joke_id, round_id, team_id, created_at
+			FROM published_jokes
+			WHERE ($1::bigint IS NULL OR round_id = $1)
+			  AND ($2::bigint IS NULL OR team_id = $2)
+			ORDER BY joke_id
+		`,
+	},
+	ports.ExportPurchases: {
+		header: []string{"purchase_id", "round_id", "customer_user_id", "joke_id", "team_id", "price", "created_at"},
+		sql: `
+			SELECT p.purchase_id, p.round_id, p.customer_user_id, p.joke_id, pj.team_id, p.price::float8, p.created_at
+			FROM purchases p
+			JOIN published_jokes pj ON pj.joke_id = p.joke_id
+			WHERE ($1::bigint IS NULL OR p.round_id = $1)
+			  AND ($2::bigint IS NULL OR pj.team_id = $2)
+			ORDER BY p.purchase_id
+		`,
+	},
+	ports.ExportPurchaseEvents: {
+		header: []string{"event_id", "round_id", "customer_user_id", "joke_id", "team_id", "delta", "price", "created_at"},
+		sql: `
+			SELECT event_id, round_id, customer_user_id, joke_id, team_id, delta, price::float8, created_at
+			FROM purchase_events
+			WHERE ($1::bigint IS NULL OR round_id = $1)
+			  AND ($2::bigint IS NULL OR team_id = $2)
+			ORDER BY event_id
+		`,
+	},
+	ports.ExportBatchSubmissionEvents: {
+		header: []string{"event_id", "round_id", "team_id", "batch_id", "jokes_count", "delta", "created_at"},
+		sql: `
+			SELECT event_id, round_id, team_id, batch_id, jokes_count, delta, created_at
+			FROM batch_submission_events
+			WHERE ($1::bigint IS NULL OR round_id = $1)
+			  AND ($2::bigint IS NULL OR team_id = $2)
+			ORDER BY event_id
+		`,
+	},
+}
+
+// StreamExport runs the entity's export query and hands the header, then each row, to
+// emit as it is read. Nothing is buffered beyond the current row.
+func (r *PostgresRepository) StreamExport(ctx context.Context, entity ports.ExportEntity, filter ports.ExportFilter, emit ports.ExportRowFunc) error {
+	q, ok := exportQueries[entity]
+	if !ok {
+		return domain.NewValidationError("entity", "unsupported export entity")
+	}
+	args := []any{filter.RoundID, filter.TeamID}
+	if q.teamOnly {
+		args = []any{filter.TeamID}
+	}
+	rows, err := r.pool.Query(ctx, q.sql, args...)
+	if err != nil {
+		return fmt.Errorf("export %s: %w", entity, err)
+	}
+	defer rows.Close()
+
+	if err := emit(q.header); err != nil {
+		return err
+	}
+	record := make([]string, len(q.header))
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return fmt.Errorf("export %s: %w", entity, err)
+		}
+		for i, v := range values {
+			record[i] = exportCell(v)
+		}
+		if err := emit(record); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("export %s: %w", entity, err)
+	}
+	return nil
+}
+
+// exportCell renders a scanned column as text: NULL is empty, times are RFC 3339 UTC.
+func exportCell(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case time.Time:
+		return x.UTC().Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		return fmt.Sprint(x)
+	}
+}
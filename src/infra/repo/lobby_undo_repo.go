@@ -0,0 +1,255 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// lobbyQuerier is satisfied by both the pool and a transaction.
+type lobbyQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func (r *PostgresRepository) CaptureLobbyUsers(ctx context.Context, roundID int64, userIDs []int64) ([]ports.LobbyUserState, error) {
+	return captureLobbyUsers(ctx, r.pool, roundID, userIDs)
+}
+
+// captureLobbyUsers returns one state per requested id, in the order given; ids with no
+// user row, or whose user is soft-deleted, come back with Exists=false.
+func captureLobbyUsers(ctx context.Context, q lobbyQuerier, roundID int64, userIDs []int64) ([]ports.LobbyUserState, error) {
+	const sql = `
+		SELECT u.user_id, u.removed_at IS NULL, u.display_name, u.role, u.team_id, u.status, u.assigned_at, u.joined_at, u.created_at,
+		       b.starting_budget::float8, b.remaining_budget::float8
+		FROM users u
+		LEFT JOIN customer_round_budget b ON b.round_id = $1 AND b.customer_user_id = u.user_id
+		WHERE u.user_id = ANY($2)
+	`
+	rows, err := q.Query(ctx, sql, roundID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[int64]ports.LobbyUserState, len(userIDs))
+	for rows.Next() {
+		var st ports.LobbyUserState
+		var starting, remaining *float64
+		if err := rows.Scan(&st.UserID, &st.Exists, &st.DisplayName, &st.Role, &st.TeamID, &st.Status, &st.AssignedAt,
+			&st.JoinedAt, &st.CreatedAt, &starting, &remaining); err != nil {
+			return nil, err
+		}
+		if starting != nil && remaining != nil {
+			st.Budget = &ports.LobbyBudgetState{StartingBudget: *starting, RemainingBudget: *remaining}
+		}
+		found[st.UserID] = st
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]ports.LobbyUserState, 0, len(userIDs))
+	for _, id := range userIDs {
+		st, ok := found[id]
+		if !ok {
+			st = ports.LobbyUserState{UserID: id}
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+func (r *PostgresRepository) PushLobbyUndo(ctx context.Context, entry *ports.LobbyUndoEntry) error {
+	before, err := json.Marshal(entry.UsersBefore)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(entry.UsersAfter)
+	if err != nil {
+		return err
+	}
+	const q = `
+		INSERT INTO lobby_undo_entries (round_id, action, users_before, users_after)
+		VALUES ($1, $2, $3, $4)
+		RETURNING undo_id, created_at
+	`
+	return r.pool.QueryRow(ctx, q, entry.RoundID, entry.Action, before, after).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+const lobbyUndoColumns = `undo_id, round_id, action, users_before, users_after, created_at, undone_at`
+
+func scanLobbyUndo(row pgx.Row) (*ports.LobbyUndoEntry, error) {
+	var e ports.LobbyUndoEntry
+	var before, after []byte
+	if err := row.Scan(&e.ID, &e.RoundID, &e.Action, &before, &after, &e.CreatedAt, &e.UndoneAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(before, &e.UsersBefore); err != nil {
+		return nil, fmt.Errorf("decode undo %d: %w", e.ID, err)
+	}
+	if err := json.Unmarshal(after, &e.UsersAfter); err != nil {
+		return nil, fmt.Errorf("decode undo %d: %w", e.ID, err)
+	}
+	return &e, nil
+}
+
+func (r *PostgresRepository) ListLobbyUndo(ctx context.Context, roundID int64, limit int) ([]ports.LobbyUndoEntry, error) {
+	q := `
+		SELECT ` + lobbyUndoColumns + `
+		FROM lobby_undo_entries
+		WHERE round_id = $1 AND undone_at IS NULL
+		ORDER BY undo_id DESC
+		LIMIT $2
+	`
+	rows, err := r.pool.Query(ctx, q, roundID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ports.LobbyUndoEntry{}
+	for rows.Next() {
+		e, err := scanLobbyUndo(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	return out, rows.Err()
+}
+
+func (r *PostgresRepository) UndoLobbyChange(ctx context.Context, roundID int64) (*ports.LobbyUndoEntry, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status domain.RoundStatus
+	if err := tx.QueryRow(ctx, `SELECT status FROM rounds WHERE round_id = $1 FOR UPDATE`, roundID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("round")
+		}
+		return nil, err
+	}
+	if status != domain.RoundConfigured {
+		return nil, domain.NewConflictError("lobby changes can only be undone before the round starts")
+	}
+
+	entry, err := scanLobbyUndo(tx.QueryRow(ctx, `
+		SELECT `+lobbyUndoColumns+`
+		FROM lobby_undo_entries
+		WHERE round_id = $1 AND undone_at IS NULL
+		ORDER BY undo_id DESC
+		LIMIT 1
+		FOR UPDATE
+	`, roundID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("lobby change to undo")
+		}
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(entry.UsersAfter))
+	for _, st := range entry.UsersAfter {
+		ids = append(ids, st.UserID)
+	}
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE user_id = ANY($1) FOR UPDATE`, ids); err != nil {
+		return nil, err
+	}
+	current, err := captureLobbyUsers(ctx, tx, roundID, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, want := range entry.UsersAfter {
+		if !sameLobbyState(current[i], want) {
+			name := want.DisplayName
+			if name == "" {
+				name = current[i].DisplayName
+			}
+			return nil, domain.NewConflictError(fmt.Sprintf("user %d (%s) has changed since this %s; undo refused", want.UserID, name, entry.Action))
+		}
+	}
+
+	for _, st := range entry.UsersBefore {
+		if err := restoreLobbyUser(ctx, tx, roundID, st); err != nil {
+			return nil, fmt.Errorf("undo %d: restore user %d: %w", entry.ID, st.UserID, err)
+		}
+	}
+
+	if err := tx.QueryRow(ctx, `UPDATE lobby_undo_entries SET undone_at = now() WHERE undo_id = $1 RETURNING undone_at`, entry.ID).Scan(&entry.UndoneAt); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// sameLobbyState compares the parts of a user's lobby state an undo would overwrite.
+func sameLobbyState(a, b ports.LobbyUserState) bool {
+	if a.Exists != b.Exists {
+		return false
+	}
+	if !a.Exists {
+		return true
+	}
+	if (a.Role == nil) != (b.Role == nil) || (a.Role != nil && *a.Role != *b.Role) {
+		return false
+	}
+	if (a.TeamID == nil) != (b.TeamID == nil) || (a.TeamID != nil && *a.TeamID != *b.TeamID) {
+		return false
+	}
+	if a.Status != b.Status {
+		return false
+	}
+	if (a.Budget == nil) != (b.Budget == nil) || (a.Budget != nil && *a.Budget != *b.Budget) {
+		return false
+	}
+	return true
+}
+
+// restoreLobbyUser writes a captured state back: re-creating, updating or soft-deleting
+// the user row, and replacing their budget row for the round.
+func restoreLobbyUser(ctx context.Context, tx pgx.Tx, roundID int64, st ports.LobbyUserState) error {
+	if !st.Exists {
+		_, err := tx.Exec(ctx, `UPDATE users SET removed_at = COALESCE(removed_at, now()) WHERE user_id = $1`, st.UserID)
+		return err
+	}
+
+	const upsertQ = `
+		INSERT INTO users (user_id, display_name, role, team_id, status, assigned_at, joined_at, created_at)
+		VALUES ($1, $2, $3, $4, $5::participant_status, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE
+		SET role = EXCLUDED.role,
+		    team_id = EXCLUDED.team_id,
+		    status = EXCLUDED.status,
+		    assigned_at = EXCLUDED.assigned_at,
+		    removed_at = NULL,
+		    removed_by = NULL
+	`
+	if _, err := tx.Exec(ctx, upsertQ, st.UserID, st.DisplayName, st.Role, st.TeamID, st.Status, st.AssignedAt, st.JoinedAt, st.CreatedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM customer_round_budget WHERE round_id = $1 AND customer_user_id = $2`, roundID, st.UserID); err != nil {
+		return err
+	}
+	if st.Budget != nil {
+		const budgetQ = `
+			INSERT INTO customer_round_budget (round_id, customer_user_id, starting_budget, remaining_budget)
+			VALUES ($1, $2, $3, $4)
+		`
+		if _, err := tx.Exec(ctx, budgetQ, roundID, st.UserID, st.Budget.StartingBudget, st.Budget.RemainingBudget); err != nil {
+			return err
+		}
+	}
+	return nil
+}
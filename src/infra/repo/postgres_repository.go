@@ -44,53 +44,67 @@ func isUniqueViolation(err error) bool {
 
 // Users & participants
 
+// userColumns lists the columns scanned by scanUser.
+const userColumns = `user_id, display_name, role, team_id, status, assigned_at, joined_at, created_at, last_seen_at, removed_at, removed_by`
+
+// scanUser scans a row selected with userColumns.
+func scanUser(row pgx.Row) (*domain.User, error) {
+	var u domain.User
+	if err := row.Scan(&u.ID, &u.DisplayName, &u.Role, &u.TeamID, &u.Status, &u.AssignedAt, &u.JoinedAt, &u.CreatedAt,
+		&u.LastSeenAt, &u.RemovedAt, &u.RemovedBy); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
 func (r *PostgresRepository) CreateUser(ctx context.Context, displayName string) (*domain.User, error) {
 	const q = `
 		INSERT INTO users (display_name)
 		VALUES ($1)
-		RETURNING user_id, display_name, role, team_id, status, assigned_at, joined_at, created_at
+		RETURNING ` + userColumns + `
 	`
-	var u domain.User
-	err := r.pool.QueryRow(ctx, q, displayName).Scan(&u.ID, &u.DisplayName, &u.Role, &u.TeamID, &u.Status, &u.AssignedAt, &u.JoinedAt, &u.CreatedAt)
+	u, err := scanUser(r.pool.QueryRow(ctx, q, displayName))
 	if err != nil {
 		if isUniqueViolation(err) {
 			return nil, domain.NewConflictError("display name already taken")
 		}
 		return nil, err
 	}
-	return &u, nil
+	return u, nil
 }
 
-func (r *PostgresRepository) GetUserByDisplayName(ctx context.Context, displayName string) (*domain.User, error) {
+func (r *PostgresRepository) GetInstructorByDisplayName(ctx context.Context, displayName string) (*domain.User, error) {
 	const q = `
-		SELECT user_id, display_name, role, team_id, status, assigned_at, joined_at, created_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE display_name = $1
+		WHERE display_name = $1 AND role = 'INSTRUCTOR' AND removed_at IS NULL
+		ORDER BY user_id
+		LIMIT 1
 	`
-	var u domain.User
-	if err := r.pool.QueryRow(ctx, q, displayName).Scan(&u.ID, &u.DisplayName, &u.Role, &u.TeamID, &u.Status, &u.AssignedAt, &u.JoinedAt, &u.CreatedAt); err != nil {
+	u, err := scanUser(r.pool.QueryRow(ctx, q, displayName))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewNotFoundError("user")
 		}
 		return nil, err
 	}
-	return &u, nil
+	return u, nil
 }
 
 func (r *PostgresRepository) GetUserByID(ctx context.Context, userID int64) (*domain.User, error) {
 	const q = `
-		SELECT user_id, display_name, role, team_id, status, assigned_at, joined_at, created_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE user_id = $1
+		WHERE user_id = $1 AND removed_at IS NULL
 	`
-	var u domain.User
-	if err := r.pool.QueryRow(ctx, q, userID).Scan(&u.ID, &u.DisplayName, &u.Role, &u.TeamID, &u.Status, &u.AssignedAt, &u.JoinedAt, &u.CreatedAt); err != nil {
+	u, err := scanUser(r.pool.QueryRow(ctx, q, userID))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewNotFoundError("user")
 		}
 		return nil, err
 	}
-	return &u, nil
+	return u, nil
 }
 
 func (r *PostgresRepository) UpdateUserAssignment(ctx context.Context, userID int64, role *domain.Role, teamID *int64) error {
@@ -123,7 +137,7 @@ func (r *PostgresRepository) PatchUserInRound(ctx context.Context, roundID, user
 
 	// Lock user row so role transition checks are consistent.
 	var oldRole *domain.Role
-	if err := tx.QueryRow(ctx, `SELECT role FROM users WHERE user_id = $1 FOR UPDATE`, userID).Scan(&oldRole); err != nil {
+	if err := tx.QueryRow(ctx, `SELECT role FROM users WHERE user_id = $1 AND removed_at IS NULL FOR UPDATE`, userID).Scan(&oldRole); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.NewNotFoundError("user")
 		}
@@ -223,9 +237,9 @@ func (r *PostgresRepository) MarkUserAssigned(ctx context.Context, userID int64)
 
 func (r *PostgresRepository) ListUsersByStatus(ctx context.Context, status domain.ParticipantStatus) ([]domain.User, error) {
 	const q = `
-		SELECT user_id, display_name, role, team_id, status, assigned_at, joined_at, created_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE status = $1::participant_status AND (role IS NULL OR role <> 'INSTRUCTOR')
+		WHERE status = $1::participant_status AND (role IS NULL OR role <> 'INSTRUCTOR') AND removed_at IS NULL
 		ORDER BY joined_at ASC
 	`
 	rows, err := r.pool.Query(ctx, q, status)
@@ -236,20 +250,20 @@ func (r *PostgresRepository) ListUsersByStatus(ctx context.Context, status domai
 
 	var users []domain.User
 	for rows.Next() {
-		var u domain.User
-		if err := rows.Scan(&u.ID, &u.DisplayName, &u.Role, &u.TeamID, &u.Status, &u.AssignedAt, &u.JoinedAt, &u.CreatedAt); err != nil {
+		u, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
-		users = append(users, u)
+		users = append(users, *u)
 	}
 	return users, nil
 }
 
 func (r *PostgresRepository) ListCustomers(ctx context.Context) ([]ports.LobbyCustomer, error) {
 	const q = `
-		SELECT user_id, display_name, role
+		SELECT user_id, display_name, role, last_seen_at
 		FROM users
-		WHERE role = 'CUSTOMER'
+		WHERE role = 'CUSTOMER' AND removed_at IS NULL
 		ORDER BY user_id
 	`
 	rows, err := r.pool.Query(ctx, q)
@@ -260,7 +274,7 @@ func (r *PostgresRepository) ListCustomers(ctx context.Context) ([]ports.LobbyCu
 	var customers []ports.LobbyCustomer
 	for rows.Next() {
 		var c ports.LobbyCustomer
-		if err := rows.Scan(&c.UserID, &c.DisplayName, &c.Role); err != nil {
+		if err := rows.Scan(&c.UserID, &c.DisplayName, &c.Role, &c.LastSeenAt); err != nil {
 			return nil, err
 		}
 		customers = append(customers, c)
@@ -270,9 +284,9 @@ func (r *PostgresRepository) ListCustomers(ctx context.Context) ([]ports.LobbyCu
 
 func (r *PostgresRepository) ListTeamMembers(ctx context.Context, teamID int64) ([]ports.TeamMember, error) {
 	const q = `
-		SELECT user_id, display_name, role
+		SELECT user_id, display_name, role, last_seen_at
 		FROM users
-		WHERE team_id = $1 AND role IS NOT NULL AND role <> 'INSTRUCTOR'
+		WHERE team_id = $1 AND role IS NOT NULL AND role <> 'INSTRUCTOR' AND removed_at IS NULL
 		ORDER BY user_id
 	`
 	rows, err := r.pool.Query(ctx, q, teamID)
@@ -284,7 +298,7 @@ func (r *PostgresRepository) ListTeamMembers(ctx context.Context, teamID int64)
 	var members []ports.TeamMember
 	for rows.Next() {
 		var m ports.TeamMember
-		if err := rows.Scan(&m.UserID, &m.DisplayName, &m.Role); err != nil {
+		if err := rows.Scan(&m.UserID, &m.DisplayName, &m.Role, &m.LastSeenAt); err != nil {
 			return nil, err
 		}
 		members = append(members, m)
@@ -292,8 +306,9 @@ func (r *PostgresRepository) ListTeamMembers(ctx context.Context, teamID int64)
 	return members, nil
 }
 
-// DeleteUser removes a non-instructor user from the database.
-func (r *PostgresRepository) DeleteUser(ctx context.Context, userID int64) error {
+// DeleteUser soft-deletes a non-instructor user. The row keeps its role and team so
+// the user's ratings, purchases and events still count in stats.
+func (r *PostgresRepository) DeleteUser(ctx context.Context, userID, removedBy int64) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -301,23 +316,148 @@ func (r *PostgresRepository) DeleteUser(ctx context.Context, userID int64) error
 	defer tx.Rollback(ctx)
 
 	var role *string
-	if err := tx.QueryRow(ctx, `SELECT role FROM users WHERE user_id = $1`, userID).Scan(&role); err != nil {
+	var removedAt *time.Time
+	if err := tx.QueryRow(ctx, `SELECT role, removed_at FROM users WHERE user_id = $1 FOR UPDATE`, userID).Scan(&role, &removedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.NewNotFoundError("user")
 		}
 		return err
 	}
+	if removedAt != nil {
+		return domain.NewNotFoundError("user")
+	}
 	if role != nil && *role == string(domain.RoleInstructor) {
 		return domain.NewConflictError("cannot delete instructor user")
 	}
 
-	res, err := tx.Exec(ctx, `DELETE FROM users WHERE user_id = $1`, userID)
+	if _, err := tx.Exec(ctx, `UPDATE users SET removed_at = now(), removed_by = $2 WHERE user_id = $1`, userID, removedBy); err != nil {
+		return err
+	}
+	// The row stays, so ON DELETE SET NULL never fires: hand a removed QC's in-review
+	// batches back to the queue here.
+	if _, err := tx.Exec(ctx, `UPDATE batches SET locked_by_qc = NULL WHERE locked_by_qc = $1 AND status = 'SUBMITTED'`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) RestoreUser(ctx context.Context, userID int64) (*domain.User, error) {
+	const q = `
+		UPDATE users
+		SET removed_at = NULL, removed_by = NULL
+		WHERE user_id = $1 AND removed_at IS NOT NULL AND merged_into IS NULL
+		RETURNING ` + userColumns + `
+	`
+	u, err := scanUser(r.pool.QueryRow(ctx, q, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("removed user")
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+func (r *PostgresRepository) ListRemovedUsers(ctx context.Context) ([]ports.LobbyRemoved, error) {
+	const q = `
+		SELECT user_id, display_name, role, team_id, removed_at, removed_by, merged_into
+		FROM users
+		WHERE removed_at IS NOT NULL
+		ORDER BY removed_at DESC, user_id
+	`
+	rows, err := r.pool.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var removed []ports.LobbyRemoved
+	for rows.Next() {
+		var u ports.LobbyRemoved
+		if err := rows.Scan(&u.UserID, &u.DisplayName, &u.Role, &u.TeamID, &u.RemovedAt, &u.RemovedBy, &u.MergedInto); err != nil {
+			return nil, err
+		}
+		removed = append(removed, u)
+	}
+	return removed, rows.Err()
+}
+
+func (r *PostgresRepository) TouchUser(ctx context.Context, userID int64) (time.Time, error) {
+	var lastSeen time.Time
+	err := r.pool.QueryRow(ctx, `UPDATE users SET last_seen_at = now() WHERE user_id = $1 AND removed_at IS NULL RETURNING last_seen_at`, userID).Scan(&lastSeen)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, domain.NewNotFoundError("user")
+		}
+		return time.Time{}, err
+	}
+	return lastSeen, nil
+}
+
+func (r *PostgresRepository) ReplaceTeamMember(ctx context.Context, userID, replacementID int64) error {
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
-	if res.RowsAffected() == 0 {
+	defer tx.Rollback(ctx)
+
+	// Lock both rows in id order so concurrent swaps can't deadlock.
+	rows, err := tx.Query(ctx, `
+		SELECT user_id, role, team_id, status
+		FROM users
+		WHERE user_id = ANY($1) AND removed_at IS NULL
+		ORDER BY user_id
+		FOR UPDATE
+	`, []int64{userID, replacementID})
+	if err != nil {
+		return err
+	}
+	type lockedUser struct {
+		role   *domain.Role
+		teamID *int64
+		status domain.ParticipantStatus
+	}
+	locked := make(map[int64]lockedUser, 2)
+	for rows.Next() {
+		var id int64
+		var u lockedUser
+		if err := rows.Scan(&id, &u.role, &u.teamID, &u.status); err != nil {
+			rows.Close()
+			return err
+		}
+		locked[id] = u
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	from, ok := locked[userID]
+	if !ok {
 		return domain.NewNotFoundError("user")
 	}
+	to, ok := locked[replacementID]
+	if !ok {
+		return domain.NewNotFoundError("replacement user")
+	}
+	if from.role == nil || (*from.role != domain.RoleJM && *from.role != domain.RoleQC) || from.teamID == nil {
+		return domain.NewConflictError("only a JM or QC can be replaced")
+	}
+	if to.status != domain.ParticipantWaiting || to.role != nil {
+		return domain.NewConflictError("replacement must be a waiting participant")
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET role = NULL, team_id = NULL, status = 'WAITING', assigned_at = NULL WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE users SET role = $2, team_id = $3, status = 'ASSIGNED', assigned_at = now() WHERE user_id = $1`, replacementID, from.role, from.teamID); err != nil {
+		return err
+	}
+	// A dropped QC's in-review batches go back to the queue.
+	if _, err := tx.Exec(ctx, `UPDATE batches SET locked_by_qc = NULL WHERE locked_by_qc = $1 AND status = 'SUBMITTED'`, userID); err != nil {
+		return err
+	}
 
 	return tx.Commit(ctx)
 }
@@ -375,69 +515,78 @@ func (r *PostgresRepository) GetTeam(ctx context.Context, teamID int64) (*domain
 
 // Rounds
 
+// roundColumns lists the columns scanned by scanRound. Every query returning a full
+// round row should select (or RETURN) exactly these, in this order.
+const roundColumns = `round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing, started_at, ended_at, created_at, is_popped_active, qc_routing_mode, max_unrated_batches, max_unrated_jokes, late_joiner_policy, chat_enabled, pricing_mode, min_joke_price::float8, max_joke_price::float8, price_step::float8, price_decay::float8, auction_rule, auction_window_seconds, returns_allowed, return_window_seconds, max_returns, return_refund_percent`
+
+// scanRound scans a row selected with roundColumns.
+func scanRound(row pgx.Row) (*domain.Round, error) {
+	var rd domain.Round
+	if err := row.Scan(
+		&rd.ID, &rd.RoundNumber, &rd.Status, &rd.CustomerBudget, &rd.BatchSize, &rd.MarketPrice, &rd.CostOfPublishing,
+		&rd.StartedAt, &rd.EndedAt, &rd.CreatedAt, &rd.IsPoppedActive, &rd.QCRoutingMode,
+		&rd.MaxUnratedBatches, &rd.MaxUnratedJokes, &rd.LateJoinerPolicy, &rd.ChatEnabled,
+		&rd.PricingMode, &rd.MinJokePrice, &rd.MaxJokePrice, &rd.PriceStep, &rd.PriceDecay,
+		&rd.AuctionRule, &rd.AuctionWindowSeconds,
+		&rd.ReturnsAllowed, &rd.ReturnWindowSeconds, &rd.MaxReturns, &rd.RefundPercent,
+	); err != nil {
+		return nil, err
+	}
+	return &rd, nil
+}
+
 func (r *PostgresRepository) GetActiveRound(ctx context.Context) (*domain.Round, error) {
 	const q = `
-		SELECT round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing, started_at, ended_at, created_at, is_popped_active
+		SELECT ` + roundColumns + `
 		FROM rounds
 		WHERE status = 'ACTIVE'
 		LIMIT 1
 	`
-	var rd domain.Round
-	err := r.pool.QueryRow(ctx, q).Scan(
-		&rd.ID, &rd.RoundNumber, &rd.Status, &rd.CustomerBudget, &rd.BatchSize, &rd.MarketPrice, &rd.CostOfPublishing,
-		&rd.StartedAt, &rd.EndedAt, &rd.CreatedAt, &rd.IsPoppedActive,
-	)
+	rd, err := scanRound(r.pool.QueryRow(ctx, q))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &rd, nil
+	return rd, nil
 }
 
 func (r *PostgresRepository) GetRoundByID(ctx context.Context, roundID int64) (*domain.Round, error) {
 	const q = `
-		SELECT round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing, started_at, ended_at, created_at, is_popped_active
+		SELECT ` + roundColumns + `
 		FROM rounds WHERE round_id = $1
 	`
-	var rd domain.Round
-	if err := r.pool.QueryRow(ctx, q, roundID).Scan(
-		&rd.ID, &rd.RoundNumber, &rd.Status, &rd.CustomerBudget, &rd.BatchSize, &rd.MarketPrice, &rd.CostOfPublishing,
-		&rd.StartedAt, &rd.EndedAt, &rd.CreatedAt, &rd.IsPoppedActive,
-	); err != nil {
+	rd, err := scanRound(r.pool.QueryRow(ctx, q, roundID))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewNotFoundError("round")
 		}
 		return nil, err
 	}
-	return &rd, nil
+	return rd, nil
 }
 
 func (r *PostgresRepository) GetLatestRound(ctx context.Context) (*domain.Round, error) {
 	const q = `
-		SELECT round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing, started_at, ended_at, created_at, is_popped_active
+		SELECT ` + roundColumns + `
 		FROM rounds
 		ORDER BY round_id DESC
 		LIMIT 1
 	`
-	var rd domain.Round
-	err := r.pool.QueryRow(ctx, q).Scan(
-		&rd.ID, &rd.RoundNumber, &rd.Status, &rd.CustomerBudget, &rd.BatchSize, &rd.MarketPrice, &rd.CostOfPublishing,
-		&rd.StartedAt, &rd.EndedAt, &rd.CreatedAt, &rd.IsPoppedActive,
-	)
+	rd, err := scanRound(r.pool.QueryRow(ctx, q))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &rd, nil
+	return rd, nil
 }
 
 func (r *PostgresRepository) ListRounds(ctx context.Context) ([]domain.Round, error) {
 	const q = `
-		SELECT round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing, started_at, ended_at, created_at, is_popped_active
+		SELECT ` + roundColumns + `
 		FROM rounds
 		ORDER BY round_id ASC
 	`
@@ -449,14 +598,11 @@ func (r *PostgresRepository) ListRounds(ctx context.Context) ([]domain.Round, er
 
 	var rounds []domain.Round
 	for rows.Next() {
-		var rd domain.Round
-		if err := rows.Scan(
-			&rd.ID, &rd.RoundNumber, &rd.Status, &rd.CustomerBudget, &rd.BatchSize, &rd.MarketPrice, &rd.CostOfPublishing,
-			&rd.StartedAt, &rd.EndedAt, &rd.CreatedAt, &rd.IsPoppedActive,
-		); err != nil {
+		rd, err := scanRound(rows)
+		if err != nil {
 			return nil, err
 		}
-		rounds = append(rounds, rd)
+		rounds = append(rounds, *rd)
 	}
 	return rounds, nil
 }
@@ -469,13 +615,10 @@ func (r *PostgresRepository) UpdateRoundConfig(ctx context.Context, roundID int6
 		    market_price = $4,
 		    cost_of_publishing = $5
 		WHERE round_id = $1
-		RETURNING round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing, started_at, ended_at, created_at, is_popped_active
+		RETURNING ` + roundColumns + `
 	`
-	var rd domain.Round
-	if err := r.pool.QueryRow(ctx, q, roundID, customerBudget, batchSize, marketPrice, costOfPublishing).Scan(
-		&rd.ID, &rd.RoundNumber, &rd.Status, &rd.CustomerBudget, &rd.BatchSize, &rd.MarketPrice, &rd.CostOfPublishing,
-		&rd.StartedAt, &rd.EndedAt, &rd.CreatedAt, &rd.IsPoppedActive,
-	); err != nil {
+	rd, err := scanRound(r.pool.QueryRow(ctx, q, roundID, customerBudget, batchSize, marketPrice, costOfPublishing))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewNotFoundError("round")
 		}
@@ -503,7 +646,7 @@ func (r *PostgresRepository) UpdateRoundConfig(ctx context.Context, roundID int6
 		return nil, err
 	}
 
-	return &rd, nil
+	return rd, nil
 }
 
 func (r *PostgresRepository) InsertRoundConfig(ctx context.Context, roundID int64, customerBudget, batchSize int, marketPrice, costOfPublishing float64) (*domain.Round, error) {
@@ -516,22 +659,19 @@ func (r *PostgresRepository) InsertRoundConfig(ctx context.Context, roundID int6
 		return nil, err
 	}
 
-	var inserted domain.Round
 	roundNumber := int(roundID)
 	const q = `
 		INSERT INTO rounds (round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing)
 		VALUES ($1, $2, 'CONFIGURED', $3, $4, $5, $6)
-		RETURNING round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing, started_at, ended_at, created_at, is_popped_active
+		RETURNING ` + roundColumns + `
 	`
-	if err := r.pool.QueryRow(ctx, q, roundID, roundNumber, customerBudget, batchSize, marketPrice, costOfPublishing).Scan(
-		&inserted.ID, &inserted.RoundNumber, &inserted.Status, &inserted.CustomerBudget, &inserted.BatchSize, &inserted.MarketPrice, &inserted.CostOfPublishing,
-		&inserted.StartedAt, &inserted.EndedAt, &inserted.CreatedAt, &inserted.IsPoppedActive,
-	); err != nil {
+	inserted, err := scanRound(r.pool.QueryRow(ctx, q, roundID, roundNumber, customerBudget, batchSize, marketPrice, costOfPublishing))
+	if err != nil {
 		r.log.Error("InsertRoundConfig insert failed", "round_id", roundID, "err", err)
 		return nil, err
 	}
 	r.log.Info("InsertRoundConfig inserted round", "round_id", inserted.ID, "round_number", inserted.RoundNumber)
-	return &inserted, nil
+	return inserted, nil
 }
 
 func (r *PostgresRepository) StartRound(ctx context.Context, roundID int64, customerBudget, batchSize int, marketPrice, costOfPublishing float64) (*domain.Round, error) {
@@ -551,13 +691,10 @@ func (r *PostgresRepository) StartRound(ctx context.Context, roundID int64, cust
 		    started_at = COALESCE(started_at, now()),
 		    ended_at = NULL
 		WHERE round_id = $1
-		RETURNING round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing, started_at, ended_at, created_at, is_popped_active
+		RETURNING ` + roundColumns + `
 	`
-	var rd domain.Round
-	if err := tx.QueryRow(ctx, updateRound, roundID, customerBudget, batchSize, marketPrice, costOfPublishing).Scan(
-		&rd.ID, &rd.RoundNumber, &rd.Status, &rd.CustomerBudget, &rd.BatchSize, &rd.MarketPrice, &rd.CostOfPublishing,
-		&rd.StartedAt, &rd.EndedAt, &rd.CreatedAt, &rd.IsPoppedActive,
-	); err != nil {
+	rd, err := scanRound(tx.QueryRow(ctx, updateRound, roundID, customerBudget, batchSize, marketPrice, costOfPublishing))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewNotFoundError("round")
 		}
@@ -594,7 +731,7 @@ func (r *PostgresRepository) StartRound(ctx context.Context, roundID int64, cust
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
-	return &rd, nil
+	return rd, nil
 }
 
 func (r *PostgresRepository) EndRound(ctx context.Context, roundID int64) (*domain.Round, error) {
@@ -602,19 +739,16 @@ func (r *PostgresRepository) EndRound(ctx context.Context, roundID int64) (*doma
 		UPDATE rounds
 		SET status = 'ENDED', ended_at = now()
 		WHERE round_id = $1
-		RETURNING round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing, started_at, ended_at, created_at, is_popped_active
+		RETURNING ` + roundColumns + `
 	`
-	var rd domain.Round
-	if err := r.pool.QueryRow(ctx, q, roundID).Scan(
-		&rd.ID, &rd.RoundNumber, &rd.Status, &rd.CustomerBudget, &rd.BatchSize, &rd.MarketPrice, &rd.CostOfPublishing,
-		&rd.StartedAt, &rd.EndedAt, &rd.CreatedAt, &rd.IsPoppedActive,
-	); err != nil {
+	rd, err := scanRound(r.pool.QueryRow(ctx, q, roundID))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewNotFoundError("round")
 		}
 		return nil, err
 	}
-	return &rd, nil
+	return rd, nil
 }
 
 func (r *PostgresRepository) SetRoundPopupState(ctx context.Context, roundID int64, isActive bool) (*domain.Round, error) {
@@ -622,19 +756,174 @@ func (r *PostgresRepository) SetRoundPopupState(ctx context.Context, roundID int
 		UPDATE rounds
 		SET is_popped_active = $2
 		WHERE round_id = $1
-		RETURNING round_id, round_number, status, customer_budget, batch_size, market_price, cost_of_publishing, started_at, ended_at, created_at, is_popped_active
+		RETURNING ` + roundColumns + `
 	`
-	var rd domain.Round
-	if err := r.pool.QueryRow(ctx, q, roundID, isActive).Scan(
-		&rd.ID, &rd.RoundNumber, &rd.Status, &rd.CustomerBudget, &rd.BatchSize, &rd.MarketPrice, &rd.CostOfPublishing,
-		&rd.StartedAt, &rd.EndedAt, &rd.CreatedAt, &rd.IsPoppedActive,
-	); err != nil {
+	rd, err := scanRound(r.pool.QueryRow(ctx, q, roundID, isActive))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewNotFoundError("round")
 		}
 		return nil, err
 	}
-	return &rd, nil
+	return rd, nil
+}
+
+func (r *PostgresRepository) SetRoundQCRoutingMode(ctx context.Context, roundID int64, mode domain.QCRoutingMode) (*domain.Round, error) {
+	const q = `
+		UPDATE rounds
+		SET qc_routing_mode = $2::qc_routing_mode
+		WHERE round_id = $1
+		RETURNING ` + roundColumns + `
+	`
+	rd, err := scanRound(r.pool.QueryRow(ctx, q, roundID, mode))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("round")
+		}
+		return nil, err
+	}
+	return rd, nil
+}
+
+func (r *PostgresRepository) SetRoundLateJoinerPolicy(ctx context.Context, roundID int64, policy domain.LateJoinerPolicy) (*domain.Round, error) {
+	const q = `
+		UPDATE rounds
+		SET late_joiner_policy = $2::late_joiner_policy
+		WHERE round_id = $1
+		RETURNING ` + roundColumns + `
+	`
+	rd, err := scanRound(r.pool.QueryRow(ctx, q, roundID, policy))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("round")
+		}
+		return nil, err
+	}
+	return rd, nil
+}
+
+// SetRoundPricing stores the round's pricing. Switching into dynamic pricing starts every
+// joke already on the market from the market price, now; switching into auctions opens
+// bidding on them from now.
+func (r *PostgresRepository) SetRoundPricing(ctx context.Context, roundID int64, p domain.RoundPricing) (*domain.Round, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var prev domain.PricingMode
+	if err := tx.QueryRow(ctx, `SELECT pricing_mode FROM rounds WHERE round_id = $1 FOR UPDATE`, roundID).Scan(&prev); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("round")
+		}
+		return nil, err
+	}
+	const q = `
+		UPDATE rounds
+		SET pricing_mode = $2::pricing_mode, min_joke_price = $3, max_joke_price = $4,
+		    price_step = $5, price_decay = $6, auction_rule = $7::auction_rule, auction_window_seconds = $8,
+		    auction_started_at = CASE
+		        WHEN $2::pricing_mode = 'AUCTION' AND pricing_mode <> 'AUCTION' THEN now()
+		        ELSE auction_started_at
+		    END
+		WHERE round_id = $1
+		RETURNING ` + roundColumns + `
+	`
+	rd, err := scanRound(tx.QueryRow(ctx, q, roundID, p.PricingMode, p.MinJokePrice, p.MaxJokePrice, p.PriceStep, p.PriceDecay,
+		p.AuctionRule, p.AuctionWindowSeconds))
+	if err != nil {
+		return nil, err
+	}
+	if rd.PricingMode == domain.PricingDynamic && prev != domain.PricingDynamic {
+		const startQ = `
+			INSERT INTO joke_price_changes (round_id, joke_id, price)
+			SELECT round_id, joke_id, $2 FROM published_jokes WHERE round_id = $1
+		`
+		if _, err := tx.Exec(ctx, startQ, roundID, rd.DecayedPrice(domain.PriceState{Price: rd.MarketPrice})); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+// SetRoundReturnPolicy stores the round's return policy. Returns already made still count
+// against a new limit.
+func (r *PostgresRepository) SetRoundReturnPolicy(ctx context.Context, roundID int64, p domain.ReturnPolicy) (*domain.Round, error) {
+	const q = `
+		UPDATE rounds
+		SET returns_allowed = $2, return_window_seconds = $3, max_returns = $4, return_refund_percent = $5
+		WHERE round_id = $1
+		RETURNING ` + roundColumns + `
+	`
+	rd, err := scanRound(r.pool.QueryRow(ctx, q, roundID, p.ReturnsAllowed, p.ReturnWindowSeconds, p.MaxReturns, p.RefundPercent))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("round")
+		}
+		return nil, err
+	}
+	return rd, nil
+}
+
+func (r *PostgresRepository) SetRoundChatEnabled(ctx context.Context, roundID int64, enabled bool) (*domain.Round, error) {
+	const q = `
+		UPDATE rounds
+		SET chat_enabled = $2
+		WHERE round_id = $1
+		RETURNING ` + roundColumns + `
+	`
+	rd, err := scanRound(r.pool.QueryRow(ctx, q, roundID, enabled))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("round")
+		}
+		return nil, err
+	}
+	return rd, nil
+}
+
+// SetRoundWIPLimits sets (or clears, with nil) the per-team limits on unrated work.
+func (r *PostgresRepository) SetRoundWIPLimits(ctx context.Context, roundID int64, maxUnratedBatches, maxUnratedJokes *int) (*domain.Round, error) {
+	const q = `
+		UPDATE rounds
+		SET max_unrated_batches = $2,
+		    max_unrated_jokes = $3
+		WHERE round_id = $1
+		RETURNING ` + roundColumns + `
+	`
+	rd, err := scanRound(r.pool.QueryRow(ctx, q, roundID, maxUnratedBatches, maxUnratedJokes))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("round")
+		}
+		return nil, err
+	}
+	return rd, nil
+}
+
+// ListRoundTeamIDs returns the ids of teams taking part in a round, ordered by id.
+// This ordering defines the QC rotation (see domain.QCRotationSourceTeam).
+func (r *PostgresRepository) ListRoundTeamIDs(ctx context.Context, roundID int64) ([]int64, error) {
+	const q = `SELECT team_id FROM team_rounds_state WHERE round_id = $1 ORDER BY team_id`
+	rows, err := r.pool.Query(ctx, q, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
 }
 
 // Team round state
@@ -672,20 +961,23 @@ func (r *PostgresRepository) IncrementRatedStats(ctx context.Context, roundID, t
 	return err
 }
 
-// updateTeamPoints adjusts only points_earned without touching batches_rated.
-func (r *PostgresRepository) updateTeamPoints(ctx context.Context, roundID, teamID int64, pointsDelta int) error {
+// updateTeamPoints adjusts only points_earned without touching batches_rated, inside the
+// caller's transaction.
+func (r *PostgresRepository) updateTeamPoints(ctx context.Context, tx pgx.Tx, roundID, teamID int64, pointsDelta int) error {
 	const q = `
 		UPDATE team_rounds_state
 		SET points_earned = points_earned + $3,
 			updated_at = now()
 		WHERE round_id = $1 AND team_id = $2
 	`
-	_, err := r.pool.Exec(ctx, q, roundID, teamID, pointsDelta)
+	_, err := tx.Exec(ctx, q, roundID, teamID, pointsDelta)
 	return err
 }
 
 // Batches and jokes
 
+// CreateBatch submits a batch, enforcing the round's WIP limits. The team's round state
+// row is locked first so concurrent submits are checked one at a time.
 func (r *PostgresRepository) CreateBatch(ctx context.Context, roundID, teamID int64, jokes []string) (*domain.Batch, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
@@ -693,10 +985,29 @@ func (r *PostgresRepository) CreateBatch(ctx context.Context, roundID, teamID in
 	}
 	defer tx.Rollback(ctx)
 
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM team_rounds_state WHERE round_id = $1 AND team_id = $2 FOR UPDATE`, roundID, teamID); err != nil {
+		return nil, err
+	}
+	round, err := scanRound(tx.QueryRow(ctx, `SELECT `+roundColumns+` FROM rounds WHERE round_id = $1`, roundID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("round")
+		}
+		return nil, err
+	}
+	queuedBatches, queuedJokes, err := countUnratedWork(ctx, tx, roundID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if err := round.CheckWIPLimit(queuedBatches, queuedJokes, len(jokes)); err != nil {
+		return nil, err
+	}
+
 	var batch domain.Batch
+	// The batch keeps the routing it was submitted under for the queue history.
 	const insertBatch = `
-		INSERT INTO batches (round_id, team_id, status, submitted_at)
-		VALUES ($1, $2, 'SUBMITTED', now())
+		INSERT INTO batches (round_id, team_id, status, submitted_at, qc_routing_mode)
+		SELECT $1, $2, 'SUBMITTED', now(), qc_routing_mode FROM rounds WHERE round_id = $1
 		RETURNING batch_id, round_id, team_id, status, submitted_at, rated_at, avg_score, passes_count, locked_at, created_at
 	`
 	if err := tx.QueryRow(ctx, insertBatch, roundID, teamID).Scan(
@@ -724,7 +1035,8 @@ func (r *PostgresRepository) CreateBatch(ctx context.Context, roundID, teamID in
 		return nil, err
 	}
 
-	if err := r.IncrementBatchCreated(ctx, roundID, teamID); err != nil {
+	// On the transaction: the pool would wait on the row lock taken above.
+	if _, err := tx.Exec(ctx, `UPDATE team_rounds_state SET batches_created = batches_created + 1, updated_at = now() WHERE round_id = $1 AND team_id = $2`, roundID, teamID); err != nil {
 		return nil, err
 	}
 
@@ -734,6 +1046,26 @@ func (r *PostgresRepository) CreateBatch(ctx context.Context, roundID, teamID in
 	return &batch, nil
 }
 
+// rowQuerier is satisfied by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// countUnratedWork returns how many batches (and jokes in them) a team has waiting for QC.
+func countUnratedWork(ctx context.Context, db rowQuerier, roundID, teamID int64) (int, int, error) {
+	const q = `
+		SELECT COUNT(DISTINCT b.batch_id), COUNT(j.joke_id)
+		FROM batches b
+		LEFT JOIN jokes j ON j.batch_id = b.batch_id
+		WHERE b.round_id = $1 AND b.team_id = $2 AND b.status = 'SUBMITTED'
+	`
+	var batches, jokes int
+	if err := db.QueryRow(ctx, q, roundID, teamID).Scan(&batches, &jokes); err != nil {
+		return 0, 0, err
+	}
+	return batches, jokes, nil
+}
+
 func (r *PostgresRepository) ListBatchesByTeam(ctx context.Context, roundID, teamID int64) ([]domain.Batch, error) {
 	const q = `
 		SELECT batch_id, round_id, team_id, status, submitted_at, rated_at, avg_score, passes_count, feedback, locked_at, created_at
@@ -867,7 +1199,9 @@ func (r *PostgresRepository) GetBatchWithJokes(ctx context.Context, batchID int6
 	return &ports.BatchWithJokes{Batch: b, Jokes: jokes}, nil
 }
 
-func (r *PostgresRepository) GetNextBatchForQC(ctx context.Context, roundID, qcUserID, teamID int64) (*ports.BatchWithJokes, int, error) {
+// GetNextBatchForQC locks the oldest SUBMITTED batch from sourceTeamID (or from any team
+// when sourceTeamID is nil) for the QC and returns it with the remaining queue size.
+func (r *PostgresRepository) GetNextBatchForQC(ctx context.Context, roundID, qcUserID int64, sourceTeamID *int64) (*ports.BatchWithJokes, int, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return nil, 0, err
@@ -877,20 +1211,24 @@ func (r *PostgresRepository) GetNextBatchForQC(ctx context.Context, roundID, qcU
 	const nextQ = `
 		SELECT batch_id
 		FROM batches
-		WHERE round_id = $1 AND team_id = $3 AND status = 'SUBMITTED' AND (locked_by_qc IS NULL OR locked_by_qc = $2)
-		ORDER BY submitted_at ASC
+		WHERE round_id = $1
+		  AND ($3::bigint IS NULL OR team_id = $3)
+		  AND status = 'SUBMITTED'
+		  AND (locked_by_qc IS NULL OR locked_by_qc = $2)
+		ORDER BY submitted_at ASC, batch_id ASC
 		FOR UPDATE SKIP LOCKED
 		LIMIT 1
 	`
 	var batchID int64
-	if err := tx.QueryRow(ctx, nextQ, roundID, qcUserID, teamID).Scan(&batchID); err != nil {
+	if err := tx.QueryRow(ctx, nextQ, roundID, qcUserID, sourceTeamID).Scan(&batchID); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, 0, domain.NewNotFoundError("batch")
 		}
 		return nil, 0, err
 	}
 
-	if _, err := tx.Exec(ctx, `UPDATE batches SET locked_at = now(), locked_by_qc = $2 WHERE batch_id = $1`, batchID, qcUserID); err != nil {
+	// Keep the first lock time: it marks the end of the batch's wait in the queue (flow metrics).
+	if _, err := tx.Exec(ctx, `UPDATE batches SET locked_at = COALESCE(locked_at, now()), locked_by_qc = $2 WHERE batch_id = $1`, batchID, qcUserID); err != nil {
 		return nil, 0, err
 	}
 
@@ -921,7 +1259,7 @@ func (r *PostgresRepository) GetNextBatchForQC(ctx context.Context, roundID, qcU
 	}
 
 	var queueSize int
-	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM batches WHERE round_id = $1 AND team_id = $2 AND status = 'SUBMITTED'`, roundID, teamID).Scan(&queueSize); err != nil {
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM batches WHERE round_id = $1 AND ($2::bigint IS NULL OR team_id = $2) AND status = 'SUBMITTED'`, roundID, sourceTeamID).Scan(&queueSize); err != nil {
 		return nil, 0, err
 	}
 
@@ -998,7 +1336,7 @@ func (r *PostgresRepository) RateBatch(ctx context.Context, batchID int64, qcUse
 			avg_score = $3,
 			passes_count = $4,
 			feedback = $5,
-			locked_at = NULL,
+			-- locked_at stays as it was (NULL if never locked) for flow metrics.
 			locked_by_qc = NULL
 		WHERE batch_id = $1
 		RETURNING batch_id, round_id, team_id, status, submitted_at, rated_at, avg_score, passes_count, feedback, locked_at, created_at
@@ -1053,17 +1391,97 @@ func (r *PostgresRepository) RateBatch(ctx context.Context, batchID int64, qcUse
 	return &updated, published, nil
 }
 
-func (r *PostgresRepository) CountSubmittedBatches(ctx context.Context, roundID int64) (int, error) {
-	const q = `SELECT COUNT(*) FROM batches WHERE round_id = $1 AND status = 'SUBMITTED'`
+// CountSubmittedBatches counts SUBMITTED batches in a round, optionally limited to one team.
+func (r *PostgresRepository) CountSubmittedBatches(ctx context.Context, roundID int64, teamID *int64) (int, error) {
+	const q = `SELECT COUNT(*) FROM batches WHERE round_id = $1 AND ($2::bigint IS NULL OR team_id = $2) AND status = 'SUBMITTED'`
 	var count int
-	if err := r.pool.QueryRow(ctx, q, roundID).Scan(&count); err != nil {
+	if err := r.pool.QueryRow(ctx, q, roundID, teamID).Scan(&count); err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
+// ListBatchFlow returns lifecycle timestamps for every submitted batch in a round.
+func (r *PostgresRepository) ListBatchFlow(ctx context.Context, roundID int64) ([]ports.BatchFlow, error) {
+	const q = `
+		SELECT b.batch_id, b.team_id, t.name, COUNT(j.joke_id), b.submitted_at, b.locked_at, b.rated_at
+		FROM batches b
+		JOIN teams t ON t.id = b.team_id
+		LEFT JOIN jokes j ON j.batch_id = b.batch_id
+		WHERE b.round_id = $1 AND b.submitted_at IS NOT NULL
+		GROUP BY b.batch_id, b.team_id, t.name, b.submitted_at, b.locked_at, b.rated_at
+		ORDER BY b.submitted_at, b.batch_id
+	`
+	rows, err := r.pool.Query(ctx, q, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ports.BatchFlow
+	for rows.Next() {
+		var b ports.BatchFlow
+		if err := rows.Scan(&b.BatchID, &b.TeamID, &b.TeamName, &b.JokesCount, &b.SubmittedAt, &b.LockedAt, &b.RatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+// ListJokeLifecycles returns lifecycle timestamps for every joke in a round.
+func (r *PostgresRepository) ListJokeLifecycles(ctx context.Context, roundID int64) ([]ports.JokeLifecycle, error) {
+	const q = `
+		SELECT j.joke_id, b.batch_id, b.team_id, t.name, j.joke_text, j.joke_title,
+		       b.created_at, b.submitted_at, b.locked_at, b.rated_at, b.avg_score::float8,
+		       jr.rating, pj.joke_id IS NOT NULL AS is_published, pj.created_at
+		FROM jokes j
+		JOIN batches b ON b.batch_id = j.batch_id
+		JOIN teams t ON t.id = b.team_id
+		LEFT JOIN joke_ratings jr ON jr.joke_id = j.joke_id
+		LEFT JOIN published_jokes pj ON pj.joke_id = j.joke_id
+		WHERE b.round_id = $1
+		ORDER BY b.team_id, j.joke_id
+	`
+	rows, err := r.pool.Query(ctx, q, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ports.JokeLifecycle
+	for rows.Next() {
+		var jl ports.JokeLifecycle
+		if err := rows.Scan(&jl.JokeID, &jl.BatchID, &jl.TeamID, &jl.TeamName, &jl.JokeText, &jl.JokeTitle,
+			&jl.BatchCreatedAt, &jl.SubmittedAt, &jl.LockedAt, &jl.RatedAt, &jl.BatchAvgScore,
+			&jl.Rating, &jl.IsPublished, &jl.PublishedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, jl)
+	}
+	return out, nil
+}
+
 // Market and budget
 
+// latestPriceChangeJoin attaches a published joke's (pj) latest dynamic price change as
+// lpc; priceStateColumns turn it into the joke's domain.PriceState, falling back to the
+// round's (r) market price since publication. Idle is in seconds (see secondsToDuration).
+const (
+	latestPriceChangeJoin = `LEFT JOIN LATERAL (
+			SELECT price, created_at FROM joke_price_changes
+			WHERE joke_id = pj.joke_id
+			ORDER BY change_id DESC
+			LIMIT 1
+		) lpc ON TRUE`
+	priceStateColumns = `COALESCE(lpc.price, r.market_price)::float8 AS state_price,
+		EXTRACT(EPOCH FROM now() - COALESCE(lpc.created_at, pj.created_at))::float8 AS state_idle`
+)
+
+func secondsToDuration(secs float64) time.Duration {
+	return time.Duration(secs * float64(time.Second))
+}
+
 // marketPerformanceLabelCTEs defines the exact performance labeling logic used by Market.
 // Keep this shared so all endpoints show consistent team labels.
 //
@@ -1074,18 +1492,17 @@ func (r *PostgresRepository) CountSubmittedBatches(ctx context.Context, roundID
 const marketPerformanceLabelCTEs = `
 		cfg AS (
 			SELECT
-				COALESCE(market_price, 1)::double precision AS market_price,
 				COALESCE(cost_of_publishing, 0.1)::double precision AS cost_of_publishing,
 				(SELECT COUNT(*) FROM published_jokes WHERE round_id = $1) AS total_published_jokes
 			FROM rounds
 			WHERE round_id = $1
 		),
 		market_sales_counts AS (
-			SELECT pj.team_id, COUNT(*) AS total_sales
-			FROM purchases p
-			JOIN published_jokes pj ON pj.joke_id = p.joke_id
-			WHERE p.round_id = $1
-			GROUP BY pj.team_id
+			-- Revenue nets purchase events, so a partial refund leaves the rest with the team.
+			SELECT pe.team_id, SUM(pe.delta) AS total_sales, SUM(pe.delta * pe.price)::double precision AS revenue
+			FROM purchase_events pe
+			WHERE pe.round_id = $1
+			GROUP BY pe.team_id
 		),
 		market_sold_counts AS (
 			-- Count jokes that have at least one purchase (not total purchases).
@@ -1119,7 +1536,7 @@ const marketPerformanceLabelCTEs = `
 			           WHEN COALESCE(m.total_market, 0) = 0 THEN 0
 			           ELSE COALESCE(sc.sold_jokes, 0)::double precision / COALESCE(m.total_market, 0)
 			       END AS ratio,
-			       (SELECT market_price FROM cfg) * COALESCE(s.total_sales, 0)::double precision
+			       COALESCE(s.revenue, 0)
 			         - (SELECT cost_of_publishing FROM cfg) * COALESCE(m.total_market, 0)::double precision AS profit
 			FROM team_rounds_state trs
 			LEFT JOIN market_sales_counts s ON s.team_id = trs.team_id
@@ -1194,74 +1611,158 @@ func (r *PostgresRepository) getCustomerBudget(ctx context.Context, roundID, cus
 	return &b, nil
 }
 
-func (r *PostgresRepository) ListMarket(ctx context.Context, roundID, customerID int64) ([]ports.MarketItem, error) {
-	const q = `WITH ` + marketPerformanceLabelCTEs + `
-		SELECT pj.joke_id, j.joke_text, j.joke_title, pj.team_id, t.name,
-			CASE
-				WHEN (SELECT total_published_jokes FROM cfg) < 10 THEN 'LOW PERFORMING'
-				ELSE COALESCE(l.performance_label, 'AVERAGE PERFORMING')
-			END AS label,
-			COALESCE(pc.purchase_count, 0) AS purchase_count,
-			CASE WHEN p.purchase_id IS NOT NULL THEN TRUE ELSE FALSE END AS is_bought,
-			COALESCE(tb.profit, 0) AS profit,
-			COALESCE(tb.accepted_jokes, 0) AS accepted_jokes,
-			GREATEST(COALESCE(tb.accepted_jokes, 0) - COALESCE(tb.unsold_jokes, 0), 0) AS sold_jokes_count
-		FROM published_jokes pj
-		JOIN jokes j ON j.joke_id = pj.joke_id
-		JOIN teams t ON t.id = pj.team_id
-		LEFT JOIN purchases p ON p.round_id = pj.round_id AND p.joke_id = pj.joke_id AND p.customer_user_id = $2
-		LEFT JOIN (
-			SELECT joke_id, COUNT(*) AS purchase_count
-			FROM purchases
-			WHERE round_id = $1
-			GROUP BY joke_id
-		) pc ON pc.joke_id = pj.joke_id
-		LEFT JOIN market_labeled l ON l.team_id = pj.team_id
-		LEFT JOIN market_team_base tb ON tb.team_id = pj.team_id
-		WHERE pj.round_id = $1
-		ORDER BY pj.created_at ASC, pj.joke_id ASC
+// marketSortOrders maps each market sort to its ORDER BY over the market CTE's columns.
+var marketSortOrders = map[ports.MarketSort]string{
+	ports.MarketSortOldest:     "published_at ASC, joke_id ASC",
+	ports.MarketSortNewest:     "published_at DESC, joke_id DESC",
+	ports.MarketSortMostBought: "purchase_count DESC, published_at DESC, joke_id DESC",
+	ports.MarketSortTeamProfit: "profit DESC, published_at DESC, joke_id DESC",
+}
+
+func (r *PostgresRepository) ListMarket(ctx context.Context, roundID, customerID int64, filter ports.MarketFilter) ([]ports.MarketItem, int64, error) {
+	order, ok := marketSortOrders[filter.Sort]
+	if !ok {
+		order = marketSortOrders[ports.MarketSortOldest]
+	}
+	const market = `WITH ` + marketPerformanceLabelCTEs + `,
+		market AS (
+			SELECT pj.joke_id, j.joke_text, j.joke_title, pj.team_id, t.name,
+				CASE
+					WHEN (SELECT total_published_jokes FROM cfg) < 10 THEN 'LOW PERFORMING'
+					ELSE COALESCE(l.performance_label, 'AVERAGE PERFORMING')
+				END AS label,
+				COALESCE(pc.purchase_count, 0) AS purchase_count,
+				CASE WHEN p.purchase_id IS NOT NULL THEN TRUE ELSE FALSE END AS is_bought,
+				COALESCE(tb.profit, 0) AS profit,
+				COALESCE(tb.accepted_jokes, 0) AS accepted_jokes,
+				GREATEST(COALESCE(tb.accepted_jokes, 0) - COALESCE(tb.unsold_jokes, 0), 0) AS sold_jokes_count,
+				cr.avg_rating, COALESCE(cr.rating_count, 0) AS rating_count, mr.rating AS my_rating,
+				pj.created_at AS published_at, j.team_price::float8 AS team_price,
+				` + priceStateColumns + `,
+				CASE WHEN r.pricing_mode = 'AUCTION' THEN ` + auctionClosesAt + ` END AS auction_closes_at,
+				mb.amount::float8 AS my_bid
+			FROM published_jokes pj
+			JOIN jokes j ON j.joke_id = pj.joke_id
+			JOIN teams t ON t.id = pj.team_id
+			JOIN rounds r ON r.round_id = pj.round_id
+			` + latestPriceChangeJoin + `
+			LEFT JOIN purchases p ON p.round_id = pj.round_id AND p.joke_id = pj.joke_id AND p.customer_user_id = $2
+			LEFT JOIN (
+				SELECT joke_id, COUNT(*) AS purchase_count
+				FROM purchases
+				WHERE round_id = $1
+				GROUP BY joke_id
+			) pc ON pc.joke_id = pj.joke_id
+			LEFT JOIN (
+				SELECT joke_id, AVG(rating)::float8 AS avg_rating, COUNT(*) AS rating_count
+				FROM customer_ratings
+				WHERE round_id = $1
+				GROUP BY joke_id
+			) cr ON cr.joke_id = pj.joke_id
+			LEFT JOIN customer_ratings mr ON mr.joke_id = pj.joke_id AND mr.customer_user_id = $2
+			LEFT JOIN joke_bids mb ON mb.joke_id = pj.joke_id AND mb.customer_user_id = $2 AND mb.status = 'OPEN'
+			LEFT JOIN market_labeled l ON l.team_id = pj.team_id
+			LEFT JOIN market_team_base tb ON tb.team_id = pj.team_id
+			WHERE pj.round_id = $1
+		)
 	`
-	rows, err := r.pool.Query(ctx, q, roundID, customerID)
+	const where = `
+		WHERE ($3 = '' OR to_tsvector('english', joke_text || ' ' || COALESCE(joke_title, '')) @@ websearch_to_tsquery('english', $3))
+		  AND ($4::bigint IS NULL OR team_id = $4)
+		  AND ($5 = '' OR label = $5)
+		  AND ($6::boolean IS NULL OR is_bought = $6)
+	`
+	args := []any{roundID, customerID, filter.Search, filter.TeamID, filter.Label, filter.Bought}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, market+`SELECT COUNT(*) FROM market `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	q := market + `
+		SELECT joke_id, joke_text, joke_title, team_id, name, label, purchase_count, is_bought, profit,
+		       accepted_jokes, sold_jokes_count, avg_rating, rating_count, my_rating, team_price,
+		       state_price, state_idle, auction_closes_at, my_bid
+		FROM market
+	` + where + `
+		ORDER BY ` + order + `
+		LIMIT $7 OFFSET $8
+	`
+	rows, err := r.pool.Query(ctx, q, append(args, filter.Limit, filter.Offset)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var items []ports.MarketItem
+	items := []ports.MarketItem{}
 	for rows.Next() {
 		var item ports.MarketItem
 		var title *string
-		if err := rows.Scan(&item.JokeID, &item.JokeText, &title, &item.TeamID, &item.TeamName, &item.TeamLabel, &item.BoughtCount, &item.IsBoughtByMe, &item.TeamProfit, &item.TeamAccepted, &item.TeamSold); err != nil {
-			return nil, err
+		var idle float64
+		if err := rows.Scan(&item.JokeID, &item.JokeText, &title, &item.TeamID, &item.TeamName, &item.TeamLabel, &item.BoughtCount, &item.IsBoughtByMe, &item.TeamProfit, &item.TeamAccepted, &item.TeamSold,
+			&item.CustomerRatingAvg, &item.CustomerRatingCount, &item.MyRating, &item.TeamPrice, &item.PriceState.Price, &idle,
+			&item.AuctionClosesAt, &item.MyBid); err != nil {
+			return nil, 0, err
 		}
 		item.JokeTitle = title
+		item.PriceState.Idle = secondsToDuration(idle)
 		items = append(items, item)
 	}
-	return items, nil
+	return items, total, rows.Err()
 }
 
-func (r *PostgresRepository) BuyJoke(ctx context.Context, roundID, customerID, jokeID int64, marketPrice float64) (*domain.Purchase, *domain.CustomerRoundBudget, int64, error) {
+func (r *PostgresRepository) BuyJoke(ctx context.Context, roundID, customerID, jokeID int64) (*domain.Purchase, *domain.CustomerRoundBudget, int64, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return nil, nil, 0, err
 	}
 	defer tx.Rollback(ctx)
 
+	// Lock the joke first so concurrent buyers take turns and each sees the price the
+	// previous sale left behind.
+	var teamID int64
+	if err := tx.QueryRow(ctx, `SELECT team_id FROM published_jokes WHERE joke_id = $1 AND round_id = $2 FOR UPDATE`, jokeID, roundID).Scan(&teamID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, 0, domain.NewNotFoundError("joke")
+		}
+		return nil, nil, 0, err
+	}
+	round, err := scanRound(tx.QueryRow(ctx, `SELECT `+roundColumns+` FROM rounds WHERE round_id = $1`, roundID))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	const stateQ = `
+		SELECT j.team_price::float8, ` + priceStateColumns + `
+		FROM published_jokes pj
+		JOIN jokes j ON j.joke_id = pj.joke_id
+		JOIN rounds r ON r.round_id = pj.round_id
+		` + latestPriceChangeJoin + `
+		WHERE pj.joke_id = $1
+	`
+	var teamPrice *float64
+	var state domain.PriceState
+	var idle float64
+	if err := tx.QueryRow(ctx, stateQ, jokeID).Scan(&teamPrice, &state.Price, &idle); err != nil {
+		return nil, nil, 0, err
+	}
+	state.Idle = secondsToDuration(idle)
+	price := round.JokePrice(teamPrice, state)
+
 	budget, err := r.getCustomerBudgetTx(ctx, tx, roundID, customerID)
 	if err != nil {
 		return nil, nil, 0, err
 	}
-	if budget.RemainingBudget < marketPrice {
+	if budget.RemainingBudget < price {
 		return nil, nil, 0, domain.NewConflictError("insufficient budget")
 	}
 
 	const purchaseQ = `
-		INSERT INTO purchases (round_id, customer_user_id, joke_id)
-		VALUES ($1, $2, $3)
+		INSERT INTO purchases (round_id, customer_user_id, joke_id, price)
+		VALUES ($1, $2, $3, $4)
 		RETURNING purchase_id, created_at
 	`
 	var p domain.Purchase
-	if err := tx.QueryRow(ctx, purchaseQ, roundID, customerID, jokeID).Scan(&p.ID, &p.CreatedAt); err != nil {
+	if err := tx.QueryRow(ctx, purchaseQ, roundID, customerID, jokeID, price).Scan(&p.ID, &p.CreatedAt); err != nil {
 		if isUniqueViolation(err) {
 			return nil, nil, 0, domain.NewConflictError("already bought")
 		}
@@ -1270,21 +1771,24 @@ func (r *PostgresRepository) BuyJoke(ctx context.Context, roundID, customerID, j
 	p.RoundID = roundID
 	p.CustomerUserID = customerID
 	p.JokeID = jokeID
+	p.Price = price
 
-	if _, err := tx.Exec(ctx, `UPDATE customer_round_budget SET remaining_budget = remaining_budget - $3, updated_at = now() WHERE round_id = $1 AND customer_user_id = $2`, roundID, customerID, marketPrice); err != nil {
+	if _, err := tx.Exec(ctx, `UPDATE customer_round_budget SET remaining_budget = remaining_budget - $3, updated_at = now() WHERE round_id = $1 AND customer_user_id = $2`, roundID, customerID, price); err != nil {
 		return nil, nil, 0, err
 	}
 
-	var teamID int64
-	if err := tx.QueryRow(ctx, `SELECT team_id FROM published_jokes WHERE joke_id = $1`, jokeID).Scan(&teamID); err != nil {
-		return nil, nil, 0, err
+	if round.PricingMode == domain.PricingDynamic {
+		const changeQ = `INSERT INTO joke_price_changes (round_id, joke_id, sale_price, price) VALUES ($1, $2, $3, $4)`
+		if _, err := tx.Exec(ctx, changeQ, roundID, jokeID, price, round.RaisedPrice(price)); err != nil {
+			return nil, nil, 0, err
+		}
 	}
 
-	if err := r.updateTeamPoints(ctx, roundID, teamID, 1); err != nil {
+	if err := r.updateTeamPoints(ctx, tx, roundID, teamID, 1); err != nil {
 		return nil, nil, 0, err
 	}
 
-	if _, err := tx.Exec(ctx, `INSERT INTO purchase_events (round_id, customer_user_id, joke_id, team_id, delta) VALUES ($1, $2, $3, $4, 1)`, roundID, customerID, jokeID, teamID); err != nil {
+	if _, err := tx.Exec(ctx, `INSERT INTO purchase_events (round_id, customer_user_id, joke_id, team_id, delta, price) VALUES ($1, $2, $3, $4, 1, $5)`, roundID, customerID, jokeID, teamID, price); err != nil {
 		return nil, nil, 0, err
 	}
 
@@ -1298,21 +1802,27 @@ func (r *PostgresRepository) BuyJoke(ctx context.Context, roundID, customerID, j
 	return &p, budget, teamID, nil
 }
 
-func (r *PostgresRepository) ReturnJoke(ctx context.Context, roundID, customerID, jokeID int64, marketPrice float64) (*domain.Purchase, *domain.CustomerRoundBudget, int64, error) {
+func (r *PostgresRepository) ReturnJoke(ctx context.Context, roundID, customerID, jokeID int64) (*domain.Purchase, *domain.CustomerRoundBudget, int64, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return nil, nil, 0, err
 	}
 	defer tx.Rollback(ctx)
 
+	// Lock the customer's budget first so their returns are counted one at a time.
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM customer_round_budget WHERE round_id = $1 AND customer_user_id = $2 FOR UPDATE`, roundID, customerID); err != nil {
+		return nil, nil, 0, err
+	}
+
 	const findQ = `
-		SELECT purchase_id, created_at
+		SELECT purchase_id, price, created_at, EXTRACT(EPOCH FROM now() - created_at)::float8
 		FROM purchases
 		WHERE round_id = $1 AND customer_user_id = $2 AND joke_id = $3
 		FOR UPDATE
 	`
 	var p domain.Purchase
-	if err := tx.QueryRow(ctx, findQ, roundID, customerID, jokeID).Scan(&p.ID, &p.CreatedAt); err != nil {
+	var age float64
+	if err := tx.QueryRow(ctx, findQ, roundID, customerID, jokeID).Scan(&p.ID, &p.Price, &p.CreatedAt, &age); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil, 0, domain.NewConflictError("not bought yet")
 		}
@@ -1322,11 +1832,31 @@ func (r *PostgresRepository) ReturnJoke(ctx context.Context, roundID, customerID
 	p.CustomerUserID = customerID
 	p.JokeID = jokeID
 
+	round, err := scanRound(tx.QueryRow(ctx, `SELECT `+roundColumns+` FROM rounds WHERE round_id = $1`, roundID))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	used, err := countReturns(ctx, tx, roundID, customerID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if err := round.CheckReturn(secondsToDuration(age), used); err != nil {
+		return nil, nil, 0, err
+	}
+	refund := round.Refund(p.Price)
+	p.Refund = &refund
+
 	if _, err := tx.Exec(ctx, `DELETE FROM purchases WHERE purchase_id = $1`, p.ID); err != nil {
 		return nil, nil, 0, err
 	}
+	// A rating only stands while the customer holds the joke.
+	if _, err := tx.Exec(ctx, `DELETE FROM customer_ratings WHERE customer_user_id = $1 AND joke_id = $2`, customerID, jokeID); err != nil {
+		return nil, nil, 0, err
+	}
 
-	if _, err := tx.Exec(ctx, `UPDATE customer_round_budget SET remaining_budget = remaining_budget + $3, updated_at = now() WHERE round_id = $1 AND customer_user_id = $2`, roundID, customerID, marketPrice); err != nil {
+	// Refund from what was actually paid, whatever the price is now. The event records
+	// the refund, so the team keeps whatever part of the price isn't paid back.
+	if _, err := tx.Exec(ctx, `UPDATE customer_round_budget SET remaining_budget = remaining_budget + $3, updated_at = now() WHERE round_id = $1 AND customer_user_id = $2`, roundID, customerID, refund); err != nil {
 		return nil, nil, 0, err
 	}
 
@@ -1335,11 +1865,11 @@ func (r *PostgresRepository) ReturnJoke(ctx context.Context, roundID, customerID
 		return nil, nil, 0, err
 	}
 
-	if err := r.updateTeamPoints(ctx, roundID, teamID, -1); err != nil {
+	if err := r.updateTeamPoints(ctx, tx, roundID, teamID, -1); err != nil {
 		return nil, nil, 0, err
 	}
 
-	if _, err := tx.Exec(ctx, `INSERT INTO purchase_events (round_id, customer_user_id, joke_id, team_id, delta) VALUES ($1, $2, $3, $4, -1)`, roundID, customerID, jokeID, teamID); err != nil {
+	if _, err := tx.Exec(ctx, `INSERT INTO purchase_events (round_id, customer_user_id, joke_id, team_id, delta, price) VALUES ($1, $2, $3, $4, -1, $5)`, roundID, customerID, jokeID, teamID, refund); err != nil {
 		return nil, nil, 0, err
 	}
 
@@ -1354,6 +1884,138 @@ func (r *PostgresRepository) ReturnJoke(ctx context.Context, roundID, customerID
 	return &p, budget, teamID, nil
 }
 
+func (r *PostgresRepository) CountCustomerReturns(ctx context.Context, roundID, customerID int64) (int, error) {
+	return countReturns(ctx, r.pool, roundID, customerID)
+}
+
+func countReturns(ctx context.Context, db rowQuerier, roundID, customerID int64) (int, error) {
+	var n int
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM purchase_events WHERE round_id = $1 AND customer_user_id = $2 AND delta = -1`, roundID, customerID).Scan(&n)
+	return n, err
+}
+
+func (r *PostgresRepository) ListPurchaseEvents(ctx context.Context, roundID int64) ([]domain.PurchaseEvent, error) {
+	const q = `
+		SELECT event_id, round_id, customer_user_id, joke_id, team_id, delta, price, created_at
+		FROM purchase_events
+		WHERE round_id = $1
+		ORDER BY created_at, event_id
+	`
+	rows, err := r.pool.Query(ctx, q, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.PurchaseEvent
+	for rows.Next() {
+		var e domain.PurchaseEvent
+		if err := rows.Scan(&e.ID, &e.RoundID, &e.CustomerUserID, &e.JokeID, &e.TeamID, &e.Delta, &e.Price, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (r *PostgresRepository) GetJokePricing(ctx context.Context, jokeID int64) (*ports.JokePricing, error) {
+	const q = `
+		SELECT j.joke_id, b.round_id, b.team_id, j.team_price::float8, pj.created_at
+		FROM jokes j
+		JOIN batches b ON b.batch_id = j.batch_id
+		LEFT JOIN published_jokes pj ON pj.joke_id = j.joke_id
+		WHERE j.joke_id = $1
+	`
+	var jp ports.JokePricing
+	if err := r.pool.QueryRow(ctx, q, jokeID).Scan(&jp.JokeID, &jp.RoundID, &jp.TeamID, &jp.TeamPrice, &jp.PublishedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("joke")
+		}
+		return nil, err
+	}
+	return &jp, nil
+}
+
+func (r *PostgresRepository) SetJokeTeamPrice(ctx context.Context, jokeID int64, price *float64) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE jokes SET team_price = $2 WHERE joke_id = $1`, jokeID, price)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewNotFoundError("joke")
+	}
+	return nil
+}
+
+func (r *PostgresRepository) ListJokePriceChanges(ctx context.Context, jokeID int64) ([]domain.JokePriceChange, error) {
+	const q = `
+		SELECT change_id, round_id, joke_id, sale_price::float8, price::float8, created_at
+		FROM joke_price_changes
+		WHERE joke_id = $1
+		ORDER BY change_id
+	`
+	rows, err := r.pool.Query(ctx, q, jokeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []domain.JokePriceChange{}
+	for rows.Next() {
+		var c domain.JokePriceChange
+		if err := rows.Scan(&c.ID, &c.RoundID, &c.JokeID, &c.SalePrice, &c.Price, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func (r *PostgresRepository) RateJoke(ctx context.Context, rating *domain.CustomerRating) error {
+	const q = `
+		INSERT INTO customer_ratings (round_id, customer_user_id, joke_id, rating, review)
+		SELECT p.round_id, p.customer_user_id, p.joke_id, $4, $5
+		FROM purchases p
+		WHERE p.round_id = $1 AND p.customer_user_id = $2 AND p.joke_id = $3
+		ON CONFLICT (customer_user_id, joke_id) DO UPDATE
+		SET rating = EXCLUDED.rating, review = EXCLUDED.review, updated_at = now()
+		RETURNING created_at, updated_at
+	`
+	if err := r.pool.QueryRow(ctx, q, rating.RoundID, rating.CustomerUserID, rating.JokeID, rating.Rating, rating.Review).Scan(&rating.CreatedAt, &rating.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewConflictError("not bought yet")
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresRepository) ListCustomerPurchaseHistory(ctx context.Context, roundID, customerID int64) ([]ports.PurchaseHistoryEntry, error) {
+	const q = `
+		SELECT pe.event_id, pe.joke_id, j.joke_text, j.joke_title, pe.team_id, t.name, pe.delta, pe.price, pe.created_at
+		FROM purchase_events pe
+		JOIN jokes j ON j.joke_id = pe.joke_id
+		JOIN teams t ON t.id = pe.team_id
+		WHERE pe.round_id = $1 AND pe.customer_user_id = $2
+		ORDER BY pe.event_id
+	`
+	rows, err := r.pool.Query(ctx, q, roundID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []ports.PurchaseHistoryEntry{}
+	for rows.Next() {
+		var e ports.PurchaseHistoryEntry
+		if err := rows.Scan(&e.EventID, &e.JokeID, &e.JokeText, &e.JokeTitle, &e.TeamID, &e.TeamName, &e.Delta, &e.Price, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
 func (r *PostgresRepository) getCustomerBudgetTx(ctx context.Context, tx pgx.Tx, roundID, customerID int64) (*domain.CustomerRoundBudget, error) {
 	const q = `
 		SELECT round_id, customer_user_id, starting_budget, remaining_budget, created_at, updated_at
@@ -1389,9 +2051,10 @@ func (r *PostgresRepository) GetTeamSummary(ctx context.Context, roundID, teamID
 			GROUP BY trs.points_earned, trs.batches_created, trs.batches_rated, trs.accepted_jokes
 		),
 		unrated AS (
-			SELECT COUNT(*) AS cnt
-			FROM batches
-			WHERE round_id = $1 AND team_id = $2 AND status = 'SUBMITTED'
+			SELECT COUNT(DISTINCT b.batch_id) AS cnt, COUNT(j.joke_id) AS jokes
+			FROM batches b
+			LEFT JOIN jokes j ON j.batch_id = b.batch_id
+			WHERE b.round_id = $1 AND b.team_id = $2 AND b.status = 'SUBMITTED'
 		),
 		sales AS (
 			SELECT COUNT(*) AS total_sales
@@ -1406,7 +2069,7 @@ func (r *PostgresRepository) GetTeamSummary(ctx context.Context, roundID, teamID
 		),
 		profit_rank AS (
 			SELECT trs.team_id,
-			       (SELECT market_price FROM cfg) * COALESCE(sales_all.total_sales, 0)::double precision
+			       COALESCE(sales_all.revenue, 0)
 			         - (SELECT cost_of_publishing FROM cfg) * COALESCE(market_all.total_market, 0)::double precision AS profit
 			FROM team_rounds_state trs
 			LEFT JOIN market_sales_counts sales_all ON sales_all.team_id = trs.team_id
@@ -1427,7 +2090,7 @@ func (r *PostgresRepository) GetTeamSummary(ctx context.Context, roundID, teamID
 		       sa.total_sales, s.batches_created, s.batches_rated, s.accepted_jokes,
 		       COALESCE(us.unsold_jokes, 0),
 		       GREATEST(s.accepted_jokes - COALESCE(us.unsold_jokes, 0), 0) AS sold_jokes_count,
-		       COALESCE(s.avg_score, 0), u.cnt
+		       COALESCE(s.avg_score, 0), u.cnt, u.jokes
 		FROM teams t
 		JOIN stats s ON true
 		JOIN ranks r ON r.team_id = t.id
@@ -1442,7 +2105,7 @@ func (r *PostgresRepository) GetTeamSummary(ctx context.Context, roundID, teamID
 	if err := r.pool.QueryRow(ctx, q, roundID, teamID).Scan(
 		&summary.Team.ID, &summary.Team.Name, &summary.RoundID, &summary.Rank, &summary.Points, &summary.Profit, &summary.Performance, &summary.TotalSales,
 		&summary.BatchesCreated, &summary.BatchesRated, &summary.AcceptedJokes,
-		&summary.UnsoldJokes, &summary.SoldJokesCount, &summary.AvgScoreOverall, &summary.UnratedBatches,
+		&summary.UnsoldJokes, &summary.SoldJokesCount, &summary.AvgScoreOverall, &summary.UnratedBatches, &summary.UnratedJokes,
 	); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewNotFoundError("team summary")
@@ -1459,11 +2122,13 @@ func (r *PostgresRepository) GetLobby(ctx context.Context, roundID int64) (*port
 	const summaryQ = `
 		SELECT
 			(SELECT COUNT(*) FROM users u
-				WHERE u.status = 'WAITING' AND (u.role IS NULL OR u.role <> 'INSTRUCTOR')) AS waiting,
+				WHERE u.status = 'WAITING' AND (u.role IS NULL OR u.role <> 'INSTRUCTOR') AND u.removed_at IS NULL) AS waiting,
+			(SELECT COUNT(*) FROM users u
+				WHERE u.status = 'ASSIGNED' AND (u.role IS NULL OR u.role <> 'INSTRUCTOR') AND u.removed_at IS NULL) AS assigned,
 			(SELECT COUNT(*) FROM users u
-				WHERE u.status = 'ASSIGNED' AND (u.role IS NULL OR u.role <> 'INSTRUCTOR')) AS assigned
+				WHERE u.status = 'WAITLISTED' AND u.removed_at IS NULL) AS waitlisted
 	`
-	if err := r.pool.QueryRow(ctx, summaryQ).Scan(&snapshot.Summary.Waiting, &snapshot.Summary.Assigned); err != nil {
+	if err := r.pool.QueryRow(ctx, summaryQ).Scan(&snapshot.Summary.Waiting, &snapshot.Summary.Assigned, &snapshot.Summary.Waitlisted); err != nil {
 		return nil, err
 	}
 	snapshot.Summary.Dropped = 0
@@ -1510,9 +2175,30 @@ func (r *PostgresRepository) GetLobby(ctx context.Context, roundID int64) (*port
 			UserID:      u.ID,
 			DisplayName: u.DisplayName,
 			Status:      domain.ParticipantWaiting,
+			LastSeenAt:  u.LastSeenAt,
+		})
+	}
+
+	waitlisted, err := r.ListUsersByStatus(ctx, domain.ParticipantWaitlisted)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range waitlisted {
+		snapshot.Waitlist = append(snapshot.Waitlist, ports.LobbyUnassigned{
+			UserID:      u.ID,
+			DisplayName: u.DisplayName,
+			Status:      domain.ParticipantWaitlisted,
+			LastSeenAt:  u.LastSeenAt,
 		})
 	}
 
+	removed, err := r.ListRemovedUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Removed = removed
+	snapshot.Summary.Removed = len(removed)
+
 	return &snapshot, nil
 }
 
@@ -1520,7 +2206,6 @@ func (r *PostgresRepository) GetRoundStats(ctx context.Context, roundID int64) (
 	const q = `
 		WITH cfg AS (
 			SELECT
-				COALESCE(market_price, 1)::double precision AS market_price,
 				COALESCE(cost_of_publishing, 0.1)::double precision AS cost_of_publishing
 			FROM rounds
 			WHERE round_id = $1
@@ -1570,17 +2255,16 @@ func (r *PostgresRepository) GetRoundStats(ctx context.Context, roundID int64) (
 				COALESCE(rrej.rejected_jokes, 0) AS unaccepted_jokes,
 				COALESCE(jc.total_jokes, 0) AS total_jokes,
 				COALESCE(u.unsold_jokes, 0) AS unsold_jokes,
-				(SELECT market_price FROM cfg) * COALESCE(sales.total_sales, 0)::double precision
+				COALESCE(sales.revenue, 0)
 				  - (SELECT cost_of_publishing FROM cfg) * COALESCE(m.total_market, 0)::double precision AS profit,
 				COALESCE(ra.avg_score, 0) AS avg_score
 			FROM team_rounds_state trs
 			JOIN teams t ON t.id = trs.team_id
 			LEFT JOIN (
-				SELECT pj.team_id, COUNT(*) AS total_sales
-				FROM purchases p
-				JOIN published_jokes pj ON pj.joke_id = p.joke_id
-				WHERE p.round_id = $1
-				GROUP BY pj.team_id
+				SELECT pe.team_id, SUM(pe.delta) AS total_sales, SUM(pe.delta * pe.price)::double precision AS revenue
+				FROM purchase_events pe
+				WHERE pe.round_id = $1
+				GROUP BY pe.team_id
 			) sales ON sales.team_id = trs.team_id
 			LEFT JOIN joke_counts jc ON jc.team_id = trs.team_id
 			LEFT JOIN rejected_jokes rrej ON rrej.team_id = trs.team_id
@@ -1588,7 +2272,7 @@ func (r *PostgresRepository) GetRoundStats(ctx context.Context, roundID int64) (
 			LEFT JOIN unsold u ON u.team_id = trs.team_id
 			LEFT JOIN market m ON m.team_id = trs.team_id
 			WHERE trs.round_id = $1
-			GROUP BY t.id, t.name, trs.batches_rated, sales.total_sales, trs.accepted_jokes, rrej.rejected_jokes, jc.total_jokes, u.unsold_jokes, ra.avg_score, m.total_market
+			GROUP BY t.id, t.name, trs.batches_rated, sales.total_sales, sales.revenue, trs.accepted_jokes, rrej.rejected_jokes, jc.total_jokes, u.unsold_jokes, ra.avg_score, m.total_market
 		)
 		SELECT
 			DENSE_RANK() OVER (ORDER BY profit DESC) as rank,
@@ -1631,8 +2315,13 @@ func (r *PostgresRepository) GetRoundStatsV2(ctx context.Context, roundID int64)
 	}
 
 	result := &ports.RoundStats{
-		RoundID:     roundID,
-		Leaderboard: leaderboard,
+		RoundID:       roundID,
+		QCRoutingMode: domain.QCRoutingOwnTeam,
+		Leaderboard:   leaderboard,
+	}
+	if err := r.pool.QueryRow(ctx, `SELECT qc_routing_mode FROM rounds WHERE round_id = $1`, roundID).Scan(&result.QCRoutingMode); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		r.log.Error("GetRoundStatsV2: routing mode query failed", "round_id", roundID, "error", err)
+		return nil, err
 	}
 
 	// Rejection chart data per team:
@@ -1689,19 +2378,44 @@ func (r *PostgresRepository) GetRoundStatsV2(ctx context.Context, roundID int64)
 		result.SalesOverTime = append(result.SalesOverTime, pnt)
 	}
 
-	// Unrated jokes queue size over time (submission and rating events)
+	// Unrated jokes queue size over time (submission and rating events), attributed to
+	// the QC queue each batch landed in under the routing mode it was submitted with, so
+	// changing the round's mode later doesn't rewrite earlier history:
+	// - OWN_TEAM: the submitting team's own queue
+	// - ROTATION: the queue of the next team in id order (see domain.QCRotationSourceTeam)
+	// - GLOBAL_POOL: one shared queue, reported as team_id 0
 	const unratedQ = `
-		WITH events AS (
+		WITH round_teams AS (
+			SELECT team_id,
+			       LEAD(team_id) OVER (ORDER BY team_id) AS next_team_id,
+			       FIRST_VALUE(team_id) OVER (ORDER BY team_id) AS first_team_id
+			FROM team_rounds_state
+			WHERE round_id = $1
+		),
+		events AS (
+			SELECT e.event_id,
+			       e.created_at,
+			       CASE b.qc_routing_mode
+			           WHEN 'ROTATION' THEN COALESCE(rt.next_team_id, rt.first_team_id, e.team_id)
+			           WHEN 'GLOBAL_POOL' THEN 0
+			           ELSE e.team_id
+			       END AS team_id,
+			       e.delta
+			FROM batch_submission_events e
+			JOIN batches b ON b.batch_id = e.batch_id
+			LEFT JOIN round_teams rt ON rt.team_id = e.team_id
+			WHERE e.round_id = $1
+		),
+		numbered AS (
 			SELECT e.event_id,
 			       e.created_at,
 			       e.team_id,
-			       t.name AS team_name,
+			       COALESCE(t.name, 'All teams') AS team_name,
 			       e.delta,
 			       ROW_NUMBER() OVER (ORDER BY e.created_at, e.event_id) AS event_idx,
 			       ROW_NUMBER() OVER (PARTITION BY e.team_id ORDER BY e.created_at, e.event_id) AS team_idx
-			FROM batch_submission_events e
-			JOIN teams t ON t.id = e.team_id
-			WHERE e.round_id = $1
+			FROM events e
+			LEFT JOIN teams t ON t.id = e.team_id
 		),
 		queue AS (
 			SELECT e.event_idx,
@@ -1714,7 +2428,7 @@ func (r *PostgresRepository) GetRoundStatsV2(ctx context.Context, roundID int64)
 			       	ORDER BY e.created_at, e.event_id
 			       	ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW
 			       ) AS queue_count
-			FROM events e
+			FROM numbered e
 		)
 		SELECT event_idx,
 		       team_idx,
@@ -1817,6 +2531,72 @@ func (r *PostgresRepository) GetRoundStatsV2(ctx context.Context, roundID int64)
 		result.BatchSizeQuality = append(result.BatchSizeQuality, pnt)
 	}
 
+	// Team chat volume, split by the sender's role when they posted.
+	const messagesQ = `
+		SELECT team_id,
+		       COUNT(*) AS messages,
+		       COUNT(*) FILTER (WHERE sender_role = 'JM') AS jm_messages,
+		       COUNT(*) FILTER (WHERE sender_role = 'QC') AS qc_messages
+		FROM chat_messages
+		WHERE round_id = $1 AND team_id IS NOT NULL
+		GROUP BY team_id
+	`
+	msgRows, err := r.pool.Query(ctx, messagesQ, roundID)
+	if err != nil {
+		r.log.Error("GetRoundStatsV2: messages query failed", "round_id", roundID, "error", err)
+		return nil, err
+	}
+	defer msgRows.Close()
+	counts := map[int64]ports.TeamMessageCount{}
+	for msgRows.Next() {
+		var cnt ports.TeamMessageCount
+		if err := msgRows.Scan(&cnt.TeamID, &cnt.Messages, &cnt.JMMessages, &cnt.QCMessages); err != nil {
+			return nil, err
+		}
+		counts[cnt.TeamID] = cnt
+	}
+	if err := msgRows.Err(); err != nil {
+		return nil, err
+	}
+	// Every team on the leaderboard gets a row, silent teams included.
+	for _, t := range leaderboard {
+		cnt := counts[t.Team.ID]
+		cnt.TeamID = t.Team.ID
+		cnt.TeamName = t.Team.Name
+		result.MessagesByTeam = append(result.MessagesByTeam, cnt)
+	}
+
+	// Customer opinion vs QC rating, per joke that at least one customer rated.
+	const ratingsQ = `
+		SELECT cr.joke_id, pj.team_id, t.name, jr.rating,
+		       AVG(cr.rating)::float8 AS customer_avg,
+		       COUNT(*) AS customer_ratings
+		FROM customer_ratings cr
+		JOIN published_jokes pj ON pj.joke_id = cr.joke_id
+		JOIN joke_ratings jr ON jr.joke_id = cr.joke_id
+		JOIN teams t ON t.id = pj.team_id
+		WHERE cr.round_id = $1
+		GROUP BY cr.joke_id, pj.team_id, t.name, jr.rating
+		ORDER BY cr.joke_id
+	`
+	ratingRows, err := r.pool.Query(ctx, ratingsQ, roundID)
+	if err != nil {
+		r.log.Error("GetRoundStatsV2: customer ratings query failed", "round_id", roundID, "error", err)
+		return nil, err
+	}
+	defer ratingRows.Close()
+	result.CustomerVsQCRatings = []ports.RatingComparisonPoint{}
+	for ratingRows.Next() {
+		var pnt ports.RatingComparisonPoint
+		if err := ratingRows.Scan(&pnt.JokeID, &pnt.TeamID, &pnt.TeamName, &pnt.QCRating, &pnt.CustomerAvg, &pnt.CustomerRatings); err != nil {
+			return nil, err
+		}
+		result.CustomerVsQCRatings = append(result.CustomerVsQCRatings, pnt)
+	}
+	if err := ratingRows.Err(); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
@@ -1840,7 +2620,14 @@ func (r *PostgresRepository) ResetGame(ctx context.Context) error {
 			joke_ratings,
 			jokes,
 			batches,
-			team_rounds_state
+			team_rounds_state,
+			lobby_undo_entries,
+			announcements,
+			chat_messages,
+			chat_read_cursors,
+			customer_ratings,
+			joke_price_changes,
+			joke_bids
 		RESTART IDENTITY CASCADE
 	`
 	if _, err := tx.Exec(ctx, truncateQ); err != nil {
@@ -1860,7 +2647,24 @@ func (r *PostgresRepository) ResetGame(ctx context.Context) error {
 		    cost_of_publishing = $4,
 		    started_at = NULL,
 		    ended_at = NULL,
-		    is_popped_active = FALSE
+		    is_popped_active = FALSE,
+		    qc_routing_mode = 'OWN_TEAM',
+		    max_unrated_batches = NULL,
+		    max_unrated_jokes = NULL,
+		    late_joiner_policy = 'WAIT',
+		    chat_enabled = TRUE,
+		    pricing_mode = 'FLAT',
+		    min_joke_price = NULL,
+		    max_joke_price = NULL,
+		    price_step = 0,
+		    price_decay = 0,
+		    auction_rule = 'FIRST_PRICE',
+		    auction_window_seconds = 60,
+		    auction_started_at = NULL,
+		    returns_allowed = TRUE,
+		    return_window_seconds = NULL,
+		    max_returns = NULL,
+		    return_refund_percent = 100
 	`
 	if _, err := tx.Exec(ctx, resetRoundsQ, domain.DefaultInstructorCustomerBudget, domain.DefaultInstructorBatchSize, domain.DefaultMarketPrice, domain.DefaultCostOfPublishing); err != nil {
 		r.log.Error("ResetGame: reset rounds failed", "error", err)
@@ -1871,6 +2675,16 @@ func (r *PostgresRepository) ResetGame(ctx context.Context) error {
 		r.log.Error("ResetGame: delete users failed", "error", err)
 		return err
 	}
+	// Scripts are kept for the next class, but a run in progress would act on the reset rounds.
+	if _, err := tx.Exec(ctx, `UPDATE script_runs SET status = 'STOPPED', resumed_at = NULL, ended_at = now() WHERE status IN ('RUNNING', 'PAUSED')`); err != nil {
+		r.log.Error("ResetGame: stop script run failed", "error", err)
+		return err
+	}
+	// The class roster is kept, but every join code can be used afresh.
+	if _, err := tx.Exec(ctx, `UPDATE roster_entries SET user_id = NULL, claimed_at = NULL`); err != nil {
+		r.log.Error("ResetGame: release roster failed", "error", err)
+		return err
+	}
 
 	if _, err := tx.Exec(ctx, `DELETE FROM teams`); err != nil {
 		r.log.Error("ResetGame: delete teams failed", "error", err)
@@ -0,0 +1,150 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"jokefactory/src/core/domain"
+)
+
+const rosterColumns = `roster_id, display_name, student_id, preferred_team, preferred_role, join_code, user_id, claimed_at, created_at`
+
+func scanRosterEntry(row pgx.Row) (*domain.RosterEntry, error) {
+	var e domain.RosterEntry
+	if err := row.Scan(&e.ID, &e.DisplayName, &e.StudentID, &e.PreferredTeam, &e.PreferredRole, &e.JoinCode,
+		&e.UserID, &e.ClaimedAt, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *PostgresRepository) ImportRoster(ctx context.Context, entries []domain.RosterEntry) ([]domain.RosterEntry, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	const q = `
+		INSERT INTO roster_entries (display_name, student_id, preferred_team, preferred_role, join_code)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (student_id) WHERE student_id IS NOT NULL DO UPDATE
+		SET display_name = EXCLUDED.display_name,
+		    preferred_team = EXCLUDED.preferred_team,
+		    preferred_role = EXCLUDED.preferred_role
+		RETURNING ` + rosterColumns + `
+	`
+	out := make([]domain.RosterEntry, 0, len(entries))
+	for _, e := range entries {
+		stored, err := scanRosterEntry(tx.QueryRow(ctx, q, e.DisplayName, e.StudentID, e.PreferredTeam, e.PreferredRole, e.JoinCode))
+		if err != nil {
+			if isUniqueViolation(err) {
+				return nil, domain.NewConflictError("join code collision; retry the import")
+			}
+			return nil, err
+		}
+		out = append(out, *stored)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *PostgresRepository) ListRoster(ctx context.Context) ([]domain.RosterEntry, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+rosterColumns+` FROM roster_entries ORDER BY roster_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.RosterEntry{}
+	for rows.Next() {
+		e, err := scanRosterEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	return out, rows.Err()
+}
+
+func (r *PostgresRepository) ClaimJoinCode(ctx context.Context, joinCode, rejoinSecretHash string) (*domain.User, bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	entry, err := scanRosterEntry(tx.QueryRow(ctx, `SELECT `+rosterColumns+` FROM roster_entries WHERE join_code = upper($1) FOR UPDATE`, joinCode))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, domain.NewUnauthorizedError("invalid join code")
+		}
+		return nil, false, err
+	}
+
+	if entry.UserID != nil {
+		var removedAt *time.Time
+		if err := tx.QueryRow(ctx, `SELECT removed_at FROM users WHERE user_id = $1`, *entry.UserID).Scan(&removedAt); err != nil {
+			return nil, false, err
+		}
+		if removedAt != nil {
+			return nil, false, domain.NewForbiddenError("this participant has been removed from the game")
+		}
+		const reclaimQ = `
+			UPDATE users
+			SET rejoin_secret_hash = $2, last_seen_at = now()
+			WHERE user_id = $1
+			RETURNING ` + userColumns + `
+		`
+		u, err := scanUser(tx.QueryRow(ctx, reclaimQ, *entry.UserID, rejoinSecretHash))
+		if err != nil {
+			return nil, false, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, false, err
+		}
+		return u, true, nil
+	}
+
+	u, err := createParticipantTx(ctx, tx, entry.DisplayName, rejoinSecretHash)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE roster_entries SET user_id = $2, claimed_at = now() WHERE roster_id = $1`, entry.ID, u.ID); err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, err
+	}
+	return u, false, nil
+}
+
+func (r *PostgresRepository) GetGameSettings(ctx context.Context) (*domain.GameSettings, error) {
+	var s domain.GameSettings
+	err := r.pool.QueryRow(ctx, `SELECT require_join_code, max_participants, updated_at FROM game_settings`).Scan(&s.RequireJoinCode, &s.MaxParticipants, &s.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &domain.GameSettings{}, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *PostgresRepository) UpdateGameSettings(ctx context.Context, settings *domain.GameSettings) error {
+	const q = `
+		INSERT INTO game_settings (singleton, require_join_code, max_participants, updated_at)
+		VALUES (TRUE, $1, $2, now())
+		ON CONFLICT (singleton) DO UPDATE
+		SET require_join_code = EXCLUDED.require_join_code,
+		    max_participants = EXCLUDED.max_participants,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+	return r.pool.QueryRow(ctx, q, settings.RequireJoinCode, settings.MaxParticipants).Scan(&settings.UpdatedAt)
+}
@@ -0,0 +1,242 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"jokefactory/src/core/domain"
+)
+
+const scriptColumns = `script_id, name, steps, created_by, created_at, updated_at`
+
+func scanScript(row pgx.Row) (*domain.GameScript, error) {
+	var s domain.GameScript
+	var steps []byte
+	if err := row.Scan(&s.ID, &s.Name, &steps, &s.CreatedBy, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(steps, &s.Steps); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+const scriptRunColumns = `run_id, script_id, script_name, steps, status::text, next_step, elapsed_ms, resumed_at,
+	started_by, started_at, ended_at`
+
+func scanScriptRun(row pgx.Row) (*domain.ScriptRun, error) {
+	var run domain.ScriptRun
+	var steps []byte
+	var elapsedMS int64
+	if err := row.Scan(&run.ID, &run.ScriptID, &run.ScriptName, &steps, &run.Status, &run.NextStep, &elapsedMS,
+		&run.ResumedAt, &run.StartedBy, &run.StartedAt, &run.EndedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(steps, &run.Steps); err != nil {
+		return nil, err
+	}
+	run.Elapsed = time.Duration(elapsedMS) * time.Millisecond
+	return &run, nil
+}
+
+func (r *PostgresRepository) CreateScript(ctx context.Context, script *domain.GameScript) error {
+	steps, err := json.Marshal(script.Steps)
+	if err != nil {
+		return err
+	}
+	const q = `
+		INSERT INTO game_scripts (name, steps, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING script_id, created_at, updated_at
+	`
+	return r.pool.QueryRow(ctx, q, script.Name, steps, script.CreatedBy).Scan(&script.ID, &script.CreatedAt, &script.UpdatedAt)
+}
+
+func (r *PostgresRepository) UpdateScript(ctx context.Context, script *domain.GameScript) error {
+	steps, err := json.Marshal(script.Steps)
+	if err != nil {
+		return err
+	}
+	const q = `
+		UPDATE game_scripts
+		SET name = $2, steps = $3, updated_at = now()
+		WHERE script_id = $1
+		RETURNING created_by, created_at, updated_at
+	`
+	if err := r.pool.QueryRow(ctx, q, script.ID, script.Name, steps).Scan(&script.CreatedBy, &script.CreatedAt, &script.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewNotFoundError("script")
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresRepository) GetScript(ctx context.Context, scriptID int64) (*domain.GameScript, error) {
+	s, err := scanScript(r.pool.QueryRow(ctx, `SELECT `+scriptColumns+` FROM game_scripts WHERE script_id = $1`, scriptID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("script")
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *PostgresRepository) ListScripts(ctx context.Context) ([]domain.GameScript, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+scriptColumns+` FROM game_scripts ORDER BY script_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.GameScript{}
+	for rows.Next() {
+		s, err := scanScript(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
+
+func (r *PostgresRepository) DeleteScript(ctx context.Context, scriptID int64) error {
+	res, err := r.pool.Exec(ctx, `DELETE FROM game_scripts WHERE script_id = $1`, scriptID)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.NewNotFoundError("script")
+	}
+	return nil
+}
+
+func (r *PostgresRepository) StartScriptRun(ctx context.Context, run *domain.ScriptRun) error {
+	steps, err := json.Marshal(run.Steps)
+	if err != nil {
+		return err
+	}
+	q := `
+		INSERT INTO script_runs (script_id, script_name, steps, status, resumed_at, started_by)
+		VALUES ($1, $2, $3, 'RUNNING', now(), $4)
+		RETURNING ` + scriptRunColumns + `
+	`
+	started, err := scanScriptRun(r.pool.QueryRow(ctx, q, run.ScriptID, run.ScriptName, steps, run.StartedBy))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.NewConflictError("another script is already running")
+		}
+		return err
+	}
+	*run = *started
+	return nil
+}
+
+func (r *PostgresRepository) GetScriptRun(ctx context.Context, runID int64) (*domain.ScriptRun, error) {
+	run, err := scanScriptRun(r.pool.QueryRow(ctx, `SELECT `+scriptRunColumns+` FROM script_runs WHERE run_id = $1`, runID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("script run")
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+func (r *PostgresRepository) GetCurrentScriptRun(ctx context.Context) (*domain.ScriptRun, error) {
+	q := `SELECT ` + scriptRunColumns + ` FROM script_runs WHERE status IN ('RUNNING', 'PAUSED')`
+	run, err := scanScriptRun(r.pool.QueryRow(ctx, q))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("script run")
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+func (r *PostgresRepository) SetScriptRunStatus(ctx context.Context, runID int64, to domain.ScriptRunStatus, from ...domain.ScriptRunStatus) (*domain.ScriptRun, error) {
+	fromText := make([]string, 0, len(from))
+	for _, st := range from {
+		fromText = append(fromText, string(st))
+	}
+	// Leaving RUNNING banks the time since resumed_at; entering it restarts the clock.
+	q := `
+		UPDATE script_runs
+		SET elapsed_ms = CASE
+		        WHEN status = 'RUNNING' AND resumed_at IS NOT NULL
+		        THEN elapsed_ms + (EXTRACT(EPOCH FROM now() - resumed_at) * 1000)::bigint
+		        ELSE elapsed_ms
+		    END,
+		    resumed_at = CASE WHEN $2::text = 'RUNNING' THEN now() ELSE NULL END,
+		    ended_at = CASE WHEN $2::text IN ('FINISHED', 'STOPPED') THEN now() ELSE ended_at END,
+		    status = $2::script_run_status
+		WHERE run_id = $1 AND status::text = ANY($3)
+		RETURNING ` + scriptRunColumns + `
+	`
+	run, err := scanScriptRun(r.pool.QueryRow(ctx, q, runID, string(to), fromText))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, getErr := r.GetScriptRun(ctx, runID); getErr != nil {
+				return nil, getErr
+			}
+			return nil, domain.NewConflictError("script run is not " + strings.Join(fromText, " or "))
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+func (r *PostgresRepository) AdvanceScriptRun(ctx context.Context, runID int64, step int, requireRunning bool) (bool, error) {
+	const q = `
+		UPDATE script_runs
+		SET next_step = next_step + 1
+		WHERE run_id = $1 AND next_step = $2
+		  AND (status = 'RUNNING' OR (NOT $3 AND status = 'PAUSED'))
+	`
+	res, err := r.pool.Exec(ctx, q, runID, step, requireRunning)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (r *PostgresRepository) InsertScriptStepLog(ctx context.Context, entry *domain.ScriptStepLog) error {
+	const q = `
+		INSERT INTO script_step_log (run_id, step_index, action, outcome, error)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING log_id, executed_at
+	`
+	return r.pool.QueryRow(ctx, q, entry.RunID, entry.StepIndex, string(entry.Action), string(entry.Outcome), entry.Error).
+		Scan(&entry.ID, &entry.ExecutedAt)
+}
+
+func (r *PostgresRepository) ListScriptStepLog(ctx context.Context, runID int64) ([]domain.ScriptStepLog, error) {
+	const q = `
+		SELECT log_id, run_id, step_index, action, outcome, error, executed_at
+		FROM script_step_log
+		WHERE run_id = $1
+		ORDER BY log_id
+	`
+	rows, err := r.pool.Query(ctx, q, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.ScriptStepLog{}
+	for rows.Next() {
+		var e domain.ScriptStepLog
+		if err := rows.Scan(&e.ID, &e.RunID, &e.StepIndex, &e.Action, &e.Outcome, &e.Error, &e.ExecutedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,902 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"jokefactory/src/core/domain"
+	"jokefactory/src/core/ports"
+)
+
+// ExportSnapshot reads every game table inside a read-only repeatable-read transaction,
+// so the snapshot is consistent even while the game is being played.
+func (r *PostgresRepository) ExportSnapshot(ctx context.Context) (*ports.GameSnapshot, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	snap := &ports.GameSnapshot{}
+	if snap.Teams, err = snapshotRows[ports.SnapshotTeam](ctx, tx, `
+		SELECT id, name, created_at FROM teams ORDER BY id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot teams: %w", err)
+	}
+	if snap.Users, err = snapshotRows[ports.SnapshotUser](ctx, tx, `
+		SELECT user_id, display_name, role::text AS role, team_id, status::text AS status,
+		       assigned_at, joined_at, created_at, removed_at, removed_by, last_seen_at,
+		       rejoin_secret_hash, merged_into, late_admitted_at
+		FROM users ORDER BY user_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot users: %w", err)
+	}
+	if snap.Rounds, err = snapshotRows[ports.SnapshotRound](ctx, tx, `
+		SELECT round_id, round_number, status::text AS status, customer_budget, batch_size,
+		       market_price::float8 AS market_price, cost_of_publishing::float8 AS cost_of_publishing,
+		       is_popped_active, qc_routing_mode::text AS qc_routing_mode,
+		       max_unrated_batches, max_unrated_jokes, late_joiner_policy::text AS late_joiner_policy,
+		       chat_enabled, pricing_mode::text AS pricing_mode, min_joke_price::float8 AS min_joke_price,
+		       max_joke_price::float8 AS max_joke_price, price_step::float8 AS price_step,
+		       price_decay::float8 AS price_decay, auction_rule::text AS auction_rule, auction_window_seconds,
+		       auction_started_at, returns_allowed, return_window_seconds, max_returns, return_refund_percent,
+		       started_at, ended_at, created_at
+		FROM rounds ORDER BY round_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot rounds: %w", err)
+	}
+	if snap.TeamRoundStates, err = snapshotRows[ports.SnapshotTeamRoundState](ctx, tx, `
+		SELECT round_id, team_id, points_earned, batches_created, batches_rated, accepted_jokes,
+		       created_at, updated_at
+		FROM team_rounds_state ORDER BY round_id, team_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot team_rounds_state: %w", err)
+	}
+	if snap.Batches, err = snapshotRows[ports.SnapshotBatch](ctx, tx, `
+		SELECT batch_id, round_id, team_id, status::text AS status, submitted_at, rated_at,
+		       avg_score::float8 AS avg_score, passes_count, feedback, locked_at, locked_by_qc,
+		       qc_routing_mode::text AS qc_routing_mode, created_at
+		FROM batches ORDER BY batch_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot batches: %w", err)
+	}
+	if snap.Jokes, err = snapshotRows[ports.SnapshotJoke](ctx, tx, `
+		SELECT joke_id, batch_id, joke_text, joke_title, team_price::float8 AS team_price, created_at
+		FROM jokes ORDER BY joke_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot jokes: %w", err)
+	}
+	if snap.JokeRatings, err = snapshotRows[ports.SnapshotJokeRating](ctx, tx, `
+		SELECT joke_id, qc_user_id, rating, tag::text AS tag, rated_at FROM joke_ratings ORDER BY joke_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot joke_ratings: %w", err)
+	}
+	if snap.PublishedJokes, err = snapshotRows[ports.SnapshotPublishedJoke](ctx, tx, `
+		SELECT joke_id, round_id, team_id, created_at FROM published_jokes ORDER BY joke_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot published_jokes: %w", err)
+	}
+	if snap.CustomerBudgets, err = snapshotRows[ports.SnapshotCustomerBudget](ctx, tx, `
+		SELECT round_id, customer_user_id, starting_budget::float8 AS starting_budget,
+		       remaining_budget::float8 AS remaining_budget, created_at, updated_at
+		FROM customer_round_budget ORDER BY round_id, customer_user_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot customer_round_budget: %w", err)
+	}
+	if snap.Purchases, err = snapshotRows[ports.SnapshotPurchase](ctx, tx, `
+		SELECT purchase_id, round_id, customer_user_id, joke_id, price::float8 AS price, created_at
+		FROM purchases ORDER BY purchase_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot purchases: %w", err)
+	}
+	if snap.PurchaseEvents, err = snapshotRows[ports.SnapshotPurchaseEvent](ctx, tx, `
+		SELECT event_id, round_id, customer_user_id, joke_id, team_id, delta, price::float8 AS price, created_at
+		FROM purchase_events ORDER BY event_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot purchase_events: %w", err)
+	}
+	if snap.BatchSubmissionEvents, err = snapshotRows[ports.SnapshotBatchSubmissionEvent](ctx, tx, `
+		SELECT event_id, round_id, team_id, batch_id, jokes_count, delta, created_at
+		FROM batch_submission_events ORDER BY event_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot batch_submission_events: %w", err)
+	}
+	if snap.CustomerRatings, err = snapshotRows[ports.SnapshotCustomerRating](ctx, tx, `
+		SELECT round_id, customer_user_id, joke_id, rating, review, created_at, updated_at
+		FROM customer_ratings ORDER BY round_id, joke_id, customer_user_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot customer_ratings: %w", err)
+	}
+	if snap.JokePriceChanges, err = snapshotRows[ports.SnapshotJokePriceChange](ctx, tx, `
+		SELECT change_id, round_id, joke_id, sale_price::float8 AS sale_price, price::float8 AS price, created_at
+		FROM joke_price_changes ORDER BY change_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot joke_price_changes: %w", err)
+	}
+	if snap.JokeBids, err = snapshotRows[ports.SnapshotJokeBid](ctx, tx, `
+		SELECT bid_id, round_id, joke_id, customer_user_id, amount::float8 AS amount, status::text AS status,
+		       price::float8 AS price, created_at, updated_at, settled_at
+		FROM joke_bids ORDER BY bid_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot joke_bids: %w", err)
+	}
+	if snap.Announcements, err = snapshotRows[ports.SnapshotAnnouncement](ctx, tx, `
+		SELECT announcement_id, title, body, severity::text AS severity, target::text AS target,
+		       target_role::text AS target_role, target_team_id, target_user_id, expires_at, created_by, created_at
+		FROM announcements ORDER BY announcement_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot announcements: %w", err)
+	}
+	if snap.AnnouncementAcks, err = snapshotRows[ports.SnapshotAnnouncementAck](ctx, tx, `
+		SELECT announcement_id, user_id, acked_at
+		FROM announcement_acks ORDER BY announcement_id, user_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot announcement_acks: %w", err)
+	}
+	if snap.GameScripts, err = snapshotRows[ports.SnapshotGameScript](ctx, tx, `
+		SELECT script_id, name, steps, created_by, created_at, updated_at
+		FROM game_scripts ORDER BY script_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot game_scripts: %w", err)
+	}
+	if snap.ScriptRuns, err = snapshotRows[ports.SnapshotScriptRun](ctx, tx, `
+		SELECT run_id, script_id, script_name, steps, status::text AS status, next_step, elapsed_ms,
+		       resumed_at, started_by, started_at, ended_at
+		FROM script_runs ORDER BY run_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot script_runs: %w", err)
+	}
+	if snap.ScriptStepLog, err = snapshotRows[ports.SnapshotScriptStepLog](ctx, tx, `
+		SELECT log_id, run_id, step_index, action, outcome, error, executed_at
+		FROM script_step_log ORDER BY log_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot script_step_log: %w", err)
+	}
+	if snap.ChatMessages, err = snapshotRows[ports.SnapshotChatMessage](ctx, tx, `
+		SELECT message_id, round_id, team_id, sender_user_id, sender_role::text AS sender_role, body, created_at
+		FROM chat_messages ORDER BY message_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot chat_messages: %w", err)
+	}
+	if snap.ChatReadCursors, err = snapshotRows[ports.SnapshotChatReadCursor](ctx, tx, `
+		SELECT round_id, team_id, user_id, last_read_message_id, read_at
+		FROM chat_read_cursors ORDER BY round_id, team_id, user_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot chat_read_cursors: %w", err)
+	}
+	if snap.RosterEntries, err = snapshotRows[ports.SnapshotRosterEntry](ctx, tx, `
+		SELECT roster_id, display_name, student_id, preferred_team, preferred_role::text AS preferred_role,
+		       join_code, user_id, claimed_at, created_at
+		FROM roster_entries ORDER BY roster_id
+	`); err != nil {
+		return nil, fmt.Errorf("snapshot roster_entries: %w", err)
+	}
+	settings, err := snapshotRows[ports.SnapshotGameSettings](ctx, tx, `
+		SELECT require_join_code, max_participants, updated_at FROM game_settings
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot game_settings: %w", err)
+	}
+	if len(settings) > 0 {
+		snap.GameSettings = &settings[0]
+	}
+	return snap, nil
+}
+
+// rosterSnapshotVersion is the first snapshot version that carries the roster.
+const rosterSnapshotVersion = 12
+
+func snapshotRows[T any](ctx context.Context, tx pgx.Tx, q string) ([]T, error) {
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[T])
+}
+
+// snapshotIDs maps a table's ids in the snapshot to the ids they were restored under.
+type snapshotIDs struct {
+	table string
+	ids   map[int64]int64
+}
+
+func newSnapshotIDs(table string) *snapshotIDs {
+	return &snapshotIDs{table: table, ids: make(map[int64]int64)}
+}
+
+func (m *snapshotIDs) get(old int64) (int64, error) {
+	id, ok := m.ids[old]
+	if !ok {
+		return 0, domain.NewValidationError("snapshot", fmt.Sprintf("unknown %s id %d", m.table, old))
+	}
+	return id, nil
+}
+
+func (m *snapshotIDs) getOptional(old *int64) (*int64, error) {
+	if old == nil {
+		return nil, nil
+	}
+	id, err := m.get(*old)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// ImportSnapshot restores a snapshot in one transaction. The game must be empty: no teams,
+// no batches, no non-instructor users and no round that has been started. Every id is
+// remapped; instructors and rounds that already exist (matched by display name and round
+// number respectively) are reused rather than duplicated, so a freshly reset game can be
+// restored in place.
+func (r *PostgresRepository) ImportSnapshot(ctx context.Context, snap *ports.GameSnapshot) (*ports.SnapshotImportResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialise with any concurrent import/reset.
+	if _, err := tx.Exec(ctx, `LOCK TABLE teams, users, rounds, batches IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+	var teams, players, batches, playedRounds int
+	const emptyQ = `
+		SELECT
+			(SELECT count(*) FROM teams),
+			(SELECT count(*) FROM users WHERE role IS DISTINCT FROM 'INSTRUCTOR'),
+			(SELECT count(*) FROM batches),
+			(SELECT count(*) FROM rounds WHERE status <> 'CONFIGURED')
+	`
+	if err := tx.QueryRow(ctx, emptyQ).Scan(&teams, &players, &batches, &playedRounds); err != nil {
+		return nil, err
+	}
+	if teams+players+batches+playedRounds > 0 {
+		return nil, domain.NewConflictError("game is not empty; reset it before importing a snapshot")
+	}
+
+	res := &ports.SnapshotImportResult{Rows: make(map[string]int), RoundIDs: make(map[int64]int64)}
+	teamIDs := newSnapshotIDs("team")
+	userIDs := newSnapshotIDs("user")
+	roundIDs := newSnapshotIDs("round")
+	batchIDs := newSnapshotIDs("batch")
+	jokeIDs := newSnapshotIDs("joke")
+
+	for _, t := range snap.Teams {
+		var id int64
+		if err := tx.QueryRow(ctx, `INSERT INTO teams (name, created_at) VALUES ($1, $2) RETURNING id`, t.Name, t.CreatedAt).Scan(&id); err != nil {
+			return nil, fmt.Errorf("import team %d: %w", t.ID, err)
+		}
+		teamIDs.ids[t.ID] = id
+	}
+	res.Rows["teams"] = len(snap.Teams)
+
+	for _, u := range snap.Users {
+		teamID, err := teamIDs.getOptional(u.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		if u.Role != nil && *u.Role == string(domain.RoleInstructor) {
+			var existing int64
+			err := tx.QueryRow(ctx, `SELECT user_id FROM users WHERE role = 'INSTRUCTOR' AND display_name = $1 ORDER BY user_id LIMIT 1`, u.DisplayName).Scan(&existing)
+			if err == nil {
+				userIDs.ids[u.ID] = existing
+				continue
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return nil, err
+			}
+		}
+		// Older snapshots lack last_seen_at; the migration that added it used joined_at.
+		const q = `
+			INSERT INTO users (display_name, role, team_id, status, assigned_at, joined_at, created_at, removed_at,
+			                   last_seen_at, rejoin_secret_hash, late_admitted_at)
+			VALUES ($1, $2::user_role, $3, $4::participant_status, $5, $6, $7, $8, COALESCE($9, $6), $10, $11)
+			RETURNING user_id
+		`
+		var id int64
+		if err := tx.QueryRow(ctx, q, u.DisplayName, u.Role, teamID, u.Status, u.AssignedAt, u.JoinedAt, u.CreatedAt, u.RemovedAt,
+			u.LastSeenAt, u.RejoinHash, u.LateAdmit).Scan(&id); err != nil {
+			return nil, fmt.Errorf("import user %d: %w", u.ID, err)
+		}
+		userIDs.ids[u.ID] = id
+	}
+	// removed_by and merged_into can point at any user, so they are linked once every
+	// user has an id.
+	for _, u := range snap.Users {
+		if u.RemovedBy == nil && u.MergedInto == nil {
+			continue
+		}
+		removedBy, err := userIDs.getOptional(u.RemovedBy)
+		if err != nil {
+			return nil, err
+		}
+		mergedInto, err := userIDs.getOptional(u.MergedInto)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE users SET removed_by = $2, merged_into = $3 WHERE user_id = $1`, userIDs.ids[u.ID], removedBy, mergedInto); err != nil {
+			return nil, fmt.Errorf("import user %d: %w", u.ID, err)
+		}
+	}
+	res.Rows["users"] = len(snap.Users)
+
+	seenNumbers := make(map[int]bool, len(snap.Rounds))
+	for _, rd := range snap.Rounds {
+		if seenNumbers[rd.RoundNumber] {
+			return nil, domain.NewValidationError("snapshot", fmt.Sprintf("duplicate round number %d", rd.RoundNumber))
+		}
+		seenNumbers[rd.RoundNumber] = true
+
+		// Round ids double as round numbers (see InsertRoundConfig), so restore them that way.
+		const q = `
+			INSERT INTO rounds (round_id, round_number, status, customer_budget, batch_size, market_price,
+			                    cost_of_publishing, is_popped_active, qc_routing_mode, max_unrated_batches,
+			                    max_unrated_jokes, started_at, ended_at, created_at, late_joiner_policy,
+			                    chat_enabled, pricing_mode, min_joke_price, max_joke_price, price_step, price_decay,
+			                    auction_rule, auction_window_seconds, auction_started_at, returns_allowed,
+			                    return_window_seconds, max_returns, return_refund_percent)
+			VALUES ($1, $1, $2::round_status, $3, $4, $5, $6, $7, $8::qc_routing_mode, $9, $10, $11, $12, $13,
+			        $14::late_joiner_policy, $15, $16::pricing_mode, $17, $18, $19, $20, $21::auction_rule, $22, $23,
+			        $24, $25, $26, $27)
+			ON CONFLICT (round_id) DO UPDATE
+			SET round_number = EXCLUDED.round_number,
+			    status = EXCLUDED.status,
+			    customer_budget = EXCLUDED.customer_budget,
+			    batch_size = EXCLUDED.batch_size,
+			    market_price = EXCLUDED.market_price,
+			    cost_of_publishing = EXCLUDED.cost_of_publishing,
+			    is_popped_active = EXCLUDED.is_popped_active,
+			    qc_routing_mode = EXCLUDED.qc_routing_mode,
+			    max_unrated_batches = EXCLUDED.max_unrated_batches,
+			    max_unrated_jokes = EXCLUDED.max_unrated_jokes,
+			    started_at = EXCLUDED.started_at,
+			    ended_at = EXCLUDED.ended_at,
+			    created_at = EXCLUDED.created_at,
+			    late_joiner_policy = EXCLUDED.late_joiner_policy,
+			    chat_enabled = EXCLUDED.chat_enabled,
+			    pricing_mode = EXCLUDED.pricing_mode,
+			    min_joke_price = EXCLUDED.min_joke_price,
+			    max_joke_price = EXCLUDED.max_joke_price,
+			    price_step = EXCLUDED.price_step,
+			    price_decay = EXCLUDED.price_decay,
+			    auction_rule = EXCLUDED.auction_rule,
+			    auction_window_seconds = EXCLUDED.auction_window_seconds,
+			    auction_started_at = EXCLUDED.auction_started_at,
+			    returns_allowed = EXCLUDED.returns_allowed,
+			    return_window_seconds = EXCLUDED.return_window_seconds,
+			    max_returns = EXCLUDED.max_returns,
+			    return_refund_percent = EXCLUDED.return_refund_percent
+			RETURNING round_id
+		`
+		// Older snapshots lack the newer round settings; use the column defaults.
+		lateJoiners := rd.LateJoinerPolicy
+		if lateJoiners == "" {
+			lateJoiners = string(domain.LateJoinersWait)
+		}
+		chatEnabled := true
+		if rd.ChatEnabled != nil {
+			chatEnabled = *rd.ChatEnabled
+		}
+		pricing := rd.PricingMode
+		if pricing == "" {
+			pricing = string(domain.PricingFlat)
+		}
+		auctionRule, auctionWindow := rd.AuctionRule, rd.AuctionWindow
+		if auctionRule == "" {
+			auctionRule = string(domain.AuctionFirstPrice)
+		}
+		if auctionWindow == 0 {
+			auctionWindow = 60
+		}
+		returnsAllowed, refundPercent := true, 100
+		if rd.ReturnsAllowed != nil {
+			returnsAllowed = *rd.ReturnsAllowed
+		}
+		if rd.RefundPercent != nil {
+			refundPercent = *rd.RefundPercent
+		}
+		var id int64
+		if err := tx.QueryRow(ctx, q, int64(rd.RoundNumber), rd.Status, rd.CustomerBudget, rd.BatchSize, rd.MarketPrice,
+			rd.CostOfPublishing, rd.IsPoppedActive, rd.QCRoutingMode, rd.MaxUnratedBatches, rd.MaxUnratedJokes,
+			rd.StartedAt, rd.EndedAt, rd.CreatedAt, lateJoiners, chatEnabled, pricing, rd.MinJokePrice,
+			rd.MaxJokePrice, rd.PriceStep, rd.PriceDecay, auctionRule, auctionWindow, rd.AuctionStartedAt,
+			returnsAllowed, rd.ReturnWindow, rd.MaxReturns, refundPercent).Scan(&id); err != nil {
+			return nil, fmt.Errorf("import round %d: %w", rd.ID, err)
+		}
+		roundIDs.ids[rd.ID] = id
+		res.RoundIDs[rd.ID] = id
+	}
+	res.Rows["rounds"] = len(snap.Rounds)
+
+	for _, s := range snap.TeamRoundStates {
+		roundID, err := roundIDs.get(s.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		teamID, err := teamIDs.get(s.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO team_rounds_state (round_id, team_id, points_earned, batches_created, batches_rated,
+			                               accepted_jokes, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+		if _, err := tx.Exec(ctx, q, roundID, teamID, s.PointsEarned, s.BatchesCreated, s.BatchesRated, s.AcceptedJokes, s.CreatedAt, s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("import team_rounds_state %d/%d: %w", s.RoundID, s.TeamID, err)
+		}
+	}
+	res.Rows["team_rounds_state"] = len(snap.TeamRoundStates)
+
+	for _, b := range snap.Batches {
+		roundID, err := roundIDs.get(b.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		teamID, err := teamIDs.get(b.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		lockedBy, err := userIDs.getOptional(b.LockedByQC)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO batches (round_id, team_id, status, submitted_at, rated_at, avg_score, passes_count,
+			                     feedback, locked_at, locked_by_qc, created_at, qc_routing_mode)
+			VALUES ($1, $2, $3::batch_status, $4, $5, $6, $7, $8, $9, $10, $11,
+			        COALESCE(NULLIF($12, '')::qc_routing_mode, (SELECT qc_routing_mode FROM rounds WHERE round_id = $1)))
+			RETURNING batch_id
+		`
+		// Older snapshots don't say which routing a batch was submitted under; use the round's.
+		var id int64
+		if err := tx.QueryRow(ctx, q, roundID, teamID, b.Status, b.SubmittedAt, b.RatedAt, b.AvgScore, b.PassesCount,
+			b.Feedback, b.LockedAt, lockedBy, b.CreatedAt, b.QCRouting).Scan(&id); err != nil {
+			return nil, fmt.Errorf("import batch %d: %w", b.ID, err)
+		}
+		batchIDs.ids[b.ID] = id
+	}
+	res.Rows["batches"] = len(snap.Batches)
+
+	for _, j := range snap.Jokes {
+		batchID, err := batchIDs.get(j.BatchID)
+		if err != nil {
+			return nil, err
+		}
+		var id int64
+		const q = `
+			INSERT INTO jokes (batch_id, joke_text, joke_title, team_price, created_at)
+			VALUES ($1, $2, $3, $4, $5) RETURNING joke_id
+		`
+		if err := tx.QueryRow(ctx, q, batchID, j.JokeText, j.JokeTitle, j.TeamPrice, j.CreatedAt).Scan(&id); err != nil {
+			return nil, fmt.Errorf("import joke %d: %w", j.ID, err)
+		}
+		jokeIDs.ids[j.ID] = id
+	}
+	res.Rows["jokes"] = len(snap.Jokes)
+
+	for _, jr := range snap.JokeRatings {
+		jokeID, err := jokeIDs.get(jr.JokeID)
+		if err != nil {
+			return nil, err
+		}
+		qcID, err := userIDs.get(jr.QCUserID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `INSERT INTO joke_ratings (joke_id, qc_user_id, rating, tag, rated_at) VALUES ($1, $2, $3, $4::qc_tag, $5)`
+		if _, err := tx.Exec(ctx, q, jokeID, qcID, jr.Rating, jr.Tag, jr.RatedAt); err != nil {
+			return nil, fmt.Errorf("import joke_rating %d: %w", jr.JokeID, err)
+		}
+	}
+	res.Rows["joke_ratings"] = len(snap.JokeRatings)
+
+	for _, pj := range snap.PublishedJokes {
+		jokeID, err := jokeIDs.get(pj.JokeID)
+		if err != nil {
+			return nil, err
+		}
+		roundID, err := roundIDs.get(pj.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		teamID, err := teamIDs.get(pj.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `INSERT INTO published_jokes (joke_id, round_id, team_id, created_at) VALUES ($1, $2, $3, $4)`
+		if _, err := tx.Exec(ctx, q, jokeID, roundID, teamID, pj.CreatedAt); err != nil {
+			return nil, fmt.Errorf("import published_joke %d: %w", pj.JokeID, err)
+		}
+	}
+	res.Rows["published_jokes"] = len(snap.PublishedJokes)
+
+	for _, cb := range snap.CustomerBudgets {
+		roundID, err := roundIDs.get(cb.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		customerID, err := userIDs.get(cb.CustomerUserID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO customer_round_budget (round_id, customer_user_id, starting_budget, remaining_budget, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		if _, err := tx.Exec(ctx, q, roundID, customerID, cb.StartingBudget, cb.RemainingBudget, cb.CreatedAt, cb.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("import customer_round_budget %d/%d: %w", cb.RoundID, cb.CustomerUserID, err)
+		}
+	}
+	res.Rows["customer_round_budget"] = len(snap.CustomerBudgets)
+
+	for _, p := range snap.Purchases {
+		roundID, err := roundIDs.get(p.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		customerID, err := userIDs.get(p.CustomerUserID)
+		if err != nil {
+			return nil, err
+		}
+		jokeID, err := jokeIDs.get(p.JokeID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO purchases (round_id, customer_user_id, joke_id, price, created_at)
+			VALUES ($1, $2, $3, COALESCE($4, (SELECT market_price FROM rounds WHERE round_id = $1)), $5)
+		`
+		if _, err := tx.Exec(ctx, q, roundID, customerID, jokeID, p.Price, p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("import purchase %d: %w", p.ID, err)
+		}
+	}
+	res.Rows["purchases"] = len(snap.Purchases)
+
+	for _, e := range snap.PurchaseEvents {
+		roundID, err := roundIDs.get(e.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		customerID, err := userIDs.get(e.CustomerUserID)
+		if err != nil {
+			return nil, err
+		}
+		jokeID, err := jokeIDs.get(e.JokeID)
+		if err != nil {
+			return nil, err
+		}
+		teamID, err := teamIDs.get(e.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO purchase_events (round_id, customer_user_id, joke_id, team_id, delta, price, created_at)
+			VALUES ($1, $2, $3, $4, $5, COALESCE($6, (SELECT market_price FROM rounds WHERE round_id = $1)), $7)
+		`
+		if _, err := tx.Exec(ctx, q, roundID, customerID, jokeID, teamID, e.Delta, e.Price, e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("import purchase_event %d: %w", e.ID, err)
+		}
+	}
+	res.Rows["purchase_events"] = len(snap.PurchaseEvents)
+
+	for _, e := range snap.BatchSubmissionEvents {
+		roundID, err := roundIDs.get(e.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		teamID, err := teamIDs.get(e.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		batchID, err := batchIDs.get(e.BatchID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO batch_submission_events (round_id, team_id, batch_id, jokes_count, delta, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		if _, err := tx.Exec(ctx, q, roundID, teamID, batchID, e.JokesCount, e.Delta, e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("import batch_submission_event %d: %w", e.ID, err)
+		}
+	}
+	res.Rows["batch_submission_events"] = len(snap.BatchSubmissionEvents)
+
+	for _, cr := range snap.CustomerRatings {
+		roundID, err := roundIDs.get(cr.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		customerID, err := userIDs.get(cr.CustomerUserID)
+		if err != nil {
+			return nil, err
+		}
+		jokeID, err := jokeIDs.get(cr.JokeID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO customer_ratings (round_id, customer_user_id, joke_id, rating, review, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		if _, err := tx.Exec(ctx, q, roundID, customerID, jokeID, cr.Rating, cr.Review, cr.CreatedAt, cr.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("import customer_rating %d/%d: %w", cr.JokeID, cr.CustomerUserID, err)
+		}
+	}
+	res.Rows["customer_ratings"] = len(snap.CustomerRatings)
+
+	for _, c := range snap.JokePriceChanges {
+		roundID, err := roundIDs.get(c.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		jokeID, err := jokeIDs.get(c.JokeID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO joke_price_changes (round_id, joke_id, sale_price, price, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		if _, err := tx.Exec(ctx, q, roundID, jokeID, c.SalePrice, c.Price, c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("import joke_price_change %d: %w", c.ID, err)
+		}
+	}
+	res.Rows["joke_price_changes"] = len(snap.JokePriceChanges)
+
+	for _, b := range snap.JokeBids {
+		roundID, err := roundIDs.get(b.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		jokeID, err := jokeIDs.get(b.JokeID)
+		if err != nil {
+			return nil, err
+		}
+		customerID, err := userIDs.get(b.CustomerUserID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO joke_bids (round_id, joke_id, customer_user_id, amount, status, price, created_at, updated_at, settled_at)
+			VALUES ($1, $2, $3, $4, $5::bid_status, $6, $7, $8, $9)
+		`
+		if _, err := tx.Exec(ctx, q, roundID, jokeID, customerID, b.Amount, b.Status, b.Price, b.CreatedAt, b.UpdatedAt, b.SettledAt); err != nil {
+			return nil, fmt.Errorf("import joke_bid %d: %w", b.ID, err)
+		}
+	}
+	res.Rows["joke_bids"] = len(snap.JokeBids)
+
+	announcementIDs := newSnapshotIDs("announcement")
+	for _, a := range snap.Announcements {
+		teamID, err := teamIDs.getOptional(a.TargetTeamID)
+		if err != nil {
+			return nil, err
+		}
+		userID, err := userIDs.getOptional(a.TargetUserID)
+		if err != nil {
+			return nil, err
+		}
+		createdBy, err := userIDs.getOptional(a.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO announcements (title, body, severity, target, target_role, target_team_id, target_user_id,
+			                           expires_at, created_by, created_at)
+			VALUES ($1, $2, $3::announcement_severity, $4::announcement_target, $5::user_role, $6, $7, $8, $9, $10)
+			RETURNING announcement_id
+		`
+		var id int64
+		if err := tx.QueryRow(ctx, q, a.Title, a.Body, a.Severity, a.Target, a.TargetRole, teamID, userID,
+			a.ExpiresAt, createdBy, a.CreatedAt).Scan(&id); err != nil {
+			return nil, fmt.Errorf("import announcement %d: %w", a.ID, err)
+		}
+		announcementIDs.ids[a.ID] = id
+	}
+	res.Rows["announcements"] = len(snap.Announcements)
+
+	for _, ack := range snap.AnnouncementAcks {
+		announcementID, err := announcementIDs.get(ack.AnnouncementID)
+		if err != nil {
+			return nil, err
+		}
+		userID, err := userIDs.get(ack.UserID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `INSERT INTO announcement_acks (announcement_id, user_id, acked_at) VALUES ($1, $2, $3)`
+		if _, err := tx.Exec(ctx, q, announcementID, userID, ack.AckedAt); err != nil {
+			return nil, fmt.Errorf("import announcement_ack %d/%d: %w", ack.AnnouncementID, ack.UserID, err)
+		}
+	}
+	res.Rows["announcement_acks"] = len(snap.AnnouncementAcks)
+
+	// Messages are restored in id order, so new ids keep the old order.
+	type messageID struct{ old, new int64 }
+	var messageIDs []messageID
+	for _, m := range snap.ChatMessages {
+		roundID, err := roundIDs.get(m.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		teamID, err := teamIDs.getOptional(m.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		senderID, err := userIDs.getOptional(m.SenderUserID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO chat_messages (round_id, team_id, sender_user_id, sender_role, body, created_at)
+			VALUES ($1, $2, $3, $4::user_role, $5, $6)
+			RETURNING message_id
+		`
+		var id int64
+		if err := tx.QueryRow(ctx, q, roundID, teamID, senderID, m.SenderRole, m.Body, m.CreatedAt).Scan(&id); err != nil {
+			return nil, fmt.Errorf("import chat_message %d: %w", m.ID, err)
+		}
+		messageIDs = append(messageIDs, messageID{old: m.ID, new: id})
+	}
+	res.Rows["chat_messages"] = len(snap.ChatMessages)
+
+	for _, c := range snap.ChatReadCursors {
+		roundID, err := roundIDs.get(c.RoundID)
+		if err != nil {
+			return nil, err
+		}
+		teamID, err := teamIDs.getOptional(c.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		userID, err := userIDs.get(c.UserID)
+		if err != nil {
+			return nil, err
+		}
+		// A cursor needn't name a message that exists; it moves to the last message at or
+		// before it.
+		var lastRead int64
+		for _, m := range messageIDs {
+			if m.old > c.LastReadMessageID {
+				break
+			}
+			lastRead = m.new
+		}
+		const q = `
+			INSERT INTO chat_read_cursors (round_id, team_id, user_id, last_read_message_id, read_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		if _, err := tx.Exec(ctx, q, roundID, teamID, userID, lastRead, c.ReadAt); err != nil {
+			return nil, fmt.Errorf("import chat_read_cursor %d/%d: %w", c.RoundID, c.UserID, err)
+		}
+	}
+	res.Rows["chat_read_cursors"] = len(snap.ChatReadCursors)
+
+	// Scripts outlive a reset, so one with the same name is reused rather than duplicated.
+	// Ids inside the steps (rounds, announcement targets) are kept as written.
+	scriptIDs := newSnapshotIDs("script")
+	for _, gs := range snap.GameScripts {
+		var existing int64
+		err := tx.QueryRow(ctx, `SELECT script_id FROM game_scripts WHERE name = $1 ORDER BY script_id LIMIT 1`, gs.Name).Scan(&existing)
+		if err == nil {
+			scriptIDs.ids[gs.ID] = existing
+			continue
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		createdBy, err := userIDs.getOptional(gs.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO game_scripts (name, steps, created_by, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING script_id
+		`
+		var id int64
+		if err := tx.QueryRow(ctx, q, gs.Name, gs.Steps, createdBy, gs.CreatedAt, gs.UpdatedAt).Scan(&id); err != nil {
+			return nil, fmt.Errorf("import game_script %d: %w", gs.ID, err)
+		}
+		scriptIDs.ids[gs.ID] = id
+	}
+	res.Rows["game_scripts"] = len(snap.GameScripts)
+
+	// A run that was playing comes back paused at the point the snapshot was taken, so the
+	// restored game doesn't start acting on its own.
+	runIDs := newSnapshotIDs("script run")
+	for _, run := range snap.ScriptRuns {
+		scriptID, err := scriptIDs.getOptional(run.ScriptID)
+		if err != nil {
+			return nil, err
+		}
+		startedBy, err := userIDs.getOptional(run.StartedBy)
+		if err != nil {
+			return nil, err
+		}
+		status, elapsedMs, resumedAt := run.Status, run.ElapsedMs, run.ResumedAt
+		if status == string(domain.ScriptRunning) {
+			if resumedAt != nil && snap.ExportedAt.After(*resumedAt) {
+				elapsedMs += snap.ExportedAt.Sub(*resumedAt).Milliseconds()
+			}
+			status, resumedAt = string(domain.ScriptPaused), nil
+		}
+		const q = `
+			INSERT INTO script_runs (script_id, script_name, steps, status, next_step, elapsed_ms, resumed_at,
+			                         started_by, started_at, ended_at)
+			VALUES ($1, $2, $3, $4::script_run_status, $5, $6, $7, $8, $9, $10)
+			RETURNING run_id
+		`
+		var id int64
+		if err := tx.QueryRow(ctx, q, scriptID, run.ScriptName, run.Steps, status, run.NextStep, elapsedMs, resumedAt,
+			startedBy, run.StartedAt, run.EndedAt).Scan(&id); err != nil {
+			return nil, fmt.Errorf("import script_run %d: %w", run.ID, err)
+		}
+		runIDs.ids[run.ID] = id
+	}
+	res.Rows["script_runs"] = len(snap.ScriptRuns)
+
+	for _, l := range snap.ScriptStepLog {
+		runID, err := runIDs.get(l.RunID)
+		if err != nil {
+			return nil, err
+		}
+		const q = `
+			INSERT INTO script_step_log (run_id, step_index, action, outcome, error, executed_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		if _, err := tx.Exec(ctx, q, runID, l.StepIndex, l.Action, l.Outcome, l.Error, l.ExecutedAt); err != nil {
+			return nil, fmt.Errorf("import script_step_log %d: %w", l.ID, err)
+		}
+	}
+	res.Rows["script_step_log"] = len(snap.ScriptStepLog)
+
+	// The roster outlives a reset, so older snapshots (which don't carry it) leave the
+	// current one alone; newer ones replace it, claims included.
+	if snap.Version >= rosterSnapshotVersion {
+		if _, err := tx.Exec(ctx, `DELETE FROM roster_entries`); err != nil {
+			return nil, err
+		}
+		for _, e := range snap.RosterEntries {
+			userID, err := userIDs.getOptional(e.UserID)
+			if err != nil {
+				return nil, err
+			}
+			const q = `
+				INSERT INTO roster_entries (display_name, student_id, preferred_team, preferred_role, join_code,
+				                            user_id, claimed_at, created_at)
+				VALUES ($1, $2, $3, $4::user_role, $5, $6, $7, $8)
+			`
+			if _, err := tx.Exec(ctx, q, e.DisplayName, e.StudentID, e.PreferredTeam, e.PreferredRole, e.JoinCode,
+				userID, e.ClaimedAt, e.CreatedAt); err != nil {
+				return nil, fmt.Errorf("import roster_entry %d: %w", e.ID, err)
+			}
+		}
+		res.Rows["roster_entries"] = len(snap.RosterEntries)
+	}
+	if gs := snap.GameSettings; gs != nil {
+		const q = `
+			INSERT INTO game_settings (singleton, require_join_code, max_participants, updated_at)
+			VALUES (TRUE, $1, $2, $3)
+			ON CONFLICT (singleton) DO UPDATE
+			SET require_join_code = EXCLUDED.require_join_code,
+			    max_participants = EXCLUDED.max_participants,
+			    updated_at = EXCLUDED.updated_at
+		`
+		if _, err := tx.Exec(ctx, q, gs.RequireJoinCode, gs.MaxParticipants, gs.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("import game_settings: %w", err)
+		}
+		res.Rows["game_settings"] = 1
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	r.log.Info("ImportSnapshot restored game", "rows", res.Rows)
+	return res, nil
+}
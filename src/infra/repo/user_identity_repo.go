@@ -0,0 +1,336 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"jokefactory/src/core/domain"
+)
+
+// admissionLockKey serialises joins (display-name allocation and the participant cap) so
+// two concurrent joins can't both take the same free name or the last free place.
+const admissionLockKey = 7_310_001
+
+// activeParticipantsQ counts the students holding a place in the game.
+const activeParticipantsQ = `
+	SELECT COUNT(*) FROM users
+	WHERE status IN ('WAITING', 'ASSIGNED') AND role IS DISTINCT FROM 'INSTRUCTOR' AND removed_at IS NULL
+`
+
+func (r *PostgresRepository) CreateParticipant(ctx context.Context, displayName, rejoinSecretHash string) (*domain.User, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	u, err := createParticipantTx(ctx, tx, displayName, rejoinSecretHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// createParticipantTx allocates a free display name and inserts the user, waitlisted if
+// the game is already full.
+func createParticipantTx(ctx context.Context, tx pgx.Tx, displayName, rejoinSecretHash string) (*domain.User, error) {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, admissionLockKey); err != nil {
+		return nil, err
+	}
+
+	status := domain.ParticipantWaiting
+	var maxParticipants *int
+	if err := tx.QueryRow(ctx, `SELECT max_participants FROM game_settings`).Scan(&maxParticipants); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if maxParticipants != nil {
+		var active int
+		if err := tx.QueryRow(ctx, activeParticipantsQ).Scan(&active); err != nil {
+			return nil, err
+		}
+		if active >= *maxParticipants {
+			status = domain.ParticipantWaitlisted
+		}
+	}
+
+	rows, err := tx.Query(ctx, `SELECT lower(display_name) FROM users WHERE left(lower(display_name), length($1)) = lower($1)`, displayName)
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		taken[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	name := displayName
+	for n := 2; taken[strings.ToLower(name)]; n++ {
+		name = fmt.Sprintf("%s (%d)", displayName, n)
+	}
+
+	const q = `
+		INSERT INTO users (display_name, rejoin_secret_hash, status)
+		VALUES ($1, $2, $3::participant_status)
+		RETURNING ` + userColumns + `
+	`
+	return scanUser(tx.QueryRow(ctx, q, name, rejoinSecretHash, status))
+}
+
+func (r *PostgresRepository) GetUserByRejoinSecret(ctx context.Context, rejoinSecretHash string) (*domain.User, error) {
+	var userID int64
+	err := r.pool.QueryRow(ctx, `SELECT COALESCE(merged_into, user_id) FROM users WHERE rejoin_secret_hash = $1`, rejoinSecretHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("user")
+		}
+		return nil, err
+	}
+	return r.GetUserByID(ctx, userID)
+}
+
+func (r *PostgresRepository) MergeUsers(ctx context.Context, sourceID, targetID, mergedBy int64) (*domain.User, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	type mergeUser struct {
+		role      *domain.Role
+		teamID    *int64
+		status    domain.ParticipantStatus
+		removedAt *time.Time
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT user_id, role, team_id, status, removed_at
+		FROM users
+		WHERE user_id = ANY($1)
+		ORDER BY user_id
+		FOR UPDATE
+	`, []int64{sourceID, targetID})
+	if err != nil {
+		return nil, err
+	}
+	locked := make(map[int64]mergeUser, 2)
+	for rows.Next() {
+		var id int64
+		var u mergeUser
+		if err := rows.Scan(&id, &u.role, &u.teamID, &u.status, &u.removedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		locked[id] = u
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	source, ok := locked[sourceID]
+	if !ok || source.removedAt != nil {
+		return nil, domain.NewNotFoundError("source user")
+	}
+	target, ok := locked[targetID]
+	if !ok || target.removedAt != nil {
+		return nil, domain.NewNotFoundError("target user")
+	}
+	if (source.role != nil && *source.role == domain.RoleInstructor) || (target.role != nil && *target.role == domain.RoleInstructor) {
+		return nil, domain.NewConflictError("instructor users cannot be merged")
+	}
+
+	var clash *int64
+	if err := tx.QueryRow(ctx, `
+		SELECT s.round_id
+		FROM customer_round_budget s
+		JOIN customer_round_budget t ON t.round_id = s.round_id AND t.customer_user_id = $2
+		WHERE s.customer_user_id = $1
+		ORDER BY s.round_id
+		LIMIT 1
+	`, sourceID, targetID).Scan(&clash); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if clash != nil {
+		return nil, domain.NewConflictError(fmt.Sprintf("both users have a customer budget in round %d; merge refused", *clash))
+	}
+
+	moves := []string{
+		`UPDATE joke_ratings SET qc_user_id = $2 WHERE qc_user_id = $1`,
+		`UPDATE batches SET locked_by_qc = $2 WHERE locked_by_qc = $1`,
+		`UPDATE customer_round_budget SET customer_user_id = $2 WHERE customer_user_id = $1`,
+		`UPDATE purchases SET customer_user_id = $2 WHERE customer_user_id = $1`,
+		`UPDATE purchase_events SET customer_user_id = $2 WHERE customer_user_id = $1`,
+		`UPDATE customer_ratings SET customer_user_id = $2 WHERE customer_user_id = $1`,
+		`UPDATE joke_bids SET customer_user_id = $2 WHERE customer_user_id = $1`,
+		`UPDATE users SET merged_into = $2 WHERE merged_into = $1`,
+		`UPDATE roster_entries SET user_id = $2 WHERE user_id = $1`,
+		`UPDATE chat_messages SET sender_user_id = $2 WHERE sender_user_id = $1`,
+		// A channel both have read keeps whichever cursor is further along.
+		`INSERT INTO chat_read_cursors (round_id, team_id, user_id, last_read_message_id, read_at)
+		 SELECT round_id, team_id, $2, last_read_message_id, read_at FROM chat_read_cursors WHERE user_id = $1
+		 ON CONFLICT (round_id, (COALESCE(team_id, 0)), user_id) DO UPDATE
+		 SET last_read_message_id = GREATEST(chat_read_cursors.last_read_message_id, EXCLUDED.last_read_message_id),
+		     read_at = GREATEST(chat_read_cursors.read_at, EXCLUDED.read_at)`,
+		`DELETE FROM chat_read_cursors WHERE user_id = $1`,
+		`UPDATE announcements SET target_user_id = $2 WHERE target_user_id = $1`,
+		// An announcement both have acknowledged keeps the target's ack.
+		`INSERT INTO announcement_acks (announcement_id, user_id, acked_at)
+		 SELECT announcement_id, $2, acked_at FROM announcement_acks WHERE user_id = $1
+		 ON CONFLICT DO NOTHING`,
+		`DELETE FROM announcement_acks WHERE user_id = $1`,
+	}
+	for _, q := range moves {
+		if _, err := tx.Exec(ctx, q, sourceID, targetID); err != nil {
+			if isUniqueViolation(err) {
+				return nil, domain.NewConflictError("both users hold the same record; merge refused")
+			}
+			return nil, err
+		}
+	}
+
+	// An unassigned target takes over the duplicate's assignment.
+	if target.role == nil && source.role != nil {
+		const assignQ = `
+			UPDATE users
+			SET role = s.role, team_id = s.team_id, status = s.status, assigned_at = s.assigned_at
+			FROM users s
+			WHERE users.user_id = $2 AND s.user_id = $1
+		`
+		if _, err := tx.Exec(ctx, assignQ, sourceID, targetID); err != nil {
+			return nil, err
+		}
+	}
+
+	const retireQ = `
+		UPDATE users
+		SET role = NULL, team_id = NULL, status = 'WAITING', assigned_at = NULL,
+		    removed_at = now(), removed_by = $3, merged_into = $2
+		WHERE user_id = $1
+	`
+	if _, err := tx.Exec(ctx, retireQ, sourceID, targetID, mergedBy); err != nil {
+		return nil, err
+	}
+
+	merged, err := scanUser(tx.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE user_id = $1`, targetID))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func (r *PostgresRepository) PromoteWaitlisted(ctx context.Context) ([]int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, admissionLockKey); err != nil {
+		return nil, err
+	}
+	var maxParticipants *int
+	if err := tx.QueryRow(ctx, `SELECT max_participants FROM game_settings`).Scan(&maxParticipants); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	// NULL limit promotes everyone.
+	var free *int
+	if maxParticipants != nil {
+		var active int
+		if err := tx.QueryRow(ctx, activeParticipantsQ).Scan(&active); err != nil {
+			return nil, err
+		}
+		n := *maxParticipants - active
+		if n <= 0 {
+			return nil, nil
+		}
+		free = &n
+	}
+
+	const q = `
+		UPDATE users SET status = 'WAITING'
+		WHERE user_id IN (
+			SELECT user_id FROM users
+			WHERE status = 'WAITLISTED' AND removed_at IS NULL
+			ORDER BY joined_at, user_id
+			LIMIT $1
+		)
+		RETURNING user_id
+	`
+	rows, err := tx.Query(ctx, q, free)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *PostgresRepository) ListLateJoiners(ctx context.Context, roundID int64) ([]domain.User, error) {
+	const q = `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE status = 'WAITING' AND role IS NULL AND removed_at IS NULL AND late_admitted_at IS NULL
+		  AND joined_at > (SELECT min(created_at) FROM team_rounds_state WHERE round_id = $1)
+		ORDER BY joined_at, user_id
+	`
+	rows, err := r.pool.Query(ctx, q, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, rows.Err()
+}
+
+func (r *PostgresRepository) ClaimLateJoiner(ctx context.Context, userID int64) (bool, error) {
+	const q = `
+		UPDATE users SET late_admitted_at = now()
+		WHERE user_id = $1 AND late_admitted_at IS NULL
+		  AND status = 'WAITING' AND role IS NULL AND removed_at IS NULL
+	`
+	tag, err := r.pool.Exec(ctx, q, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}